@@ -0,0 +1,89 @@
+package db
+
+import (
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/powerplay"
+)
+
+// careerQuery totals a player's stats for each team they've played for, counted the same way as
+// playerStatsQuery: games played are started games they were in the lineup for.
+const careerQuery = `
+	WITH appearances AS (
+		SELECT DISTINCT d.team_id, d.game_id
+		FROM (` + dressedSql + `) d JOIN games g ON g.id = d.game_id
+		WHERE d.user_id = @player AND g.status <> @scheduled
+	),
+	events AS (
+		SELECT team_id, 1 AS goals, 0 AS assists, 0 AS pim, 0 AS plus_minus
+		FROM goals WHERE user_id = @player
+		UNION ALL
+		SELECT team_id, 0, 1, 0, 0 FROM goals WHERE assist1_id = @player
+		UNION ALL
+		SELECT team_id, 0, 1, 0, 0 FROM goals WHERE assist2_id = @player
+		UNION ALL
+		SELECT p.team_id, 0, 0, pt.duration, 0
+		FROM penalties p JOIN penalty_types pt ON pt.id = p.penalty_type_id
+		WHERE p.player_id = @player
+		UNION ALL
+		-- Power play and penalty shot goals don't count towards plus/minus
+		SELECT oi.team_id, 0, 0, 0, CASE WHEN oi.team_id = g.team_id THEN 1 ELSE -1 END
+		FROM goals_on_ice oi JOIN goals g ON g.id = oi.goal_id
+		WHERE oi.player_id = @player AND g.strength <> @powerplay AND NOT g.is_penalty_shot
+	),
+	played AS (
+		SELECT team_id, count(DISTINCT game_id) AS games_played FROM appearances GROUP BY team_id
+	),
+	totals AS (
+		SELECT team_id, sum(goals) AS goals, sum(assists) AS assists, sum(pim) AS penalty_mins, sum(plus_minus) AS plus_minus
+		FROM events GROUP BY team_id
+	)
+	SELECT COALESCE(s.id, 0) AS season_id, COALESCE(s.name, '') AS season_name, s.start AS season_start,
+		COALESCE(l.id, 0) AS league_id, COALESCE(l.name, '') AS league_name, COALESCE(l.correlation_id, '') AS league_correlation_id,
+		t.id AS team_id, t.name AS team_name, t.correlation_id AS team_correlation_id,
+		COALESCE(played.games_played, 0) AS games_played,
+		COALESCE(totals.goals, 0) AS goals,
+		COALESCE(totals.assists, 0) AS assists,
+		COALESCE(totals.goals, 0) + COALESCE(totals.assists, 0) AS points,
+		COALESCE(totals.penalty_mins, 0) AS penalty_mins,
+		COALESCE(totals.plus_minus, 0) AS plus_minus,
+		COALESCE((COALESCE(totals.goals, 0) + COALESCE(totals.assists, 0))::float / NULLIF(played.games_played, 0), 0) AS points_per_game
+	FROM teams t
+		LEFT JOIN played ON played.team_id = t.id
+		LEFT JOIN totals ON totals.team_id = t.id
+		LEFT JOIN leagues l ON l.id = t.league_id
+		LEFT JOIN seasons s ON s.id = l.season_id
+	WHERE played.team_id IS NOT NULL OR totals.team_id IS NOT NULL
+	ORDER BY s.start, s.id, t.id`
+
+// GetCareerSeasons returns a player's stat line for each team and season they've played,
+// oldest first
+func (s session) GetCareerSeasons(playerId uint) ([]models.CareerSeason, error) {
+	seasons := make([]models.CareerSeason, 0)
+	result := s.connection.Raw(careerQuery, map[string]any{
+		"player":    playerId,
+		"scheduled": models.SCHEDULED,
+		"final":     models.FINAL,
+		"powerplay": powerplay.PowerPlay,
+	}).Scan(&seasons)
+	return resultsOrError(seasons, result)
+}
+
+// GetFranchiseSeasons returns every team sharing a correlation ID, oldest season first, with
+// the record stored on the team and its captain
+func (s session) GetFranchiseSeasons(correlationId string) ([]models.FranchiseSeason, error) {
+	seasons := make([]models.FranchiseSeason, 0)
+	result := s.connection.Raw(`
+		SELECT COALESCE(s.id, 0) AS season_id, COALESCE(s.name, '') AS season_name, s.start AS season_start,
+			COALESCE(l.id, 0) AS league_id, COALESCE(l.name, '') AS league_name,
+			t.id AS team_id, t.name, t.color, t.logo_id,
+			t.wins, t.losses, t.ties, t.overtime_losses, t.goals_for, t.goals_against, t.points,
+			u.id AS captain_id, COALESCE(u.first_name || ' ' || u.last_name, '') AS captain_name
+		FROM teams t
+			LEFT JOIN leagues l ON l.id = t.league_id
+			LEFT JOIN seasons s ON s.id = l.season_id
+			LEFT JOIN rosters r ON r.id = t.roster_id
+			LEFT JOIN users u ON u.id = r.captain_id
+		WHERE t.correlation_id = ?
+		ORDER BY s.start, s.id, t.id`, correlationId).Scan(&seasons)
+	return resultsOrError(seasons, result)
+}
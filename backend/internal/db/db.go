@@ -10,6 +10,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jak103/powerplay/internal/config"
 	"github.com/jak103/powerplay/internal/db/migrations"
+	"github.com/jak103/powerplay/internal/models"
 
 	ppseeders "github.com/jak103/powerplay/internal/db/seeders"
 	"github.com/jak103/powerplay/internal/utils/locals"
@@ -23,6 +24,15 @@ var db *gorm.DB
 
 type session struct {
 	connection *gorm.DB
+	editor     editor
+}
+
+// editor is who is changing game events through a session and why, kept in the event log
+type editor struct {
+	userId  uint
+	reason  string
+	replay  models.EventLogAction // Set while undoing or redoing an entry
+	reverts *uint
 }
 
 func Init() error {
@@ -71,18 +81,40 @@ func Migrate() error {
 }
 
 func GetSession(c *fiber.Ctx) session {
-	logger := log.TheLogger
-	if c != nil {
-		logger = locals.Logger(c)
+	if c == nil {
+		return NewSession(log.TheLogger)
 	}
 
-	s := session{
+	s := NewSession(locals.Logger(c))
+	if record := locals.KeyRecord(c); record != nil {
+		s.editor.userId = record.UserId
+	}
+	return s
+}
+
+// NewSession returns a session that logs to logger and isn't tied to a request, for work that
+// outlives the request's context such as a streamed response body
+func NewSession(logger log.Logger) session {
+	return session{
 		connection: db.Session(&gorm.Session{
 			Logger: &dbLogger{
 				theLogger: &logger,
 			},
 		}),
 	}
+}
+
+// Transaction runs fn with a session bound to a single database transaction.
+// The transaction is rolled back if fn returns an error.
+func (s session) Transaction(fn func(tx session) error) error {
+	return s.connection.Transaction(func(tx *gorm.DB) error {
+		return fn(session{connection: tx, editor: s.editor})
+	})
+}
+
+// WithReason returns a session that records why game events were changed in the event log
+func (s session) WithReason(reason string) session {
+	s.editor.reason = reason
 	return s
 }
 
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/discipline"
+)
+
+// gamesServedSql counts the final games a suspended player's team has played after the suspension
+// started and before a point in time
+const gamesServedSql = `(SELECT count(*) FROM games g
+	WHERE (g.home_team_id = s.team_id OR g.away_team_id = s.team_id)
+		AND g.status = 'Final' AND g.start > s.starts_at AND g.start < ?)`
+
+func (s session) GetDisciplineRules() ([]models.DisciplineRule, error) {
+	rules := make([]models.DisciplineRule, 0)
+	err := s.connection.Order("league_id, severity, threshold").Find(&rules)
+	return resultsOrError(rules, err)
+}
+
+func (s session) SaveDisciplineRule(rule *models.DisciplineRule) (*models.DisciplineRule, error) {
+	result := s.connection.Create(rule)
+	return resultOrError(rule, result)
+}
+
+func (s session) DeleteDisciplineRule(id uint) (bool, error) {
+	result := s.connection.Delete(&models.DisciplineRule{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+type SuspensionFilter struct {
+	PlayerID uint
+	SeasonID uint
+	Active   bool // Only suspensions that still have games left to serve
+}
+
+func (s session) GetSuspensions(filter SuspensionFilter) ([]models.Suspension, error) {
+	suspensions := make([]models.Suspension, 0)
+	err := s.connection.Raw(`
+		SELECT * FROM (
+			SELECT s.*, `+gamesServedSql+` AS games_served
+			FROM suspensions s
+			WHERE (? = 0 OR s.player_id = ?) AND (? = 0 OR s.season_id = ?)
+		) suspensions
+		WHERE NOT ? OR games_served < games
+		ORDER BY starts_at DESC, id DESC`,
+		time.Now(), filter.PlayerID, filter.PlayerID, filter.SeasonID, filter.SeasonID, filter.Active).Scan(&suspensions)
+	return resultsOrError(suspensions, err)
+}
+
+func (s session) GetSuspension(id uint) (*models.Suspension, error) {
+	suspension := &models.Suspension{}
+	result := s.connection.First(suspension, id)
+	return resultOrError(suspension, result)
+}
+
+func (s session) SaveSuspension(suspension *models.Suspension) (*models.Suspension, error) {
+	result := s.connection.Create(suspension)
+	return resultOrError(suspension, result)
+}
+
+// OverrideSuspension changes the length of a suspension, recording which manager changed it and
+// why. Zero games lifts it.
+func (s session) OverrideSuspension(suspension *models.Suspension, games int, managerId *uint, reason string) (*models.Suspension, error) {
+	now := time.Now()
+	suspension.Games = games
+	suspension.OverriddenBy = managerId
+	suspension.OverriddenAt = &now
+	suspension.OverrideReason = reason
+
+	result := s.connection.Model(suspension).Updates(map[string]any{
+		"games":           suspension.Games,
+		"overridden_by":   suspension.OverriddenBy,
+		"overridden_at":   suspension.OverriddenAt,
+		"override_reason": suspension.OverrideReason,
+	})
+	return resultOrError(suspension, result)
+}
+
+// GetSuspendedPlayerIds returns which of the players are suspended for a game. Players are
+// suspended from the first game their team plays after the suspension started until the team
+// has finished as many games as the suspension is long. A zero game ID asks who is suspended now.
+func (s session) GetSuspendedPlayerIds(gameId uint, playerIds []uint) ([]uint, error) {
+	suspended := make([]uint, 0)
+	if len(playerIds) == 0 {
+		return suspended, nil
+	}
+
+	at := time.Now()
+	if gameId != 0 {
+		game, err := s.GetGame(gameId)
+		if err != nil {
+			return nil, err
+		}
+		if game != nil {
+			at = game.Start
+		}
+	}
+
+	result := s.connection.Raw(`
+		SELECT DISTINCT s.player_id FROM suspensions s
+		WHERE s.player_id IN ? AND s.starts_at < ? AND `+gamesServedSql+` < s.games`,
+		playerIds, at, at).Scan(&suspended)
+	return resultsOrError(suspended, result)
+}
+
+// applyDiscipline suspends the player who took a penalty when it sets off any discipline rules.
+// It should be called inside the transaction that created the penalty.
+func (s session) applyDiscipline(penalty *models.Penalty) error {
+	if penalty.PlayerID == 0 {
+		return nil
+	}
+
+	penaltyType := &models.PenaltyType{}
+	result := s.connection.Limit(1).Find(penaltyType, penalty.PenaltyTypeID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	team := &models.Team{}
+	result = s.connection.Preload("League").Limit(1).Find(team, penalty.TeamID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	var count int64
+	err := s.connection.Model(&models.Penalty{}).
+		Joins("JOIN penalty_types ON penalty_types.id = penalties.penalty_type_id").
+		Joins("JOIN teams ON teams.id = penalties.team_id").
+		Joins("JOIN leagues ON leagues.id = teams.league_id").
+		Where("penalties.player_id = ? AND penalty_types.severity = ? AND leagues.season_id = ?", penalty.PlayerID, penaltyType.Severity, team.League.SeasonID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.GetDisciplineRules()
+	if err != nil {
+		return err
+	}
+
+	triggered := discipline.Triggered(rules, team.LeagueID, penaltyType.Severity, int(count))
+	if len(triggered) == 0 {
+		return nil
+	}
+
+	startsAt := time.Now()
+	game, err := s.GetGame(penalty.GameID)
+	if err != nil {
+		return err
+	}
+	if game != nil {
+		startsAt = game.Start
+	}
+
+	for _, rule := range triggered {
+		suspension := &models.Suspension{
+			PlayerID:  penalty.PlayerID,
+			TeamID:    penalty.TeamID,
+			SeasonID:  team.League.SeasonID,
+			PenaltyID: &penalty.ID,
+			RuleID:    &rule.ID,
+			Games:     rule.Games,
+			StartsAt:  startsAt,
+			Reason:    discipline.Reason(rule, int(count)),
+		}
+		if err := s.connection.Create(suspension).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
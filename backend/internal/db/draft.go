@@ -0,0 +1,164 @@
+package db
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/draft"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// draftableSql keeps the registrations r that are approved and whose players aren't rostered in
+// the league yet. It takes the approved status.
+const draftableSql = `r.status = ? AND r.deleted_at IS NULL AND NOT EXISTS (
+	SELECT 1 FROM teams t JOIN player_rosters pr ON pr.roster_id = t.roster_id
+	WHERE t.league_id = r.league_id AND t.archived_at IS NULL AND pr.user_id = r.user_id)`
+
+// CreateDraft splits the players whose registrations for a league were approved, less those already
+// rostered in it, into balanced teams. It returns nil when the league doesn't exist.
+func (s session) CreateDraft(leagueId uint, teams int, names []string) (*models.Draft, error) {
+	names, err := draft.TeamNames(teams, names)
+	if err != nil {
+		return nil, err
+	}
+
+	league := &models.League{}
+	result := s.connection.Limit(1).Find(league, leagueId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	picks := make([]models.DraftPick, 0)
+	err = s.connection.Raw(`
+		SELECT r.user_id, u.skill_level, COALESCE(NULLIF(r.position, ''), ?) AS position, r.play_with
+		FROM registrations r
+			JOIN users u ON u.id = r.user_id
+		WHERE r.league_id = ? AND `+draftableSql+`
+		ORDER BY r.user_id`, models.Skater, leagueId, models.RegistrationApproved).Scan(&picks).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := draft.Generate(picks, teams, roster.Limit(*league)); err != nil {
+		return nil, err
+	}
+
+	created := &models.Draft{SeasonID: league.SeasonID, LeagueID: league.ID, TeamNames: names, Picks: picks}
+	if err := s.connection.Create(created).Error; err != nil {
+		return nil, err
+	}
+	return s.GetDraft(created.ID)
+}
+
+// GetDraft returns a draft with its picks and how its teams shape up, or nil when it doesn't exist
+func (s session) GetDraft(id uint) (*models.Draft, error) {
+	d := &models.Draft{}
+	result := s.connection.Preload("Picks", func(query *gorm.DB) *gorm.DB {
+		return query.Order("team, user_id")
+	}).First(d, id)
+	d, err := resultOrError(d, result)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	d.Teams, d.Apart = draft.Summarize(*d)
+	return d, nil
+}
+
+// MoveDraftPick moves a player to another of a draft's teams. It returns nil when the draft
+// doesn't exist.
+func (s session) MoveDraftPick(draftId, userId uint, team int) (*models.Draft, error) {
+	err := s.Transaction(func(tx session) error {
+		d, err := tx.lockDraft(draftId)
+		if err != nil || d == nil {
+			return err
+		}
+		if err := draft.CheckMove(*d, userId, team); err != nil {
+			return err
+		}
+		return tx.connection.Model(&models.DraftPick{}).Where("draft_id = ? AND user_id = ?", draftId, userId).Update("team", team).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDraft(draftId)
+}
+
+// CommitDraft creates a draft's teams in its league, each with a roster of its picks. Every pick
+// must still be eligible for the league. Picks whose registration is no longer approved, or who
+// were rostered in the league since the draft was made, are left out. It returns the new teams,
+// or nil when the draft doesn't exist.
+func (s session) CommitDraft(draftId uint) ([]models.Team, error) {
+	var teams []models.Team
+	err := s.Transaction(func(tx session) error {
+		d, err := tx.lockDraft(draftId)
+		if err != nil || d == nil {
+			return err
+		}
+
+		league := &models.League{}
+		if err := tx.connection.Limit(1).Find(league, d.LeagueID).Error; err != nil {
+			return err
+		}
+		taken := make([]string, 0)
+		if err := tx.connection.Model(&models.Team{}).Where("league_id = ?", d.LeagueID).Pluck("name", &taken).Error; err != nil {
+			return err
+		}
+		if err := draft.CheckCommit(*d, roster.Limit(*league), taken); err != nil {
+			return err
+		}
+
+		draftable := make([]uint, 0)
+		err = tx.connection.Raw(`SELECT r.user_id FROM registrations r WHERE r.league_id = ? AND `+draftableSql,
+			d.LeagueID, models.RegistrationApproved).Scan(&draftable).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		teams = make([]models.Team, 0, len(d.TeamNames))
+		for i, name := range d.TeamNames {
+			team, err := tx.CreateTeam(&models.Team{Name: name, LeagueID: d.LeagueID})
+			if err != nil {
+				return err
+			}
+			for _, pick := range d.Picks {
+				if pick.Team != i || !slices.Contains(draftable, pick.UserID) {
+					continue
+				}
+				if err := tx.checkEligibility(d.LeagueID, pick.UserID, team.ID); err != nil {
+					return err
+				}
+				player := models.RosterPlayer{RosterID: team.RosterID, UserID: pick.UserID, Position: pick.Position}
+				roster.Normalize(&player)
+				if err := tx.connection.Create(&player).Error; err != nil {
+					return err
+				}
+				if err := tx.startMembership(team.ID, pick.UserID, now, nil); err != nil {
+					return err
+				}
+			}
+			teams = append(teams, *team)
+		}
+
+		return tx.connection.Model(&models.Draft{}).Where("id = ?", d.ID).Update("committed_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// lockDraft loads a draft with its picks, locked against other changes, or nil when it doesn't exist
+func (s session) lockDraft(id uint) (*models.Draft, error) {
+	d := &models.Draft{}
+	result := s.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(d, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	if err := s.connection.Where("draft_id = ?", id).Find(&d.Picks).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
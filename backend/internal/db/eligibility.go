@@ -0,0 +1,114 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"gorm.io/gorm/clause"
+)
+
+// SetEligibilityRules replaces a league's eligibility rules. It returns nil when the league
+// doesn't exist.
+func (s session) SetEligibilityRules(leagueId uint, rules models.EligibilityRules) (*models.League, error) {
+	result := s.connection.Model(&models.League{}).Where("id = ?", leagueId).Updates(map[string]any{
+		"min_age":         rules.MinAge,
+		"max_skill_level": rules.MaxSkillLevel,
+		"one_team":        rules.OneTeam,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	league := &models.League{}
+	result = s.connection.First(league, leagueId)
+	return resultOrError(league, result)
+}
+
+// GetEligibilityExceptions lists the exceptions approved in a league
+func (s session) GetEligibilityExceptions(leagueId uint) ([]models.EligibilityException, error) {
+	exceptions := make([]models.EligibilityException, 0)
+	result := s.connection.Where("league_id = ?", leagueId).Order("user_id, rule").Find(&exceptions)
+	return resultsOrError(exceptions, result)
+}
+
+// SaveEligibilityException approves an exception, replacing the reason of an earlier approval of
+// the same one
+func (s session) SaveEligibilityException(exception *models.EligibilityException) (*models.EligibilityException, error) {
+	if !eligibility.ValidRule(exception.Rule) {
+		return nil, eligibility.ErrUnknownRule
+	}
+	result := s.connection.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "league_id"}, {Name: "user_id"}, {Name: "rule"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "approved_by", "updated_at"}),
+	}).Create(exception)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	result = s.connection.Where("league_id = ? AND user_id = ? AND rule = ?", exception.LeagueID, exception.UserID, exception.Rule).First(exception)
+	return resultOrError(exception, result)
+}
+
+// DeleteEligibilityException withdraws an exception. It reports whether there was one to withdraw.
+func (s session) DeleteEligibilityException(leagueId, exceptionId uint) (bool, error) {
+	result := s.connection.Where("league_id = ?", leagueId).Delete(&models.EligibilityException{}, exceptionId)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CheckEligibility lists the rules of a league a player breaks, less those a manager has excepted.
+// It returns nil when the league or player doesn't exist.
+func (s session) CheckEligibility(leagueId, userId uint) ([]models.EligibilityViolation, error) {
+	league, player, start, err := s.eligibilityOf(leagueId, userId, 0)
+	if err != nil || league == nil {
+		return nil, err
+	}
+	return eligibility.Check(league.EligibilityRules, *player, start), nil
+}
+
+// checkEligibility returns an error listing the rules of a league a player breaks. Being rostered
+// on the team the player is joining doesn't count against the one team rule.
+func (s session) checkEligibility(leagueId, userId, joiningTeamId uint) error {
+	league, player, start, err := s.eligibilityOf(leagueId, userId, joiningTeamId)
+	if err != nil || league == nil {
+		return err
+	}
+	return eligibility.Enforce(league.EligibilityRules, *player, start)
+}
+
+// eligibilityOf loads what a league's rules are checked against: the player, the other teams in
+// the league they're rostered on, their exceptions and when the league's season starts. The
+// league is nil when it or the player doesn't exist.
+func (s session) eligibilityOf(leagueId, userId, joiningTeamId uint) (*models.League, *eligibility.Player, time.Time, error) {
+	league := &models.League{}
+	result := s.connection.Limit(1).Find(league, leagueId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, nil, time.Time{}, result.Error
+	}
+
+	users, err := s.GetUsersByIds([]uint{userId})
+	if err != nil || len(users) == 0 {
+		return nil, nil, time.Time{}, err
+	}
+	player := &eligibility.Player{User: users[0], Teams: make([]string, 0), Exceptions: make([]models.EligibilityRule, 0)}
+
+	err = s.connection.Model(&models.Team{}).
+		Joins("JOIN player_rosters pr ON pr.roster_id = teams.roster_id").
+		Where("teams.league_id = ? AND pr.user_id = ? AND teams.archived_at IS NULL AND teams.id <> ?", leagueId, userId, joiningTeamId).
+		Order("teams.name").Pluck("teams.name", &player.Teams).Error
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	err = s.connection.Model(&models.EligibilityException{}).Where("league_id = ? AND user_id = ?", leagueId, userId).Pluck("rule", &player.Exceptions).Error
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	season := &models.Season{}
+	if err := s.connection.Select("id", "start").Limit(1).Find(season, league.SeasonID).Error; err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	start := season.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return league, player, start, nil
+}
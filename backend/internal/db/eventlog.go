@@ -0,0 +1,222 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/eventlog"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	goalEvent    = "goal"
+	penaltyEvent = "penalty"
+	shotEvent    = "shot"
+	goalieEvent  = "goalie_change"
+)
+
+// ErrEventLogOutOfSync is returned when an undo or redo doesn't match the events as they are now,
+// such as undoing the creation of a goal that no longer exists
+var ErrEventLogOutOfSync = errors.New("the event log doesn't match the game's events")
+
+// logEvent appends a change to a game's event log. It should be called inside the transaction
+// that made the change. before and after are snapshots of the event, nil when it didn't exist.
+// Changes to a game whose sheet has been signed off are refused with ErrGameLocked.
+func (s session) logEvent(kind string, gameId, eventId uint, action models.EventLogAction, before, after any) error {
+	if err := s.checkUnlocked(gameId); err != nil {
+		return err
+	}
+
+	entry := &models.EventLogEntry{
+		GameID:  gameId,
+		Kind:    kind,
+		EventID: eventId,
+		Action:  action,
+		UserID:  s.editor.userId,
+		Reason:  s.editor.reason,
+	}
+	if s.editor.replay != "" {
+		entry.Action = s.editor.replay
+		entry.Reverts = s.editor.reverts
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	return s.connection.Create(entry).Error
+}
+
+// logAmendment logs a corrected event. An event moved to another game is logged under both
+// games, so the history of each shows it leaving or arriving.
+func (s session) logAmendment(kind string, fromGameId, toGameId, eventId uint, before, after any) error {
+	if err := s.logEvent(kind, toGameId, eventId, models.EventAmended, before, after); err != nil {
+		return err
+	}
+	if fromGameId == toGameId {
+		return nil
+	}
+	return s.logEvent(kind, fromGameId, eventId, models.EventAmended, before, after)
+}
+
+// GetEventLog returns every change made to a game's events, oldest first
+func (s session) GetEventLog(gameId uint) ([]models.EventLogEntry, error) {
+	entries := make([]models.EventLogEntry, 0)
+	err := s.connection.Where("game_id = ?", gameId).Order("id").Find(&entries)
+	return resultsOrError(entries, err)
+}
+
+// UndoEvent reverses the latest change to a game's events that hasn't been undone, and returns
+// the entry it reversed. A nil entry means there was nothing to undo.
+func (s session) UndoEvent(gameId uint) (*models.EventLogEntry, error) {
+	return s.replayEvent(gameId, true)
+}
+
+// RedoEvent applies the most recently undone change again, and returns the entry it applied.
+// A nil entry means there was nothing to redo.
+func (s session) RedoEvent(gameId uint) (*models.EventLogEntry, error) {
+	return s.replayEvent(gameId, false)
+}
+
+func (s session) replayEvent(gameId uint, undo bool) (*models.EventLogEntry, error) {
+	var target *models.EventLogEntry
+	err := s.Transaction(func(tx session) error {
+		// Serialize undo and redo for a game so two officials can't reverse the same entry
+		err := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&models.Game{}, gameId).Error
+		if err != nil {
+			return err
+		}
+
+		entries, err := tx.GetEventLog(gameId)
+		if err != nil {
+			return err
+		}
+
+		undoStack, redoStack := eventlog.Stacks(entries)
+		stack, action := redoStack, models.EventRedone
+		if undo {
+			stack, action = undoStack, models.EventUndone
+		}
+		if len(stack) == 0 {
+			return nil
+		}
+
+		entry := stack[len(stack)-1]
+		target = &entry
+		step := eventlog.RedoStep(entry)
+		if undo {
+			step = eventlog.UndoStep(entry)
+		}
+
+		tx.editor.replay = action
+		tx.editor.reverts = &entry.ID
+		return tx.applyStep(entry.Kind, entry.EventID, step)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (s session) applyStep(kind string, eventId uint, step eventlog.Step) error {
+	switch kind {
+	case goalEvent:
+		goal := &models.Goal{}
+		if step.Action != models.EventVoided {
+			if err := json.Unmarshal(step.Snapshot, goal); err != nil {
+				return err
+			}
+			for i := range goal.OnIce {
+				goal.OnIce[i].ID = 0
+			}
+		}
+
+		var err error
+		switch step.Action {
+		case models.EventCreated:
+			_, err = s.SaveGoal(goal)
+		case models.EventAmended:
+			goal, err = s.UpdateGoal(goal)
+		default:
+			goal, err = s.DeleteGoal(eventId)
+		}
+		if err == nil && goal == nil {
+			return ErrEventLogOutOfSync
+		}
+		return err
+
+	case penaltyEvent:
+		penalty := &models.Penalty{}
+		if step.Action != models.EventVoided {
+			if err := json.Unmarshal(step.Snapshot, penalty); err != nil {
+				return err
+			}
+			penalty.PenaltyType = models.PenaltyType{}
+		}
+
+		var err error
+		switch step.Action {
+		case models.EventCreated:
+			err = s.CreatePenalty(penalty)
+		case models.EventAmended:
+			penalty, err = s.UpdatePenalty(penalty)
+		default:
+			penalty, err = s.DeletePenalty(eventId)
+		}
+		if err == nil && penalty == nil {
+			return ErrEventLogOutOfSync
+		}
+		return err
+
+	case shotEvent:
+		shot := &models.ShotOnGoal{}
+		if step.Action != models.EventVoided {
+			if err := json.Unmarshal(step.Snapshot, shot); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		switch step.Action {
+		case models.EventCreated:
+			_, err = s.SaveShotOnGoal(shot)
+		case models.EventAmended:
+			shot, err = s.UpdateShotOnGoal(shot)
+		default:
+			shot, err = s.DeleteShotOnGoal(eventId)
+		}
+		if err == nil && shot == nil {
+			return ErrEventLogOutOfSync
+		}
+		return err
+
+	case goalieEvent:
+		// Goalie changes are only ever recorded or voided
+		change := &models.GoalieChange{}
+		var err error
+		switch step.Action {
+		case models.EventCreated:
+			if err := json.Unmarshal(step.Snapshot, change); err != nil {
+				return err
+			}
+			_, err = s.SaveGoalieChange(change)
+		case models.EventVoided:
+			change, err = s.DeleteGoalieChange(eventId)
+		default:
+			return ErrEventLogOutOfSync
+		}
+		if err == nil && change == nil {
+			return ErrEventLogOutOfSync
+		}
+		return err
+	}
+	return ErrEventLogOutOfSync
+}
@@ -0,0 +1,25 @@
+package db
+
+import "gorm.io/gorm"
+
+// EventFilter narrows down the goals, penalties or shots on goal returned. Zero values match
+// everything. Shots aren't credited to a player, so PlayerID doesn't apply to them.
+type EventFilter struct {
+	GameID   uint
+	TeamID   uint
+	PlayerID uint
+	Period   uint
+}
+
+func (f EventFilter) scope(query *gorm.DB) *gorm.DB {
+	if f.GameID != 0 {
+		query = query.Where("game_id = ?", f.GameID)
+	}
+	if f.TeamID != 0 {
+		query = query.Where("team_id = ?", f.TeamID)
+	}
+	if f.Period != 0 {
+		query = query.Where("period = ?", f.Period)
+	}
+	return query
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun builds queries without a database so the generated SQL can be checked
+func dryRun(t *testing.T) *gorm.DB {
+	conn, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	return conn
+}
+
+func TestEventFilterScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter EventFilter
+		where  string
+		vars   []any
+	}{
+		{"No filter", EventFilter{}, "", []any{}},
+		{"Game", EventFilter{GameID: 4}, " WHERE game_id = $1", []any{uint(4)}},
+		{"Team and period", EventFilter{TeamID: 2, Period: 3}, " WHERE team_id = $1 AND period = $2", []any{uint(2), uint(3)}},
+		{"Player is left to the caller", EventFilter{GameID: 4, PlayerID: 9}, " WHERE game_id = $1", []any{uint(4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shots []models.ShotOnGoal
+			stmt := tt.filter.scope(dryRun(t)).Find(&shots).Statement
+
+			assert.Equal(t, `SELECT * FROM "shots_on_goal"`+tt.where, stmt.SQL.String())
+			assert.Equal(t, tt.vars, stmt.Vars)
+		})
+	}
+}
+
+func TestGetShotsOnGoalFilter(t *testing.T) {
+	conn := dryRun(t)
+	var sql string
+	var vars []any
+	err := conn.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	})
+	require.NoError(t, err)
+
+	s := session{connection: conn}
+	shots, err := s.GetShotsOnGoal(EventFilter{GameID: 4, TeamID: 2, PlayerID: 9, Period: 1})
+
+	assert.NoError(t, err)
+	assert.Empty(t, shots)
+	assert.Equal(t, `SELECT * FROM "shots_on_goal" WHERE game_id = $1 AND team_id = $2 AND period = $3 ORDER BY game_id, period, shot_time, id`, sql)
+	assert.Equal(t, []any{uint(4), uint(2), uint(1)}, vars)
+}
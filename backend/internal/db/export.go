@@ -0,0 +1,237 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+	"gorm.io/gorm"
+)
+
+// ExportFilter narrows an export to a season, league, team or game. Zero IDs mean no filter.
+type ExportFilter struct {
+	SeasonID uint
+	LeagueID uint
+	TeamID   uint
+	GameID   uint
+}
+
+func (f ExportFilter) params() map[string]any {
+	return map[string]any{
+		"season": f.SeasonID,
+		"league": f.LeagueID,
+		"team":   f.TeamID,
+		"game":   f.GameID,
+	}
+}
+
+// exportedGames are the games an export covers. A league or team filter matches games where
+// either side is in it.
+const exportedGames = `
+	SELECT g.id
+	FROM games g
+		JOIN teams home ON home.id = g.home_team_id
+		JOIN teams away ON away.id = g.away_team_id
+	WHERE (@season = 0 OR g.season_id = @season)
+		AND (@league = 0 OR home.league_id = @league OR away.league_id = @league)
+		AND (@team = 0 OR g.home_team_id = @team OR g.away_team_id = @team)
+		AND (@game = 0 OR g.id = @game)`
+
+// StreamSchedule calls fn with each game matching the filter in start order, reading one row
+// at a time so large schedules are never held in memory
+func (s session) StreamSchedule(filter ExportFilter, fn func(models.ScheduleExportRow) error) error {
+	query := s.connection.Raw(`
+		SELECT g.id AS game_id, g.start, g.status, COALESCE(l.name, '') AS league,
+			home.name AS home_team, away.name AS away_team, COALESCE(v.name, '') AS venue,
+			g.home_team_score AS home_score, g.away_team_score AS away_score
+		FROM games g
+			JOIN teams home ON home.id = g.home_team_id
+			JOIN teams away ON away.id = g.away_team_id
+			LEFT JOIN leagues l ON l.id = home.league_id
+			LEFT JOIN venues v ON v.id = g.venue_id
+		WHERE g.id IN (`+exportedGames+`)
+		ORDER BY g.start, g.id`, filter.params())
+	return streamRows(s, query, fn)
+}
+
+// StreamRosters calls fn with each player on the rosters of the teams matching the filter,
+// ordered by team and then player name. The game filter doesn't apply to rosters.
+func (s session) StreamRosters(filter ExportFilter, fn func(models.RosterExportRow) error) error {
+	query := s.connection.Raw(`
+		SELECT COALESCE(l.name, '') AS league, t.id AS team_id, t.name AS team,
+			u.id AS player_id, u.first_name, u.last_name, u.email, u.phone,
+			pr.jersey_number AS jersey, COALESCE(pr.position, 'skater') AS position,
+			r.captain_id = u.id AS captain
+		FROM teams t
+			JOIN rosters r ON r.id = t.roster_id
+			JOIN player_rosters pr ON pr.roster_id = r.id
+			JOIN users u ON u.id = pr.user_id
+			LEFT JOIN leagues l ON l.id = t.league_id
+		WHERE (@season = 0 OR l.season_id = @season)
+			AND (@league = 0 OR t.league_id = @league)
+			AND (@team = 0 OR t.id = @team)
+		ORDER BY t.name, t.id, u.last_name, u.first_name, u.id`, filter.params())
+	return streamRows(s, query, fn)
+}
+
+// StreamGoals calls fn with each goal scored in the games matching the filter, in game order.
+// A team filter keeps only that team's goals.
+func (s session) StreamGoals(filter ExportFilter, fn func(models.GoalExportRow) error) error {
+	query := s.connection.Raw(`
+		SELECT goals.id AS goal_id, goals.game_id, g.start, COALESCE(t.name, '') AS team,
+			goals.period, goals.duration AS elapsed,
+			COALESCE(scorer.first_name || ' ' || scorer.last_name, '') AS scorer,
+			COALESCE(a1.first_name || ' ' || a1.last_name, '') AS assist1,
+			COALESCE(a2.first_name || ' ' || a2.last_name, '') AS assist2,
+			goals.strength, goals.empty_net, goals.is_penalty_shot
+		FROM goals
+			JOIN games g ON g.id = goals.game_id
+			LEFT JOIN teams t ON t.id = goals.team_id
+			LEFT JOIN users scorer ON scorer.id = goals.user_id
+			LEFT JOIN users a1 ON a1.id = goals.assist1_id
+			LEFT JOIN users a2 ON a2.id = goals.assist2_id
+		WHERE goals.game_id IN (`+exportedGames+`)
+			AND (@team = 0 OR goals.team_id = @team)
+		ORDER BY g.start, goals.game_id, goals.period, goals.duration, goals.id`, filter.params())
+	return streamRows(s, query, fn)
+}
+
+// StreamPenalties calls fn with each penalty called in the games matching the filter, in game
+// order. A team filter keeps only that team's penalties.
+func (s session) StreamPenalties(filter ExportFilter, fn func(models.PenaltyExportRow) error) error {
+	query := s.connection.Raw(`
+		SELECT p.id AS penalty_id, p.game_id, g.start, COALESCE(t.name, '') AS team,
+			p.period, p.game_time AS elapsed,
+			COALESCE(u.first_name || ' ' || u.last_name, '') AS player,
+			COALESCE(pt.name, '') AS infraction, COALESCE(pt.severity, '') AS severity,
+			CASE WHEN p.duration > 0 THEN p.duration / 60 ELSE COALESCE(pt.duration, 0) END AS minutes
+		FROM penalties p
+			JOIN games g ON g.id = p.game_id
+			LEFT JOIN teams t ON t.id = p.team_id
+			LEFT JOIN users u ON u.id = p.player_id
+			LEFT JOIN penalty_types pt ON pt.id = p.penalty_type_id
+		WHERE p.game_id IN (`+exportedGames+`)
+			AND (@team = 0 OR p.team_id = @team)
+		ORDER BY g.start, p.game_id, p.period, p.game_time, p.id`, filter.params())
+	return streamRows(s, query, fn)
+}
+
+// StreamShots calls fn with each shot on goal in the games matching the filter, in game order.
+// A team filter keeps only that team's shots.
+func (s session) StreamShots(filter ExportFilter, fn func(models.ShotExportRow) error) error {
+	query := s.connection.Raw(`
+		SELECT s.id AS shot_id, s.game_id, g.start, COALESCE(t.name, '') AS team, s.period, s.shot_time,
+			COALESCE(u.first_name || ' ' || u.last_name, '') AS goalie
+		FROM shots_on_goal s
+			JOIN games g ON g.id = s.game_id
+			LEFT JOIN teams t ON t.id = s.team_id
+			LEFT JOIN users u ON u.id = s.goalie_id
+		WHERE s.game_id IN (`+exportedGames+`)
+			AND (@team = 0 OR s.team_id = @team)
+		ORDER BY g.start, s.game_id, s.period, s.shot_time, s.id`, filter.params())
+	return streamRows(s, query, fn)
+}
+
+// StreamStandings calls fn with each row of a league's table in rank order. Ranking needs every
+// final result, so those are read in full before the first row is handed out.
+func (s session) StreamStandings(league *models.League, config standings.Config, fn func(standings.Row) error) error {
+	results, err := s.GetFinalGameResults(league.ID)
+	if err != nil {
+		return err
+	}
+	for _, row := range standings.Compute(league.Teams, results, config) {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registrationExportRow is a registration with its registrant and answers, the answers as the
+// JSON array of key and answer pairs the query builds
+type registrationExportRow struct {
+	ID         uint
+	CreatedAt  time.Time
+	SeasonID   uint
+	LeagueID   uint
+	UserID     uint
+	Position   models.Position
+	Status     models.RegistrationStatus
+	FirstName  string
+	LastName   string
+	Email      string
+	Phone      string
+	SkillLevel int
+	Answers    string
+}
+
+// StreamRegistrations calls fn with each registration matching the filter, oldest first like
+// GetRegistrations, with its registrant and answers. Rows are read one at a time rather than
+// preloaded so a season's registrations are never all held in memory.
+func (s session) StreamRegistrations(filter RegistrationFilter, fn func(models.Registration) error) error {
+	query := s.connection.Raw(`
+		SELECT r.id, r.created_at, r.season_id, r.league_id, r.user_id, r.position, r.status,
+			COALESCE(u.first_name, '') AS first_name, COALESCE(u.last_name, '') AS last_name,
+			COALESCE(u.email, '') AS email, COALESCE(u.phone, '') AS phone, COALESCE(u.skill_level, 0) AS skill_level,
+			(SELECT COALESCE(json_agg(json_build_object('key', q.key, 'answer', q.answer) ORDER BY q.id), '[]')
+				FROM questions q WHERE q.registration_id = r.id) AS answers
+		FROM registrations r
+			LEFT JOIN users u ON u.id = r.user_id
+		WHERE (@season = 0 OR r.season_id = @season)
+			AND (@league = 0 OR r.league_id = @league)
+			AND (@user = 0 OR r.user_id = @user)
+			AND (@status = '' OR r.status = @status)
+			AND (@key = '' OR EXISTS (SELECT 1 FROM questions q WHERE q.registration_id = r.id AND q.key = @key AND lower(q.answer) = lower(@answer)))
+		ORDER BY r.created_at, r.id`, map[string]any{
+		"season": filter.SeasonID,
+		"league": filter.LeagueID,
+		"user":   filter.UserID,
+		"status": string(filter.Status),
+		"key":    filter.Key,
+		"answer": filter.Answer,
+	})
+	return streamRows(s, query, func(row registrationExportRow) error {
+		r := models.Registration{
+			DbModel:  models.DbModel{ID: row.ID, CreatedAt: row.CreatedAt},
+			SeasonID: row.SeasonID,
+			LeagueID: row.LeagueID,
+			UserID:   row.UserID,
+			User: models.User{
+				DbModel:    models.DbModel{ID: row.UserID},
+				FirstName:  row.FirstName,
+				LastName:   row.LastName,
+				Email:      row.Email,
+				Phone:      row.Phone,
+				SkillLevel: row.SkillLevel,
+			},
+			Position: row.Position,
+			Status:   row.Status,
+		}
+		if err := json.Unmarshal([]byte(row.Answers), &r.Questions); err != nil {
+			return err
+		}
+		return fn(r)
+	})
+}
+
+// streamRows scans the rows of a query one at a time and hands each to fn, stopping at the
+// first error
+func streamRows[T any](s session, query *gorm.DB, fn func(T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := s.connection.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
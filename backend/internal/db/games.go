@@ -0,0 +1,167 @@
+package db
+
+import (
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/totals"
+	"gorm.io/gorm/clause"
+)
+
+func (s session) GetGame(id uint) (*models.Game, error) {
+	game := &models.Game{}
+	result := s.connection.First(game, id)
+	return resultOrError(game, result)
+}
+
+// GetGameDetails returns a game with its teams, venue, officials and the players on both rosters
+func (s session) GetGameDetails(id uint) (*models.Game, error) {
+	game := &models.Game{}
+	result := s.connection.
+		Preload("HomeTeam").
+		Preload("AwayTeam").
+		Preload("Venue").
+		Preload("ScoreKeeper").
+		Preload("PrimaryReferee").
+		Preload("SecondaryReferee").
+		Preload("HomeTeamRoster.Players").
+		Preload("AwayTeamRoster.Players").
+		First(game, id)
+	return resultOrError(game, result)
+}
+
+// syncGameTotals recomputes the score and shot totals of each game from its goal and shot
+// rows, and the strength of each goal from its penalties. It should be called inside the
+// transaction that changed those rows.
+func (s session) syncGameTotals(gameIds ...uint) error {
+	seen := make(map[uint]bool)
+	for _, id := range gameIds {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		game := &models.Game{}
+		result := s.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(game, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue // Events can reference a game that hasn't been scheduled yet
+		}
+
+		totals, err := s.deriveGameTotals(game)
+		if err != nil {
+			return err
+		}
+
+		err = s.connection.Model(game).Updates(map[string]any{
+			"home_team_score":         totals.HomeTeamScore,
+			"away_team_score":         totals.AwayTeamScore,
+			"home_team_shots_on_goal": totals.HomeTeamShotsOnGoal,
+			"away_team_shots_on_goal": totals.AwayTeamShotsOnGoal,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := s.syncGoalStrengths(game); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s session) deriveGameTotals(game *models.Game) (models.GameTotals, error) {
+	goals := make([]models.Goal, 0)
+	if err := s.connection.Select("id", "team_id", "deleted_at").Where("game_id = ?", game.ID).Find(&goals).Error; err != nil {
+		return models.GameTotals{}, err
+	}
+	shots := make([]models.ShotOnGoal, 0)
+	if err := s.connection.Select("id", "team_id", "deleted_at").Where("game_id = ?", game.ID).Find(&shots).Error; err != nil {
+		return models.GameTotals{}, err
+	}
+	return totals.Derive(*game, goals, shots), nil
+}
+
+// ReconcileGameTotals finds every game whose stored totals disagree with its goal
+// and shot rows. When repair is true the stored totals are overwritten with the derived ones.
+func (s session) ReconcileGameTotals(repair bool) ([]models.GameTotalsDiscrepancy, error) {
+	type row struct {
+		models.GameTotals
+		GameID           uint
+		DerivedHomeScore int
+		DerivedAwayScore int
+		DerivedHomeShots int
+		DerivedAwayShots int
+	}
+
+	rows := make([]row, 0)
+	err := s.connection.Raw(`
+		SELECT g.id AS game_id,
+			g.home_team_score, g.away_team_score,
+			g.home_team_shots_on_goal, g.away_team_shots_on_goal,
+			(SELECT count(*) FROM goals WHERE goals.game_id = g.id AND goals.team_id = g.home_team_id AND goals.deleted_at IS NULL) AS derived_home_score,
+			(SELECT count(*) FROM goals WHERE goals.game_id = g.id AND goals.team_id = g.away_team_id AND goals.deleted_at IS NULL) AS derived_away_score,
+			(SELECT count(*) FROM shots_on_goal s WHERE s.game_id = g.id AND s.team_id = g.home_team_id AND s.deleted_at IS NULL) AS derived_home_shots,
+			(SELECT count(*) FROM shots_on_goal s WHERE s.game_id = g.id AND s.team_id = g.away_team_id AND s.deleted_at IS NULL) AS derived_away_shots
+		FROM games g
+		WHERE g.deleted_at IS NULL
+		ORDER BY g.id`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancies := make([]models.GameTotalsDiscrepancy, 0)
+	for _, r := range rows {
+		game := models.Game{
+			DbModel:             models.DbModel{ID: r.GameID},
+			HomeTeamScore:       r.HomeTeamScore,
+			AwayTeamScore:       r.AwayTeamScore,
+			HomeTeamShotsOnGoal: r.HomeTeamShotsOnGoal,
+			AwayTeamShotsOnGoal: r.AwayTeamShotsOnGoal,
+		}
+		derived := models.GameTotals{
+			HomeTeamScore:       r.DerivedHomeScore,
+			AwayTeamScore:       r.DerivedAwayScore,
+			HomeTeamShotsOnGoal: r.DerivedHomeShots,
+			AwayTeamShotsOnGoal: r.DerivedAwayShots,
+		}
+		if discrepancy, differs := totals.Compare(game, derived); differs {
+			discrepancies = append(discrepancies, discrepancy)
+		}
+	}
+
+	if !repair || len(discrepancies) == 0 {
+		return discrepancies, nil
+	}
+
+	err = s.Transaction(func(tx session) error {
+		for i := range discrepancies {
+			if err := tx.syncGameTotals(discrepancies[i].GameID); err != nil {
+				return err
+			}
+			discrepancies[i].Repaired = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+// GetFinalGameResults returns the result of every final game between teams in a league whose
+// game sheet has been signed off. A game counts as overtime if any goal was scored after regulation.
+func (s session) GetFinalGameResults(leagueId uint) ([]models.GameResult, error) {
+	results := make([]models.GameResult, 0)
+	err := s.connection.Raw(`
+		SELECT g.id AS game_id, g.home_team_id, g.away_team_id,
+			g.home_team_score AS home_score, g.away_team_score AS away_score,
+			EXISTS (SELECT 1 FROM goals WHERE goals.game_id = g.id AND goals.period > ?) AS overtime
+		FROM games g
+			JOIN teams home ON home.id = g.home_team_id
+			JOIN teams away ON away.id = g.away_team_id
+		WHERE g.status = ? AND g.signed_off_at IS NOT NULL AND home.league_id = ? AND away.league_id = ?
+		ORDER BY g.start`, models.RegulationPeriods, models.FINAL, leagueId, leagueId).Scan(&results)
+	return resultsOrError(results, err)
+}
@@ -0,0 +1,230 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/gamesheet"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGameLocked is returned when changing the events of a game whose sheet has been signed off
+	ErrGameLocked = errors.New("the game sheet has been signed off and is locked")
+	// ErrGameNotFinal is returned when signing the sheet of a game that isn't final yet
+	ErrGameNotFinal = errors.New("only final games can be signed")
+	// ErrGameSheetChanged is returned when the sheet changed after the signer reviewed it
+	ErrGameSheetChanged = errors.New("the game sheet changed since it was reviewed")
+	// ErrNotASigner is returned when the user isn't a referee or captain of the game
+	ErrNotASigner = errors.New("only the game's referees and captains can sign its sheet")
+)
+
+// GetGameSheet returns the sign-off state of a game, or nil when the game doesn't exist
+func (s session) GetGameSheet(gameId uint) (*gamesheet.Sheet, error) {
+	game, err := s.GetGame(gameId)
+	if err != nil || game == nil {
+		return nil, err
+	}
+	return s.buildGameSheet(game)
+}
+
+func (s session) buildGameSheet(game *models.Game) (*gamesheet.Sheet, error) {
+	captains := make(map[uint]uint)
+	rosters := make([]models.Roster, 0)
+	err := s.connection.Where("id IN ?", []uint{game.HomeTeamRosterID, game.AwayTeamRosterID}).Find(&rosters).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, roster := range rosters {
+		captains[roster.ID] = roster.CaptainID
+	}
+
+	signatures := make([]models.GameSignature, 0)
+	if err := s.connection.Where("game_id = ?", game.ID).Order("id").Find(&signatures).Error; err != nil {
+		return nil, err
+	}
+
+	contents := gamesheet.Contents{}
+	err = s.connection.Model(&models.EventLogEntry{}).Where("game_id = ?", game.ID).Select("COALESCE(MAX(id), 0)").Scan(&contents.LastEntry).Error
+	if err != nil {
+		return nil, err
+	}
+	if contents.GoalieChanges, err = s.GetGoalieChanges(game.ID); err != nil {
+		return nil, err
+	}
+	for _, teamId := range []uint{game.HomeTeamID, game.AwayTeamID} {
+		lineup, err := s.getLineup(game, teamId)
+		if err != nil {
+			return nil, err
+		}
+		contents.Lineups = append(contents.Lineups, *lineup)
+	}
+
+	sheet := gamesheet.Build(game, captains[game.HomeTeamRosterID], captains[game.AwayTeamRosterID], signatures, contents)
+	return &sheet, nil
+}
+
+// SignGameSheet records a user's signature on the version of a final game's sheet they reviewed,
+// in every role they hold. Once every required signer has signed the game is locked and its
+// league's team records are updated. A nil sheet means the game doesn't exist.
+func (s session) SignGameSheet(gameId, userId uint, digest string) (*gamesheet.Sheet, error) {
+	var sheet *gamesheet.Sheet
+	err := s.Transaction(func(tx session) error {
+		game := &models.Game{}
+		result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(game, gameId)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		current, err := tx.buildGameSheet(game)
+		if err != nil {
+			return err
+		}
+		switch {
+		case game.SignedOffAt != nil:
+			return ErrGameLocked
+		case game.Status != models.FINAL:
+			return ErrGameNotFinal
+		case digest != current.Digest:
+			return ErrGameSheetChanged
+		}
+
+		roles := gamesheet.Roles(current.Required, userId)
+		if len(roles) == 0 {
+			return ErrNotASigner
+		}
+		for _, role := range roles {
+			err := tx.connection.Where("game_id = ? AND role = ?", gameId, role).Delete(&models.GameSignature{}).Error
+			if err != nil {
+				return err
+			}
+			signature := &models.GameSignature{GameID: gameId, Role: string(role), UserID: userId, Digest: digest}
+			if err := tx.connection.Create(signature).Error; err != nil {
+				return err
+			}
+		}
+
+		if sheet, err = tx.buildGameSheet(game); err != nil {
+			return err
+		}
+		if len(sheet.Missing) > 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.connection.Model(game).UpdateColumn("signed_off_at", now).Error; err != nil {
+			return err
+		}
+		game.SignedOffAt = &now
+		return tx.updateStandings(game.HomeTeamID, game.AwayTeamID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// ReopenGameSheet unlocks a signed off game so its events can be corrected. Every signature is
+// removed, so the sheet has to be signed again, and the league's team records are updated.
+func (s session) ReopenGameSheet(gameId uint) (*gamesheet.Sheet, error) {
+	var sheet *gamesheet.Sheet
+	err := s.Transaction(func(tx session) error {
+		game := &models.Game{}
+		result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(game, gameId)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.connection.Where("game_id = ?", gameId).Delete(&models.GameSignature{}).Error; err != nil {
+			return err
+		}
+		if game.SignedOffAt != nil {
+			if err := tx.connection.Model(game).UpdateColumn("signed_off_at", nil).Error; err != nil {
+				return err
+			}
+			game.SignedOffAt = nil
+			if err := tx.updateStandings(game.HomeTeamID, game.AwayTeamID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		sheet, err = tx.buildGameSheet(game)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// GetUnsignedGames returns the sheets of final games that haven't been signed off, oldest first.
+// A seasonId of 0 includes every season.
+func (s session) GetUnsignedGames(seasonId uint) ([]gamesheet.Sheet, error) {
+	games := make([]models.Game, 0)
+	query := s.connection.Where("status = ? AND signed_off_at IS NULL", models.FINAL)
+	if seasonId != 0 {
+		query = query.Where("season_id = ?", seasonId)
+	}
+	if err := query.Order("start").Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	sheets := make([]gamesheet.Sheet, 0, len(games))
+	for i := range games {
+		sheet, err := s.buildGameSheet(&games[i])
+		if err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, *sheet)
+	}
+	return sheets, nil
+}
+
+// checkUnlocked returns ErrGameLocked when a game's sheet has been signed off
+func (s session) checkUnlocked(gameId uint) error {
+	game := &models.Game{}
+	if err := s.connection.Select("id", "signed_off_at").Limit(1).Find(game, gameId).Error; err != nil {
+		return err
+	}
+	if game.SignedOffAt != nil {
+		return ErrGameLocked
+	}
+	return nil
+}
+
+// updateStandings recomputes and stores the team records of the leagues the given teams play in.
+// Leagues with an invalid standings configuration are left alone.
+func (s session) updateStandings(teamIds ...uint) error {
+	leagueIds := make([]uint, 0)
+	err := s.connection.Model(&models.Team{}).Distinct("league_id").Where("id IN ?", teamIds).Pluck("league_id", &leagueIds).Error
+	if err != nil {
+		return err
+	}
+
+	for _, leagueId := range leagueIds {
+		league, err := s.GetLeague(leagueId)
+		if err != nil {
+			return err
+		}
+		if league == nil {
+			continue
+		}
+		config, err := standings.ConfigFor(league)
+		if err != nil {
+			continue
+		}
+		results, err := s.GetFinalGameResults(leagueId)
+		if err != nil {
+			return err
+		}
+
+		table := standings.Compute(league.Teams, results, config)
+		if err := s.UpdateTeamRecords(standings.Records(table)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/goalies"
+	"gorm.io/gorm"
+)
+
+// SaveGoalieChange records a goalie change in the game's event log and credits the game's goals
+// and shots to the goalies the changes now put in net, so a change entered after the events it
+// covers still reaches them. Changes to a game whose sheet has been signed off are refused with
+// ErrGameLocked.
+func (s session) SaveGoalieChange(change *models.GoalieChange) (*models.GoalieChange, error) {
+	err := s.Transaction(func(tx session) error {
+		if err := tx.checkUnlocked(change.GameID); err != nil {
+			return err
+		}
+		if err := tx.connection.Create(change).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(goalieEvent, change.GameID, change.ID, models.EventCreated, nil, change); err != nil {
+			return err
+		}
+		return tx.syncGoalieAttribution(change.GameID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// DeleteGoalieChange voids a goalie change, keeping a copy in the event log, and credits the game's
+// goals and shots again. The deleted change is returned, or nil if no change with that ID exists.
+func (s session) DeleteGoalieChange(id uint) (*models.GoalieChange, error) {
+	change := &models.GoalieChange{}
+	err := s.Transaction(func(tx session) error {
+		if err := tx.connection.First(change, id).Error; err != nil {
+			return err
+		}
+		if err := tx.checkUnlocked(change.GameID); err != nil {
+			return err
+		}
+		if err := tx.connection.Delete(change).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(goalieEvent, change.GameID, change.ID, models.EventVoided, change, nil); err != nil {
+			return err
+		}
+		return tx.syncGoalieAttribution(change.GameID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s session) GetGoalieChanges(gameId uint) ([]models.GoalieChange, error) {
+	changes := make([]models.GoalieChange, 0)
+	result := s.connection.Where("game_id = ?", gameId).Order("period, game_time, id").Find(&changes)
+	return resultsOrError(changes, result)
+}
+
+// goalieInNet returns the goalie defending against a team at a time in a game, or 0 if the
+// net was empty or no goalie change has been recorded
+func (s session) goalieInNet(gameId, shootingTeamId, gameSeconds uint) (uint, error) {
+	game, err := s.GetGame(gameId)
+	if err != nil || game == nil {
+		return 0, err
+	}
+
+	defendingTeamId := game.HomeTeamID
+	if shootingTeamId == game.HomeTeamID {
+		defendingTeamId = game.AwayTeamID
+	}
+
+	changes := make([]models.GoalieChange, 0)
+	err = s.connection.
+		Where("game_id = ? AND team_id = ?", gameId, defendingTeamId).
+		Where("(period - 1) * ? + game_time <= ?", models.PeriodLength, gameSeconds).
+		Order("period DESC, game_time DESC, id DESC").
+		Limit(1).
+		Find(&changes).Error
+	if err != nil || len(changes) == 0 {
+		return 0, err
+	}
+	return changes[0].GoalieID, nil
+}
+
+func (s session) fillGoalGoalie(goal *models.Goal) error {
+	if goal.GoalieID != 0 || goal.EmptyNet {
+		return nil
+	}
+
+	goalie, err := s.goalieInNet(goal.GameId, goal.TeamId, models.GameSeconds(goal.Period, goal.Duration))
+	if err != nil {
+		return err
+	}
+	if goalie != 0 {
+		goal.GoalieID = goalie
+		return nil
+	}
+
+	// Without any recorded goalie an unattributed goal is unknown rather than scored into an empty net
+	var changes int64
+	err = s.connection.Model(&models.GoalieChange{}).Where("game_id = ?", goal.GameId).Count(&changes).Error
+	goal.EmptyNet = changes > 0
+	return err
+}
+
+func (s session) fillShotGoalie(shot *models.ShotOnGoal) error {
+	if shot.GoalieID != 0 {
+		return nil
+	}
+
+	goalie, err := s.goalieInNet(shot.GameId, shot.TeamId, models.GameSeconds(shot.Period, shot.ShotTime))
+	if err != nil {
+		return err
+	}
+	shot.GoalieID = goalie
+	return nil
+}
+
+// syncGoalieAttribution credits the goals and shots of a game to the goalies its goalie changes put in net
+func (s session) syncGoalieAttribution(gameId uint) error {
+	game, err := s.GetGame(gameId)
+	if err != nil || game == nil {
+		return err
+	}
+
+	changes, err := s.GetGoalieChanges(gameId)
+	if err != nil {
+		return err
+	}
+	goals := make([]models.Goal, 0)
+	if err := s.connection.Where("game_id = ?", gameId).Find(&goals).Error; err != nil {
+		return err
+	}
+	shots := make([]models.ShotOnGoal, 0)
+	if err := s.connection.Where("game_id = ?", gameId).Find(&shots).Error; err != nil {
+		return err
+	}
+
+	// Derived columns aren't edits, so they leave updated_at alone
+	changedGoals, changedShots := goalies.Attribute(game.HomeTeamID, game.AwayTeamID, changes, goals, shots)
+	for _, goal := range changedGoals {
+		err := s.connection.Model(&models.Goal{}).Where("id = ?", goal.ID).UpdateColumns(map[string]any{
+			"goalie_id": goal.GoalieID,
+			"empty_net": goal.EmptyNet,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	for _, shot := range changedShots {
+		err := s.connection.Model(&models.ShotOnGoal{}).Where("id = ?", shot.ID).UpdateColumn("goalie_id", shot.GoalieID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GoalieStatsFilter narrows goalie stats to a game or to the games in a season or league. Zero IDs mean no filter.
+type GoalieStatsFilter struct {
+	GameID   uint
+	SeasonID uint
+	LeagueID uint
+}
+
+const goalieScopedGames = `
+	SELECT g.id
+	FROM games g
+		JOIN teams home ON home.id = g.home_team_id
+	WHERE g.status <> @scheduled
+		AND (@game = 0 OR g.id = @game)
+		AND (@season = 0 OR g.season_id = @season)
+		AND (@league = 0 OR home.league_id = @league)`
+
+// GetGoalieFacts loads what's needed to compute goalie stats for the filtered games: each game's
+// length, its goalie changes, and the shots and goals each goalie faced
+func (s session) GetGoalieFacts(filter GoalieStatsFilter) ([]models.GoalieGame, []models.GoalieChange, []models.GoalieEventCount, error) {
+	args := map[string]any{
+		"game":       filter.GameID,
+		"season":     filter.SeasonID,
+		"league":     filter.LeagueID,
+		"scheduled":  models.SCHEDULED,
+		"final":      models.FINAL,
+		"regulation": models.RegulationPeriods,
+		"period":     models.PeriodLength,
+	}
+
+	// A game that went to overtime ended at the overtime goal, if there was one
+	games := make([]models.GoalieGame, 0)
+	err := s.connection.Raw(`
+		SELECT g.id AS game_id, g.home_team_id, g.away_team_id, g.status = @final AS final,
+			GREATEST(@regulation * @period, COALESCE(
+				(SELECT max((goals.period - 1) * @period + goals.duration) FROM goals WHERE goals.game_id = g.id AND goals.period > @regulation),
+				0)) AS length
+		FROM games g
+		WHERE g.id IN (`+goalieScopedGames+`)
+		ORDER BY g.id`, args).Scan(&games).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	changes := make([]models.GoalieChange, 0)
+	err = s.connection.Raw(`
+		SELECT * FROM goalie_changes
+		WHERE game_id IN (`+goalieScopedGames+`)
+		ORDER BY game_id, period, game_time, id`, args).Scan(&changes).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Events are credited to the team that was shot at, which is the opponent of the shooting team
+	counts := make([]models.GoalieEventCount, 0)
+	err = s.connection.Raw(`
+		WITH against AS (
+			SELECT s.game_id, CASE WHEN s.team_id = g.home_team_id THEN g.away_team_id ELSE g.home_team_id END AS team_id,
+				s.goalie_id, 1 AS shots, 0 AS goals
+			FROM shots_on_goal s JOIN games g ON g.id = s.game_id
+			WHERE s.game_id IN (`+goalieScopedGames+`)
+			UNION ALL
+			SELECT goals.game_id, CASE WHEN goals.team_id = g.home_team_id THEN g.away_team_id ELSE g.home_team_id END AS team_id,
+				goals.goalie_id, 0 AS shots, 1 AS goals
+			FROM goals JOIN games g ON g.id = goals.game_id
+			WHERE goals.game_id IN (`+goalieScopedGames+`)
+		)
+		SELECT game_id, team_id, goalie_id, sum(shots) AS shots, sum(goals) AS goals
+		FROM against
+		GROUP BY game_id, team_id, goalie_id
+		ORDER BY game_id, team_id, goalie_id`, args).Scan(&counts).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return games, changes, counts, nil
+}
@@ -1,14 +1,116 @@
 package db
 
-import "github.com/jak103/powerplay/internal/models"
+import (
+	"errors"
+
+	"github.com/jak103/powerplay/internal/models"
+	"gorm.io/gorm"
+)
 
 func (s session) SaveGoal(goal *models.Goal) (*models.Goal, error) {
-	result := s.connection.Create(goal)
-	return resultOrError(goal, result)
+	err := s.Transaction(func(tx session) error {
+		if err := tx.fillGoalGoalie(goal); err != nil {
+			return err
+		}
+		if err := tx.connection.Create(goal).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(goalEvent, goal.GameId, goal.ID, models.EventCreated, nil, goal); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(goal.GameId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return goal, nil
 }
 
-func (s session) GetGoals() ([]models.Goal, error) {
+func (s session) GetGoals(filter EventFilter) ([]models.Goal, error) {
 	goals := make([]models.Goal, 0)
-	err := s.connection.Find(&goals)
+	query := filter.scope(s.connection.Preload("OnIce"))
+	if filter.PlayerID != 0 {
+		query = query.Where("? IN (user_id, assist1_id, assist2_id)", filter.PlayerID)
+	}
+	err := query.Order("game_id, period, duration, id").Find(&goals)
 	return resultsOrError(goals, err)
-}
\ No newline at end of file
+}
+
+func (s session) GetGoal(id uint) (*models.Goal, error) {
+	goal := &models.Goal{}
+	result := s.connection.Preload("OnIce").First(goal, id)
+	return resultOrError(goal, result)
+}
+
+// UpdateGoal replaces a recorded goal, logs the amendment and refreshes the totals of any game
+// it touched. A nil goal is returned if no goal with that ID exists.
+func (s session) UpdateGoal(goal *models.Goal) (*models.Goal, error) {
+	err := s.Transaction(func(tx session) error {
+		existing := &models.Goal{}
+		if err := tx.connection.Preload("OnIce").First(existing, goal.ID).Error; err != nil {
+			return err
+		}
+		// The event can't be moved out of a game that was signed off either
+		if err := tx.checkUnlocked(existing.GameId); err != nil {
+			return err
+		}
+
+		goal.CreatedAt = existing.CreatedAt
+		if err := tx.fillGoalGoalie(goal); err != nil {
+			return err
+		}
+		if err := tx.connection.Omit("OnIce").Save(goal).Error; err != nil {
+			return err
+		}
+		// The on ice players are replaced rather than merged
+		if err := tx.connection.Where("goal_id = ?", goal.ID).Delete(&models.GoalOnIce{}).Error; err != nil {
+			return err
+		}
+		for i := range goal.OnIce {
+			goal.OnIce[i].ID = 0
+			goal.OnIce[i].GoalID = goal.ID
+		}
+		if len(goal.OnIce) > 0 {
+			if err := tx.connection.Create(&goal.OnIce).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.logAmendment(goalEvent, existing.GameId, goal.GameId, goal.ID, existing, goal); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(existing.GameId, goal.GameId)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// DeleteGoal voids a recorded goal, keeping a copy in the event log, and refreshes its game's
+// totals. The deleted goal is returned, or nil if no goal with that ID exists.
+func (s session) DeleteGoal(id uint) (*models.Goal, error) {
+	goal := &models.Goal{}
+	err := s.Transaction(func(tx session) error {
+		if err := tx.connection.Preload("OnIce").First(goal, id).Error; err != nil {
+			return err
+		}
+		if err := tx.connection.Where("goal_id = ?", id).Delete(&models.GoalOnIce{}).Error; err != nil {
+			return err
+		}
+		if err := tx.connection.Delete(goal).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(goalEvent, goal.GameId, goal.ID, models.EventVoided, goal, nil); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(goal.GameId)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
@@ -0,0 +1,322 @@
+package db
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/importer"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+)
+
+// errRollback undoes an import transaction that was only a dry run or found problems
+var errRollback = errors.New("the import was rolled back")
+
+// Import reads a CSV file of the given kind and saves every row in a single transaction. Rows are
+// checked against the database too, so a dry run reports everything a real import would reject.
+// Nothing is saved on a dry run or when any row has a problem; the result says which.
+func (s session) Import(kind importer.Kind, r io.Reader, dryRun bool, location *time.Location) (*importer.Result, error) {
+	result := &importer.Result{Kind: kind, DryRun: dryRun, Errors: make([]importer.RowError, 0)}
+
+	err := s.Transaction(func(tx session) error {
+		var err error
+		switch kind {
+		case importer.Users:
+			err = tx.importUsers(importer.ParseUsers(r, result), result)
+		case importer.Rosters:
+			err = tx.importRosters(importer.ParseRosters(r, result), result)
+		case importer.Games:
+			err = tx.importGames(importer.ParseGames(r, result, location), result)
+		}
+		if err != nil {
+			return err
+		}
+
+		if dryRun || len(result.Errors) > 0 {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+
+	slices.SortStableFunc(result.Errors, func(a, b importer.RowError) int {
+		return a.Row - b.Row
+	})
+	result.Saved = err == nil
+	return result, nil
+}
+
+// usersByEmail finds the users with the given emails, keyed by their normalized email
+func (s session) usersByEmail(emails []string) (map[string]models.User, error) {
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, importer.NormalizeEmail(email))
+	}
+
+	// Stored emails are compared the way NormalizeEmail compares them
+	users := make([]models.User, 0)
+	if len(normalized) > 0 {
+		if err := s.connection.Where("LOWER(TRIM(email)) IN ?", normalized).Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	byEmail := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byEmail[importer.NormalizeEmail(user.Email)] = user
+	}
+	return byEmail, nil
+}
+
+// importUsers creates the users whose emails aren't already taken. Users that exist are left as
+// they are rather than duplicated.
+func (s session) importUsers(rows []importer.UserRow, result *importer.Result) error {
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+	}
+	existing, err := s.usersByEmail(emails)
+	if err != nil {
+		return err
+	}
+
+	users := make([]models.User, 0, len(rows))
+	for _, row := range rows {
+		if _, ok := existing[row.Email]; ok {
+			result.Matched++
+			continue
+		}
+		users = append(users, models.User{
+			FirstName:   row.FirstName,
+			LastName:    row.LastName,
+			Email:       row.Email,
+			Phone:       row.Phone,
+			SkillLevel:  row.SkillLevel,
+			DateOfBirth: row.DateOfBirth,
+		})
+	}
+	result.Created = len(users)
+	if len(users) == 0 || len(result.Errors) > 0 {
+		return nil
+	}
+	return s.connection.CreateInBatches(users, 100).Error
+}
+
+// importTeams finds teams by league and name for an import, remembering them across rows.
+// Teams without a roster are given one so players and games can be attached to it.
+type importTeams struct {
+	session session
+	leagues map[uint]*models.League
+}
+
+func (s session) newImportTeams() *importTeams {
+	return &importTeams{session: s, leagues: map[uint]*models.League{}}
+}
+
+// league returns the league with its teams, or nil if it doesn't exist
+func (t *importTeams) league(id uint) (*models.League, error) {
+	if league, ok := t.leagues[id]; ok {
+		return league, nil
+	}
+	league, err := t.session.GetLeague(id)
+	if err != nil {
+		return nil, err
+	}
+	t.leagues[id] = league
+	return league, nil
+}
+
+// team returns the team with the given name in a league, or nil if there isn't one
+func (t *importTeams) team(league *models.League, name string) (*models.Team, error) {
+	name = importer.NormalizeName(name)
+	for i := range league.Teams {
+		team := &league.Teams[i]
+		if importer.NormalizeName(team.Name) != name {
+			continue
+		}
+		if team.RosterID == 0 {
+			roster, err := t.session.createRoster(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := t.session.connection.Model(&models.Team{}).Where("id = ?", team.ID).Update("roster_id", roster.ID).Error; err != nil {
+				return nil, err
+			}
+			team.RosterID = roster.ID
+		}
+		return team, nil
+	}
+	return nil, nil
+}
+
+// importRosters adds existing users, found by email, to team rosters and sets captains, checking
+// the same roster and eligibility rules as adding a player by hand. Players already on a roster
+// are counted as matched.
+func (s session) importRosters(rows []importer.RosterRow, result *importer.Result) error {
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+	}
+	users, err := s.usersByEmail(emails)
+	if err != nil {
+		return err
+	}
+
+	teams := s.newImportTeams()
+	for _, row := range rows {
+		if row.LeagueID == 0 || row.Team == "" || row.Email == "" {
+			continue
+		}
+
+		league, err := teams.league(row.LeagueID)
+		if err != nil {
+			return err
+		}
+		if league == nil {
+			result.Fail(row.Row, "league_id", "league %v does not exist", row.LeagueID)
+			continue
+		}
+		team, err := teams.team(league, row.Team)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			result.Fail(row.Row, "team", "%v has no team named %q", league.Name, row.Team)
+			continue
+		}
+		user, ok := users[row.Email]
+		if !ok {
+			result.Fail(row.Row, "email", "no user has the email %v, import them as users first", row.Email)
+			continue
+		}
+
+		// Rows go through the same rules as adding a player by hand
+		member := models.RosterMember{UserID: user.ID, Captain: row.Captain}
+		_, err = s.changeRoster(team.ID, func(tx session, team *models.Team, players []models.RosterPlayer) error {
+			return tx.addToRoster(team, players, member)
+		})
+		switch {
+		case err == nil:
+			result.Created++
+		case errors.Is(err, roster.ErrAlreadyOnRoster):
+			result.Matched++
+			if row.Captain {
+				if err := s.setCaptain(team.RosterID, user.ID, true); err != nil {
+					return err
+				}
+			}
+		case roster.IsRuleViolation(err):
+			result.Fail(row.Row, "team", "%v", err)
+		case eligibility.IsRuleViolation(err):
+			result.Fail(row.Row, "email", "%v", err)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// importGames schedules games between teams found by name in their league, at venues found by
+// name. A game already scheduled between the same teams at the same time is counted as matched.
+func (s session) importGames(rows []importer.GameRow, result *importer.Result) error {
+	venues := make([]models.Venue, 0)
+	if err := s.connection.Find(&venues).Error; err != nil {
+		return err
+	}
+
+	teams := s.newImportTeams()
+	for _, row := range rows {
+		if row.LeagueID == 0 || row.HomeTeam == "" || row.AwayTeam == "" || row.Venue == "" || row.Start.IsZero() {
+			continue
+		}
+
+		league, err := teams.league(row.LeagueID)
+		if err != nil {
+			return err
+		}
+		if league == nil {
+			result.Fail(row.Row, "league_id", "league %v does not exist", row.LeagueID)
+			continue
+		}
+		home, err := teams.team(league, row.HomeTeam)
+		if err != nil {
+			return err
+		}
+		if home == nil {
+			result.Fail(row.Row, "home_team", "%v has no team named %q", league.Name, row.HomeTeam)
+		}
+		away, err := teams.team(league, row.AwayTeam)
+		if err != nil {
+			return err
+		}
+		if away == nil {
+			result.Fail(row.Row, "away_team", "%v has no team named %q", league.Name, row.AwayTeam)
+		}
+
+		venue := findVenue(venues, row.Venue)
+		if venue == nil {
+			result.Fail(row.Row, "venue", "there is no venue named %q", row.Venue)
+		} else {
+			if !hasLockerRoom(venue, row.HomeLockerRoom) {
+				result.Fail(row.Row, "home_locker_room", "%v has no locker room %q", venue.Name, row.HomeLockerRoom)
+			}
+			if !hasLockerRoom(venue, row.AwayLockerRoom) {
+				result.Fail(row.Row, "away_locker_room", "%v has no locker room %q", venue.Name, row.AwayLockerRoom)
+			}
+		}
+		if home == nil || away == nil || venue == nil {
+			continue
+		}
+
+		var existing int64
+		err = s.connection.Model(&models.Game{}).
+			Where("home_team_id = ? AND away_team_id = ? AND start = ?", home.ID, away.ID, row.Start).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			result.Matched++
+			continue
+		}
+
+		game := &models.Game{
+			SeasonID:           league.SeasonID,
+			Start:              row.Start,
+			VenueID:            venue.ID,
+			Status:             models.SCHEDULED,
+			HomeTeamID:         home.ID,
+			HomeTeamRosterID:   home.RosterID,
+			HomeTeamLockerRoom: row.HomeLockerRoom,
+			AwayTeamID:         away.ID,
+			AwayTeamRosterID:   away.RosterID,
+			AwayTeamLockerRoom: row.AwayLockerRoom,
+		}
+		if err := s.connection.Omit("HomeTeam", "AwayTeam", "HomeTeamRoster", "AwayTeamRoster", "Venue").Create(game).Error; err != nil {
+			return err
+		}
+		result.Created++
+	}
+	return nil
+}
+
+func findVenue(venues []models.Venue, name string) *models.Venue {
+	name = importer.NormalizeName(name)
+	for i := range venues {
+		if importer.NormalizeName(venues[i].Name) == name {
+			return &venues[i]
+		}
+	}
+	return nil
+}
+
+// hasLockerRoom reports whether a locker room can be assigned at a venue. Venues that don't list
+// their locker rooms accept any.
+func hasLockerRoom(venue *models.Venue, room string) bool {
+	return room == "" || len(venue.LockerRooms) == 0 || slices.Contains(venue.LockerRooms, room)
+}
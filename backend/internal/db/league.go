@@ -13,3 +13,9 @@ func (s session) CreateLeague(request *models.League) error {
 	result := s.connection.Create(request)
 	return result.Error
 }
+
+func (s session) GetLeague(id uint) (*models.League, error) {
+	league := &models.League{}
+	result := s.connection.Preload("Teams").First(league, id)
+	return resultOrError(league, result)
+}
@@ -0,0 +1,313 @@
+package db
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/lineup"
+	"gorm.io/gorm/clause"
+)
+
+// dressedSql lists who dressed for each team in each game: the saved lineup, or for a team without
+// one the players on its roster at puck drop less those who declined or were suspended, the same
+// as a seeded lineup. Suspensions are counted like gamesServedSql, up to each game's puck drop.
+// Stats count games played from it. It takes the final status as @final.
+const dressedSql = `
+	SELECT l.game_id, l.team_id, lp.user_id
+	FROM lineups l JOIN lineup_players lp ON lp.lineup_id = l.id
+	UNION ALL
+	SELECT g.id, t.team_id, m.user_id
+	FROM games g
+		CROSS JOIN LATERAL (VALUES (g.home_team_id), (g.away_team_id)) AS t (team_id)
+		JOIN team_memberships m ON m.team_id = t.team_id ` + memberAtSql + `
+	WHERE NOT EXISTS (SELECT 1 FROM lineups l WHERE l.game_id = g.id AND l.team_id = t.team_id)
+		AND NOT EXISTS (SELECT 1 FROM rsvps r WHERE r.game_id = g.id AND r.user_id = m.user_id AND NOT r.attending)
+		AND NOT EXISTS (SELECT 1 FROM suspensions s
+			WHERE s.player_id = m.user_id AND s.starts_at < g.start
+				AND (SELECT count(*) FROM games served
+					WHERE (served.home_team_id = s.team_id OR served.away_team_id = s.team_id)
+						AND served.status = @final AND served.start > s.starts_at AND served.start < g.start) < s.games)`
+
+// memberAtSql keeps the team memberships that cover a game's puck drop
+const memberAtSql = `AND (m.started_at IS NULL OR m.started_at <= g.start) AND (m.ended_at IS NULL OR m.ended_at > g.start)`
+
+// SaveRsvp records whether a player will be at a game, replacing their earlier answer
+func (s session) SaveRsvp(rsvp *models.Rsvp) (*models.Rsvp, error) {
+	result := s.connection.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "game_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"attending", "updated_at"}),
+	}).Create(rsvp)
+	return resultOrError(rsvp, result)
+}
+
+// GetLineup returns who is dressed for a team in a game. Until the captain edits it, the lineup
+// is the team's roster less players who declined or are suspended. It returns nil when the game
+// doesn't exist.
+func (s session) GetLineup(gameId, teamId uint) (*models.Lineup, error) {
+	game, err := s.GetGame(gameId)
+	if err != nil || game == nil {
+		return nil, err
+	}
+	return s.getLineup(game, teamId)
+}
+
+// GetLineupPlayerIds returns the IDs of the players dressed for a team in a game
+func (s session) GetLineupPlayerIds(game *models.Game, teamId uint) ([]uint, error) {
+	saved, err := s.getLineup(game, teamId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(saved.Players))
+	for _, player := range saved.Players {
+		ids = append(ids, player.UserID)
+	}
+	return ids, nil
+}
+
+func (s session) getLineup(game *models.Game, teamId uint) (*models.Lineup, error) {
+	rosterId, err := lineupRosterId(game, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := &models.Lineup{}
+	result := s.connection.Where("game_id = ? AND team_id = ?", game.ID, teamId).Limit(1).Find(saved)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		saved.Players, err = s.getLineupMembers(saved.ID, rosterId)
+	} else {
+		saved.GameID, saved.TeamID = game.ID, teamId
+		saved.Players, err = s.seedLineup(game, teamId, rosterId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved.Locked = lineup.Locked(*game, time.Now())
+	return saved, nil
+}
+
+func (s session) getLineupMembers(lineupId, rosterId uint) ([]models.LineupMember, error) {
+	members := make([]models.LineupMember, 0)
+	result := s.connection.Raw(`
+		SELECT u.id AS user_id, u.first_name, u.last_name, lp.jersey_number,
+			COALESCE(lp.position, ?) AS position, COALESCE(r.captain_id = u.id, false) AS captain, lp.sub
+		FROM lineup_players lp
+			JOIN users u ON u.id = lp.user_id
+			LEFT JOIN rosters r ON r.id = ?
+		WHERE lp.lineup_id = ?
+		ORDER BY lp.jersey_number NULLS LAST, u.last_name, u.first_name, u.id`, models.Skater, rosterId, lineupId).Scan(&members)
+	return resultsOrError(members, result)
+}
+
+// seedLineup is the lineup a team has before its captain edits it. Players are taken from the
+// team's membership history, so a game keeps the roster the team had at puck drop.
+func (s session) seedLineup(game *models.Game, teamId, rosterId uint) ([]models.LineupMember, error) {
+	members := make([]models.LineupMember, 0)
+	err := s.connection.Raw(`
+		SELECT u.id AS user_id, u.first_name, u.last_name, pr.jersey_number,
+			COALESCE(pr.position, ?) AS position, COALESCE(r.captain_id = u.id, false) AS captain
+		FROM games g
+			JOIN team_memberships m ON m.team_id = ? `+memberAtSql+`
+			JOIN users u ON u.id = m.user_id
+			LEFT JOIN player_rosters pr ON pr.roster_id = ? AND pr.user_id = u.id
+			LEFT JOIN rosters r ON r.id = ?
+		WHERE g.id = ?
+		ORDER BY pr.jersey_number NULLS LAST, u.last_name, u.first_name, u.id`, models.Skater, teamId, rosterId, rosterId, game.ID).Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rostered := make([]models.RosterMember, 0, len(members))
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		rostered = append(rostered, member.RosterMember)
+		ids = append(ids, member.UserID)
+	}
+
+	declined := make([]uint, 0)
+	err = s.connection.Model(&models.Rsvp{}).Where("game_id = ? AND NOT attending", game.ID).Pluck("user_id", &declined).Error
+	if err != nil {
+		return nil, err
+	}
+	suspended, err := s.GetSuspendedPlayerIds(game.ID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return lineup.Seed(rostered, declined, suspended), nil
+}
+
+// AddLineupPlayer dresses a player for a team in a game. Players from outside the team's roster
+// are added as subs; rostered players keep their jersey number and position unless others are given.
+// Subs and rostered players alike must be eligible for the team's league.
+func (s session) AddLineupPlayer(gameId, teamId uint, member models.LineupMember, editor *models.KeyRecord) (*models.Lineup, error) {
+	return s.changeLineup(gameId, teamId, editor, func(tx session, game *models.Game, lineupId, rosterId uint, players []models.LineupPlayer) error {
+		users, err := tx.GetUsersByIds([]uint{member.UserID})
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return lineup.ErrUnknownPlayer
+		}
+
+		player := models.LineupPlayer{LineupID: lineupId, UserID: member.UserID, JerseyNumber: member.JerseyNumber, Position: member.Position, Sub: true}
+		rostered, err := tx.GetRosterMembers(rosterId)
+		if err != nil {
+			return err
+		}
+		if i := slices.IndexFunc(rostered, func(m models.RosterMember) bool { return m.UserID == member.UserID }); i >= 0 {
+			player.Sub = false
+			if player.JerseyNumber == nil {
+				player.JerseyNumber = rostered[i].JerseyNumber
+			}
+			if player.Position == "" {
+				player.Position = rostered[i].Position
+			}
+		}
+		if player.Position == "" {
+			player.Position = models.Skater
+		}
+
+		opponent := game.AwayTeamID
+		if teamId == game.AwayTeamID {
+			opponent = game.HomeTeamID
+		}
+		opponents, err := tx.GetLineupPlayerIds(game, opponent)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(opponents, member.UserID) {
+			return lineup.ErrInOtherLineup
+		}
+
+		suspended, err := tx.GetSuspendedPlayerIds(game.ID, []uint{member.UserID})
+		if err != nil {
+			return err
+		}
+		if err := lineup.CheckAdd(players, player, len(suspended) > 0); err != nil {
+			return err
+		}
+
+		team := &models.Team{}
+		if err := tx.connection.Select("id", "league_id").Limit(1).Find(team, teamId).Error; err != nil {
+			return err
+		}
+		if err := tx.checkEligibility(team.LeagueID, member.UserID, teamId); err != nil {
+			return err
+		}
+		return tx.connection.Create(&player).Error
+	})
+}
+
+// UpdateLineupPlayer changes the jersey number and position a player wears in a game
+func (s session) UpdateLineupPlayer(gameId, teamId uint, member models.LineupMember, editor *models.KeyRecord) (*models.Lineup, error) {
+	return s.changeLineup(gameId, teamId, editor, func(tx session, game *models.Game, lineupId, rosterId uint, players []models.LineupPlayer) error {
+		player := models.LineupPlayer{LineupID: lineupId, UserID: member.UserID, JerseyNumber: member.JerseyNumber, Position: member.Position}
+		if player.Position == "" {
+			player.Position = models.Skater
+		}
+		if err := lineup.CheckUpdate(players, player); err != nil {
+			return err
+		}
+
+		return tx.connection.Model(&models.LineupPlayer{}).
+			Where("lineup_id = ? AND user_id = ?", lineupId, member.UserID).
+			Updates(map[string]any{"jersey_number": player.JerseyNumber, "position": player.Position}).Error
+	})
+}
+
+// RemoveLineupPlayer takes a player out of a team's lineup for a game
+func (s session) RemoveLineupPlayer(gameId, teamId, userId uint, editor *models.KeyRecord) (*models.Lineup, error) {
+	return s.changeLineup(gameId, teamId, editor, func(tx session, game *models.Game, lineupId, rosterId uint, players []models.LineupPlayer) error {
+		result := tx.connection.Where("lineup_id = ? AND user_id = ?", lineupId, userId).Delete(&models.LineupPlayer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return lineup.ErrNotInLineup
+		}
+		return nil
+	})
+}
+
+// changeLineup runs fn with a game locked against other lineup changes and returns the lineup after
+// the change, or nil when the game doesn't exist. The first change saves the seeded lineup. Only
+// the team's captain or a manager can change a lineup: a captain until puck drop, a manager until
+// the game's sheet is signed off.
+func (s session) changeLineup(gameId, teamId uint, editor *models.KeyRecord, fn func(tx session, game *models.Game, lineupId, rosterId uint, players []models.LineupPlayer) error) (*models.Lineup, error) {
+	var changed *models.Lineup
+	err := s.Transaction(func(tx session) error {
+		game := &models.Game{}
+		result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(game, gameId)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		rosterId, err := lineupRosterId(game, teamId)
+		if err != nil {
+			return err
+		}
+		// Managers can fix a lineup until the sheet is signed off; captains only until puck drop
+		if slices.Contains(editor.Roles, auth.Manager) {
+			if game.SignedOffAt != nil {
+				return ErrGameLocked
+			}
+		} else {
+			if lineup.Locked(*game, time.Now()) {
+				return lineup.ErrLocked
+			}
+			captain := &models.Roster{}
+			if err := tx.connection.Select("id", "captain_id").Limit(1).Find(captain, rosterId).Error; err != nil {
+				return err
+			}
+			if captain.CaptainID == 0 || captain.CaptainID != editor.UserId {
+				return lineup.ErrNotCaptain
+			}
+		}
+
+		saved, err := tx.getLineup(game, teamId)
+		if err != nil {
+			return err
+		}
+		if saved.ID == 0 {
+			if err := tx.connection.Create(saved).Error; err != nil {
+				return err
+			}
+			for _, member := range saved.Players {
+				player := models.LineupPlayer{LineupID: saved.ID, UserID: member.UserID, JerseyNumber: member.JerseyNumber, Position: member.Position}
+				if err := tx.connection.Create(&player).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		players := make([]models.LineupPlayer, 0)
+		if err := tx.connection.Where("lineup_id = ?", saved.ID).Find(&players).Error; err != nil {
+			return err
+		}
+		if err := fn(tx, game, saved.ID, rosterId, players); err != nil {
+			return err
+		}
+
+		changed, err = tx.getLineup(game, teamId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// lineupRosterId is the roster a team brought to a game
+func lineupRosterId(game *models.Game, teamId uint) (uint, error) {
+	switch teamId {
+	case game.HomeTeamID:
+		return game.HomeTeamRosterID, nil
+	case game.AwayTeamID:
+		return game.AwayTeamRosterID, nil
+	}
+	return 0, lineup.ErrTeamNotInGame
+}
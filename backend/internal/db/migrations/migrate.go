@@ -61,6 +61,283 @@ func init() {
 				return tx.Migrator().DropTable("goals")
 			},
 		},
+		&gormigrate.Migration{
+			ID: "add_standings_columns",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Team{}, &models.League{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, column := range []string{"ties", "overtime_losses", "goals_for", "goals_against", "points"} {
+					if err := tx.Migrator().DropColumn(&models.Team{}, column); err != nil {
+						return err
+					}
+				}
+				for _, column := range []string{"point_system", "tiebreakers"} {
+					if err := tx.Migrator().DropColumn(&models.League{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		&gormigrate.Migration{
+			ID: "create_goalie_changes_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.GoalieChange{}, &models.Goal{}, &models.ShotOnGoal{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.Goal{}, "goalie_id"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&models.Goal{}, "empty_net"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&models.ShotOnGoal{}, "goalie_id"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("goalie_changes")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_special_teams_columns",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Penalty{}, &models.Goal{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.Penalty{}, "game_time"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&models.Goal{}, "strength")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "create_goals_on_ice_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.GoalOnIce{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("goals_on_ice")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "create_discipline_tables",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.DisciplineRule{}, &models.Suspension{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("discipline_rules", "suspensions")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_offline_sync",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Goal{}, &models.Penalty{}, &models.ShotOnGoal{}, &models.SyncOperation{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, model := range []any{&models.Goal{}, &models.Penalty{}, &models.ShotOnGoal{}} {
+					if err := tx.Migrator().DropColumn(model, "client_id"); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropTable("sync_operations")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "create_event_log_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.EventLogEntry{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("event_log_entries")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_game_sheet_sign_off",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Game{}, &models.GameSignature{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.Game{}, "signed_off_at"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("game_signatures")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_roster_management",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&models.RosterPlayer{}, &models.Team{}, &models.League{}); err != nil {
+					return err
+				}
+				return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_player_rosters_jersey ON player_rosters (roster_id, jersey_number) WHERE jersey_number IS NOT NULL").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_player_rosters_jersey").Error; err != nil {
+					return err
+				}
+				for _, column := range []string{"jersey_number", "position"} {
+					if err := tx.Migrator().DropColumn(&models.RosterPlayer{}, column); err != nil {
+						return err
+					}
+				}
+				if err := tx.Migrator().DropColumn(&models.Team{}, "archived_at"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&models.League{}, "roster_limit")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_game_lineups",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Rsvp{}, &models.Lineup{}, &models.LineupPlayer{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&models.LineupPlayer{}, &models.Lineup{}, &models.Rsvp{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_transfers",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&models.Transfer{}, &models.TeamMembership{}, &models.Season{}); err != nil {
+					return err
+				}
+				// Players already on rosters have no start date, so they count for every game their team played
+				return tx.Exec(`
+					INSERT INTO team_memberships (user_id, team_id)
+					SELECT pr.user_id, t.id FROM teams t JOIN player_rosters pr ON pr.roster_id = t.roster_id`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.Season{}, "trade_deadline"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable(&models.TeamMembership{}, &models.Transfer{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_eligibility_rules",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.League{}, &models.EligibilityException{}, &models.Registration{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, column := range []string{"min_age", "max_skill_level", "one_team"} {
+					if err := tx.Migrator().DropColumn(&models.League{}, column); err != nil {
+						return err
+					}
+				}
+				if err := tx.Migrator().DropColumn(&models.Registration{}, "league_id"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable(&models.EligibilityException{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_drafts",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.Registration{}, &models.Draft{}, &models.DraftPick{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, column := range []string{"position", "play_with"} {
+					if err := tx.Migrator().DropColumn(&models.Registration{}, column); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropTable(&models.DraftPick{}, &models.Draft{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_registration_forms",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&models.FormQuestion{}, &models.Question{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.Question{}, "key"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable(&models.FormQuestion{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_registration_capacity",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&models.League{}, &models.Season{}, &models.Registration{}, &models.Notification{}); err != nil {
+					return err
+				}
+				// Registrations from before the approval queue were already accepted
+				return tx.Exec("UPDATE registrations SET status = ?", models.RegistrationApproved).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&models.League{}, "capacity"); err != nil {
+					return err
+				}
+				for _, column := range []string{"registration_opens", "registration_closes"} {
+					if err := tx.Migrator().DropColumn(&models.Season{}, column); err != nil {
+						return err
+					}
+				}
+				for _, column := range []string{"status", "reviewed_by", "reviewed_at", "review_note"} {
+					if err := tx.Migrator().DropColumn(&models.Registration{}, column); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropTable(&models.Notification{})
+			},
+		},
+		&gormigrate.Migration{
+			ID: "add_shot_periods",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&models.ShotOnGoal{}); err != nil {
+					return err
+				}
+				// Shots were timed from puck drop; they're now timed within their period like goals and penalties
+				return tx.Exec("UPDATE shots_on_goal SET period = shot_time / ? + 1, shot_time = shot_time % ?", models.PeriodLength, models.PeriodLength).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Exec("UPDATE shots_on_goal SET shot_time = (GREATEST(period, 1) - 1) * ? + shot_time", models.PeriodLength).Error; err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&models.ShotOnGoal{}, "period")
+			},
+		},
+		&gormigrate.Migration{
+			ID: "scope_sync_ids_to_games",
+			Migrate: func(tx *gorm.DB) error {
+				// Op and client IDs only have to be unique within a game
+				statements := []string{
+					"DROP INDEX IF EXISTS idx_goals_client_id",
+					"DROP INDEX IF EXISTS idx_penalties_client_id",
+					"DROP INDEX IF EXISTS idx_shots_on_goal_client_id",
+					"DROP INDEX IF EXISTS idx_sync_operations_op_id",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_goals_game_client ON goals (game_id, client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_penalties_game_client ON penalties (game_id, client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_shots_on_goal_game_client ON shots_on_goal (game_id, client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_operations_game_op ON sync_operations (game_id, op_id)",
+				}
+				for _, statement := range statements {
+					if err := tx.Exec(statement).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				statements := []string{
+					"DROP INDEX IF EXISTS idx_goals_game_client",
+					"DROP INDEX IF EXISTS idx_penalties_game_client",
+					"DROP INDEX IF EXISTS idx_shots_on_goal_game_client",
+					"DROP INDEX IF EXISTS idx_sync_operations_game_op",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_goals_client_id ON goals (client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_penalties_client_id ON penalties (client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_shots_on_goal_client_id ON shots_on_goal (client_id) WHERE client_id <> ''",
+					"CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_operations_op_id ON sync_operations (op_id)",
+				}
+				for _, statement := range statements {
+					if err := tx.Exec(statement).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 
 		// Add more migrations here
 	)
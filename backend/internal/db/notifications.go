@@ -1,6 +1,10 @@
 package db
 
-import "github.com/jak103/powerplay/internal/models"
+import (
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
 
 func (s session) SaveSubscription(request *models.NotificationSubscription) error {
 	result := s.connection.Create(request)
@@ -14,3 +18,25 @@ func (s session) GetSubscriptionsByTopic(topic string) ([]models.NotificationSub
 	return resultsOrError(subs, result)
 
 }
+
+// GetNotifications lists the notifications in a user's inbox, newest first
+func (s session) GetNotifications(userId uint, unreadOnly bool) ([]models.Notification, error) {
+	notifications := make([]models.Notification, 0)
+	query := s.connection.Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	result := query.Order("created_at DESC, id DESC").Find(&notifications)
+	return resultsOrError(notifications, result)
+}
+
+// ReadNotifications marks every unread notification in a user's inbox as read
+func (s session) ReadNotifications(userId uint) error {
+	return s.connection.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Update("read_at", time.Now()).Error
+}
+
+// notify leaves a message in a user's inbox. It doesn't push: push subscriptions aren't tied to
+// a user yet, so a push would reach every subscriber rather than the one it's for.
+func (s session) notify(userId uint, topic models.Topic, message string) error {
+	return s.connection.Create(&models.Notification{UserID: userId, Topic: topic, Message: message}).Error
+}
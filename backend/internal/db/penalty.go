@@ -1,16 +1,109 @@
 package db
 
-import "github.com/jak103/powerplay/internal/models"
+import (
+	"errors"
 
-func (s session) GetPenalties() ([]models.Penalty, error) {
+	"github.com/jak103/powerplay/internal/models"
+	"gorm.io/gorm"
+)
+
+func (s session) GetPenalties(filter EventFilter) ([]models.Penalty, error) {
 	penalties := make([]models.Penalty, 0)
-	err := s.connection.Preload("PenaltyType").Find(&penalties)
+	query := filter.scope(s.connection.Preload("PenaltyType"))
+	if filter.PlayerID != 0 {
+		query = query.Where("player_id = ?", filter.PlayerID)
+	}
+	err := query.Order("game_id, period, game_time, id").Find(&penalties)
 	return resultsOrError(penalties, err)
 }
 
+func (s session) GetPenalty(id uint) (*models.Penalty, error) {
+	penalty := &models.Penalty{}
+	result := s.connection.Preload("PenaltyType").First(penalty, id)
+	return resultOrError(penalty, result)
+}
+
 func (s session) CreatePenalty(request *models.Penalty) error {
-	result := s.connection.Create(request)
-	return result.Error
+	return s.Transaction(func(tx session) error {
+		if err := tx.connection.Create(request).Error; err != nil {
+			return err
+		}
+		if err := tx.applyDiscipline(request); err != nil {
+			return err
+		}
+		if err := tx.logEvent(penaltyEvent, request.GameID, request.ID, models.EventCreated, nil, request); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(request.GameID)
+	})
+}
+
+// UpdatePenalty replaces a recorded penalty, logs the amendment and refreshes the goal strengths
+// of any game it touched. When the player, team or type changes, the suspensions it handed out
+// are worked out again. A nil penalty is returned if no penalty with that ID exists.
+func (s session) UpdatePenalty(penalty *models.Penalty) (*models.Penalty, error) {
+	err := s.Transaction(func(tx session) error {
+		existing := &models.Penalty{}
+		if err := tx.connection.First(existing, penalty.ID).Error; err != nil {
+			return err
+		}
+		// The event can't be moved out of a game that was signed off either
+		if err := tx.checkUnlocked(existing.GameID); err != nil {
+			return err
+		}
+
+		penalty.CreatedAt = existing.CreatedAt
+		if err := tx.connection.Omit("PenaltyType").Save(penalty).Error; err != nil {
+			return err
+		}
+
+		if penalty.PlayerID != existing.PlayerID || penalty.TeamID != existing.TeamID || penalty.PenaltyTypeID != existing.PenaltyTypeID {
+			if err := tx.connection.Where("penalty_id = ?", penalty.ID).Delete(&models.Suspension{}).Error; err != nil {
+				return err
+			}
+			if err := tx.applyDiscipline(penalty); err != nil {
+				return err
+			}
+		}
+		if err := tx.logAmendment(penaltyEvent, existing.GameID, penalty.GameID, penalty.ID, existing, penalty); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(existing.GameID, penalty.GameID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return penalty, nil
+}
+
+// DeletePenalty voids a recorded penalty along with any suspension it handed out, keeping a copy
+// in the event log, and refreshes its game's goal strengths. The deleted penalty is returned, or
+// nil if no penalty with that ID exists.
+func (s session) DeletePenalty(id uint) (*models.Penalty, error) {
+	penalty := &models.Penalty{}
+	err := s.Transaction(func(tx session) error {
+		if err := tx.connection.First(penalty, id).Error; err != nil {
+			return err
+		}
+		if err := tx.connection.Where("penalty_id = ?", id).Delete(&models.Suspension{}).Error; err != nil {
+			return err
+		}
+		if err := tx.connection.Delete(penalty).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(penaltyEvent, penalty.GameID, penalty.ID, models.EventVoided, penalty, nil); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(penalty.GameID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return penalty, nil
 }
 
 func (s session) GetPenaltyTypes() ([]models.PenaltyType, error) {
@@ -18,3 +111,9 @@ func (s session) GetPenaltyTypes() ([]models.PenaltyType, error) {
 	err := s.connection.Find(&penaltyTypes)
 	return resultsOrError(penaltyTypes, err)
 }
+
+func (s session) GetPenaltyType(id uint) (*models.PenaltyType, error) {
+	penaltyType := &models.PenaltyType{}
+	result := s.connection.First(penaltyType, id)
+	return resultOrError(penaltyType, result)
+}
@@ -0,0 +1,342 @@
+package db
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/registration"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegistrationFilter narrows a list of registrations. Zero values mean no filter; a question
+// filter keeps registrations that gave the answer to the question with that key.
+type RegistrationFilter struct {
+	SeasonID uint
+	LeagueID uint
+	UserID   uint
+	Status   models.RegistrationStatus
+	Key      string
+	Answer   string
+}
+
+// GetForm returns the questions of a season's registration form in the order they're asked
+func (s session) GetForm(seasonId uint) ([]models.FormQuestion, error) {
+	questions := make([]models.FormQuestion, 0)
+	result := s.connection.Where("season_id = ?", seasonId).Order("id").Find(&questions)
+	return resultsOrError(questions, result)
+}
+
+// SetForm replaces a season's registration form. Answers already given keep the text of the
+// questions they answered. It returns nil when the season doesn't exist.
+func (s session) SetForm(seasonId uint, questions []models.FormQuestion) ([]models.FormQuestion, error) {
+	if err := registration.CheckForm(questions); err != nil {
+		return nil, err
+	}
+
+	found := false
+	err := s.Transaction(func(tx session) error {
+		var seasons int64
+		if err := tx.connection.Model(&models.Season{}).Where("id = ?", seasonId).Count(&seasons).Error; err != nil || seasons == 0 {
+			return err
+		}
+		if err := tx.connection.Where("season_id = ?", seasonId).Delete(&models.FormQuestion{}).Error; err != nil {
+			return err
+		}
+		for i := range questions {
+			questions[i].ID, questions[i].SeasonID = 0, seasonId
+			if err := tx.connection.Create(&questions[i]).Error; err != nil {
+				return err
+			}
+		}
+		found = true
+		return nil
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	if questions == nil {
+		questions = make([]models.FormQuestion, 0)
+	}
+	return questions, nil
+}
+
+// CreateRegistration registers a player for a league in a season with their answers to the
+// season's form, keyed by question. Registration must be open, the player must be eligible for the
+// league, and they can only register for it once unless they withdrew. The registration waits for
+// a manager's approval when the league has a spot for it, and on the waitlist otherwise.
+func (s session) CreateRegistration(r *models.Registration, answers map[string]string) (*models.Registration, error) {
+	err := s.Transaction(func(tx session) error {
+		league, err := tx.lockLeague(r.LeagueID)
+		if err != nil {
+			return err
+		}
+		if err := registration.CheckLeague(r.SeasonID, league); err != nil {
+			return err
+		}
+
+		season := &models.Season{}
+		if err := tx.connection.Limit(1).Find(season, r.SeasonID).Error; err != nil {
+			return err
+		}
+		if err := registration.CheckWindow(*season, time.Now()); err != nil {
+			return err
+		}
+
+		var registered int64
+		err = tx.connection.Model(&models.Registration{}).
+			Where("league_id = ? AND user_id = ? AND status <> ?", r.LeagueID, r.UserID, models.RegistrationWithdrawn).
+			Count(&registered).Error
+		if err != nil {
+			return err
+		}
+		if registered > 0 {
+			return registration.ErrAlreadyRegistered
+		}
+		if err := tx.checkEligibility(r.LeagueID, r.UserID, 0); err != nil {
+			return err
+		}
+
+		form, err := tx.GetForm(r.SeasonID)
+		if err != nil {
+			return err
+		}
+		r.Questions, err = registration.CheckAnswers(form, answers)
+		if err != nil {
+			return err
+		}
+
+		held, err := tx.heldSpots(league.ID)
+		if err != nil {
+			return err
+		}
+		r.Status = registration.Admit(league.Capacity, held)
+		if err := tx.connection.Omit("User").Create(r).Error; err != nil {
+			return err
+		}
+		return tx.notify(r.UserID, models.REGISTRATION_UPDATE, registration.Notice(league.Name, "", r.Status))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReviewRegistration approves or rejects a registration waiting for a manager. Rejecting one frees
+// its spot for the next registration on the waitlist. It returns nil when the registration doesn't
+// exist.
+func (s session) ReviewRegistration(id, reviewerId uint, approve bool, note string) (*models.Registration, error) {
+	var reviewed *models.Registration
+	err := s.Transaction(func(tx session) error {
+		r, league, err := tx.lockRegistration(id)
+		if r == nil || err != nil {
+			return err
+		}
+		if err := registration.CheckReview(*r); err != nil {
+			return err
+		}
+
+		status := models.RegistrationRejected
+		if approve {
+			status = models.RegistrationApproved
+		}
+		now := time.Now()
+		r.ReviewedBy, r.ReviewedAt, r.ReviewNote = &reviewerId, &now, note
+		err = tx.connection.Model(r).Updates(map[string]any{
+			"reviewed_by": r.ReviewedBy,
+			"reviewed_at": r.ReviewedAt,
+			"review_note": r.ReviewNote,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.setRegistrationStatus(r, league, status); err != nil {
+			return err
+		}
+		reviewed = r
+		return tx.promoteWaitlist(league)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviewed, nil
+}
+
+// WithdrawRegistration withdraws a registration for its registrant or a manager, freeing its spot
+// for the next registration on the waitlist. It returns nil when the registration doesn't exist.
+func (s session) WithdrawRegistration(id uint, requester *models.KeyRecord) (*models.Registration, error) {
+	var withdrawn *models.Registration
+	err := s.Transaction(func(tx session) error {
+		r, league, err := tx.lockRegistration(id)
+		if r == nil || err != nil {
+			return err
+		}
+		if err := registration.CheckWithdraw(*r, requester.UserId, slices.Contains(requester.Roles, auth.Manager)); err != nil {
+			return err
+		}
+		if err := tx.setRegistrationStatus(r, league, models.RegistrationWithdrawn); err != nil {
+			return err
+		}
+		withdrawn = r
+		return tx.promoteWaitlist(league)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawn, nil
+}
+
+// SetLeagueCapacity sets the most registrations a league holds spots for, 0 for no limit. Raising
+// it promotes registrations off the waitlist; lowering it keeps the spots already held. It returns
+// nil when the league doesn't exist.
+func (s session) SetLeagueCapacity(leagueId uint, capacity int) (*models.League, error) {
+	if err := registration.CheckCapacity(capacity); err != nil {
+		return nil, err
+	}
+
+	var updated *models.League
+	err := s.Transaction(func(tx session) error {
+		league, err := tx.lockLeague(leagueId)
+		if league == nil || err != nil {
+			return err
+		}
+		league.Capacity = capacity
+		if err := tx.connection.Model(league).Update("capacity", capacity).Error; err != nil {
+			return err
+		}
+		updated = league
+		return tx.promoteWaitlist(league)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetRegistrationWindow sets when registration for a season opens and closes. A nil date falls
+// back to the season's default. It returns nil when the season doesn't exist.
+func (s session) SetRegistrationWindow(seasonId uint, opens, closes *time.Time) (*models.Season, error) {
+	if err := registration.CheckWindowDates(opens, closes); err != nil {
+		return nil, err
+	}
+	result := s.connection.Model(&models.Season{}).Where("id = ?", seasonId).Updates(map[string]any{
+		"registration_opens":  opens,
+		"registration_closes": closes,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	season := &models.Season{}
+	result = s.connection.First(season, seasonId)
+	return resultOrError(season, result)
+}
+
+// promoteWaitlist moves a league's waitlisted registrations, oldest first, into the approval queue
+// while the league has spots for them
+func (s session) promoteWaitlist(league *models.League) error {
+	held, err := s.heldSpots(league.ID)
+	if err != nil {
+		return err
+	}
+	var waiting int64
+	if err := s.connection.Model(&models.Registration{}).Where("league_id = ? AND status = ?", league.ID, models.RegistrationWaitlisted).Count(&waiting).Error; err != nil {
+		return err
+	}
+	openings := registration.Openings(league.Capacity, held, int(waiting))
+	if openings == 0 {
+		return nil
+	}
+
+	promoted := make([]models.Registration, 0, openings)
+	err = s.connection.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("league_id = ? AND status = ?", league.ID, models.RegistrationWaitlisted).
+		Order("created_at, id").Limit(openings).Find(&promoted).Error
+	if err != nil {
+		return err
+	}
+	for i := range promoted {
+		if err := s.setRegistrationStatus(&promoted[i], league, models.RegistrationPending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRegistrationStatus moves a registration to a status and tells the registrant in their inbox
+func (s session) setRegistrationStatus(r *models.Registration, league *models.League, status models.RegistrationStatus) error {
+	from := r.Status
+	r.Status = status
+	if err := s.connection.Model(r).Update("status", status).Error; err != nil {
+		return err
+	}
+	return s.notify(r.UserID, models.REGISTRATION_UPDATE, registration.Notice(league.Name, from, status))
+}
+
+// heldSpots counts the registrations holding spots in a league
+func (s session) heldSpots(leagueId uint) (int, error) {
+	var held int64
+	err := s.connection.Model(&models.Registration{}).
+		Where("league_id = ? AND status IN ?", leagueId, []models.RegistrationStatus{models.RegistrationPending, models.RegistrationApproved}).
+		Count(&held).Error
+	return int(held), err
+}
+
+// lockLeague loads a league and locks it, so the spots in it change one registration at a time.
+// It returns nil when the league doesn't exist.
+func (s session) lockLeague(id uint) (*models.League, error) {
+	league := &models.League{}
+	result := s.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(league, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return league, nil
+}
+
+// lockRegistration loads and locks a registration and its league, the league first to match the
+// order registrations are created in. It returns nil when the registration doesn't exist.
+func (s session) lockRegistration(id uint) (*models.Registration, *models.League, error) {
+	var leagueId uint
+	result := s.connection.Model(&models.Registration{}).Where("id = ?", id).Limit(1).Pluck("league_id", &leagueId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, nil, result.Error
+	}
+	league, err := s.lockLeague(leagueId)
+	if league == nil || err != nil {
+		return nil, nil, err
+	}
+
+	r := &models.Registration{}
+	result = s.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(r, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, nil, result.Error
+	}
+	return r, league, nil
+}
+
+// GetRegistrations lists the registrations matching the filter, oldest first so a league's queue
+// and waitlist read in turn, with each registrant and their answers
+func (s session) GetRegistrations(filter RegistrationFilter) ([]models.Registration, error) {
+	registrations := make([]models.Registration, 0)
+	query := s.connection.Preload("User").Preload("Questions", func(query *gorm.DB) *gorm.DB {
+		return query.Order("id")
+	})
+	if filter.SeasonID != 0 {
+		query = query.Where("season_id = ?", filter.SeasonID)
+	}
+	if filter.LeagueID != 0 {
+		query = query.Where("league_id = ?", filter.LeagueID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Key != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM questions q WHERE q.registration_id = registrations.id AND q.key = ? AND lower(q.answer) = lower(?))", filter.Key, filter.Answer)
+	}
+	result := query.Order("created_at, id").Find(&registrations)
+	return resultsOrError(registrations, result)
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/rollover"
+)
+
+// RollOverSeason creates a new season from an existing one, copying its leagues and teams in a
+// single transaction. A preview runs the whole rollover and then rolls it back. It returns nil
+// when the season doesn't exist.
+func (s session) RollOverSeason(fromId uint, options rollover.Options, preview bool) (*models.Rollover, error) {
+	from := &models.Season{}
+	result := s.connection.Preload("Leagues.Teams.Roster.Players").First(from, fromId)
+	from, err := resultOrError(from, result)
+	if from == nil || err != nil {
+		return nil, err
+	}
+
+	plan, err := rollover.Plan(*from, options)
+	if err != nil {
+		return nil, err
+	}
+	plan.Preview = preview
+
+	err = s.Transaction(func(tx session) error {
+		if err := tx.applyRollover(&plan); err != nil {
+			return err
+		}
+		if preview {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+
+	if preview {
+		plan.Season.ID = 0
+		for i := range plan.Leagues {
+			plan.Leagues[i].LeagueID = 0
+			for j := range plan.Leagues[i].Teams {
+				plan.Leagues[i].Teams[j].TeamID = 0
+				plan.Leagues[i].Teams[j].RosterID = 0
+			}
+		}
+	}
+	return &plan, nil
+}
+
+func (s session) applyRollover(plan *models.Rollover) error {
+	for id, correlationId := range plan.AssignedLeagueCorrelations {
+		if err := s.connection.Model(&models.League{}).Where("id = ?", id).Update("correlation_id", correlationId).Error; err != nil {
+			return err
+		}
+	}
+	for id, correlationId := range plan.AssignedTeamCorrelations {
+		if err := s.connection.Model(&models.Team{}).Where("id = ?", id).Update("correlation_id", correlationId).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := s.connection.Omit("Registrations", "Schedule", "Leagues").Create(&plan.Season).Error; err != nil {
+		return err
+	}
+
+	for i := range plan.Leagues {
+		copied := &plan.Leagues[i]
+		league := &models.League{
+			CorrelationId:    copied.CorrelationId,
+			SeasonID:         plan.Season.ID,
+			Name:             copied.Name,
+			PointSystem:      copied.PointSystem,
+			Tiebreakers:      copied.Tiebreakers,
+			RosterLimit:      copied.RosterLimit,
+			Capacity:         copied.Capacity,
+			EligibilityRules: copied.EligibilityRules,
+		}
+		if err := s.connection.Omit("Teams").Create(league).Error; err != nil {
+			return err
+		}
+		copied.LeagueID = league.ID
+
+		for j := range copied.Teams {
+			if err := s.copyTeam(league.ID, &copied.Teams[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyTeam creates a team and its roster in a new league, with the players of last season's
+// roster when they're carried forward. Players who break the new league's eligibility rules are
+// left off and reported as refused.
+func (s session) copyTeam(leagueId uint, copied *models.RolloverTeam) error {
+	roster, err := s.createRoster(copied.CaptainID)
+	if err != nil {
+		return err
+	}
+	copied.RosterID = roster.ID
+
+	team := &models.Team{
+		CorrelationId: copied.CorrelationId,
+		Name:          copied.Name,
+		LogoId:        copied.LogoId,
+		Color:         copied.Color,
+		LeagueID:      leagueId,
+		RosterID:      roster.ID,
+	}
+	if err := s.connection.Omit("League", "Roster").Create(team).Error; err != nil {
+		return err
+	}
+	copied.TeamID = team.ID
+	copied.Refused = make([]models.RolloverRefusal, 0)
+	if copied.FromRosterID == 0 {
+		return nil
+	}
+
+	players := make([]models.RosterPlayer, 0)
+	err = s.connection.Select("user_id, jersey_number, COALESCE(position, ?) AS position", models.Skater).
+		Where("roster_id = ?", copied.FromRosterID).Order("user_id").Find(&players).Error
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, player := range players {
+		err := s.checkEligibility(leagueId, player.UserID, team.ID)
+		if eligibility.IsRuleViolation(err) {
+			copied.Refused = append(copied.Refused, models.RolloverRefusal{UserID: player.UserID, Violations: eligibility.Violations(err)})
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		player.RosterID = roster.ID
+		if err := s.connection.Create(&player).Error; err != nil {
+			return err
+		}
+		if err := s.startMembership(team.ID, player.UserID, now, nil); err != nil {
+			return err
+		}
+	}
+
+	copied.Players -= len(copied.Refused)
+	for _, refused := range copied.Refused {
+		if refused.UserID == copied.CaptainID {
+			copied.CaptainID = 0
+			return s.setCaptain(roster.ID, refused.UserID, false)
+		}
+	}
+	return nil
+}
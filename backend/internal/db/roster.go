@@ -0,0 +1,177 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"gorm.io/gorm/clause"
+)
+
+// GetRosterPlayerIds returns the IDs of the players on a roster
+func (s session) GetRosterPlayerIds(rosterId uint) ([]uint, error) {
+	ids := make([]uint, 0)
+	result := s.connection.Table("player_rosters").Where("roster_id = ?", rosterId).Pluck("user_id", &ids)
+	return resultsOrError(ids, result)
+}
+
+// createRoster creates a roster without players. With no captain, captain_id is left null rather
+// than pointing at a user that doesn't exist.
+func (s session) createRoster(captainId uint) (*models.Roster, error) {
+	roster := &models.Roster{CaptainID: captainId}
+	omit := []string{"Players", "Captain"}
+	if captainId == 0 {
+		omit = append(omit, "CaptainID")
+	}
+	if err := s.connection.Omit(omit...).Create(roster).Error; err != nil {
+		return nil, err
+	}
+	return roster, nil
+}
+
+// GetRosterMembers lists the players on a roster by jersey number, then name
+func (s session) GetRosterMembers(rosterId uint) ([]models.RosterMember, error) {
+	members := make([]models.RosterMember, 0)
+	result := s.connection.Raw(`
+		SELECT u.id AS user_id, u.first_name, u.last_name, pr.jersey_number,
+			COALESCE(pr.position, ?) AS position, COALESCE(r.captain_id = u.id, false) AS captain
+		FROM player_rosters pr
+			JOIN users u ON u.id = pr.user_id
+			JOIN rosters r ON r.id = pr.roster_id
+		WHERE pr.roster_id = ?
+		ORDER BY pr.jersey_number NULLS LAST, u.last_name, u.first_name, u.id`, models.Skater, rosterId).Scan(&members)
+	return resultsOrError(members, result)
+}
+
+// AddRosterMember puts a player on a team's roster, checking the roster rules and the league's
+// eligibility rules. Players serving a suspension can't be added. The team's roster is returned,
+// or nil when the team doesn't exist.
+func (s session) AddRosterMember(teamId uint, member models.RosterMember) ([]models.RosterMember, error) {
+	return s.changeRoster(teamId, func(tx session, team *models.Team, players []models.RosterPlayer) error {
+		users, err := tx.GetUsersByIds([]uint{member.UserID})
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return roster.ErrUnknownPlayer
+		}
+
+		return tx.addToRoster(team, players, member)
+	})
+}
+
+// addToRoster puts a player on the roster of a team locked by changeRoster, checking the roster
+// rules and the league's eligibility rules, and starts their membership of the team. Players
+// serving a suspension can't be added.
+func (s session) addToRoster(team *models.Team, players []models.RosterPlayer, member models.RosterMember) error {
+	suspended, err := s.GetSuspendedPlayerIds(0, []uint{member.UserID})
+	if err != nil {
+		return err
+	}
+
+	player := models.RosterPlayer{RosterID: team.RosterID, UserID: member.UserID, JerseyNumber: member.JerseyNumber, Position: member.Position}
+	roster.Normalize(&player)
+	if err := roster.CheckAdd(players, player, roster.Limit(team.League), len(suspended) > 0); err != nil {
+		return err
+	}
+	if err := s.checkEligibility(team.LeagueID, member.UserID, team.ID); err != nil {
+		return err
+	}
+	if err := s.connection.Create(&player).Error; err != nil {
+		return err
+	}
+	if err := s.startMembership(team.ID, member.UserID, time.Now(), nil); err != nil {
+		return err
+	}
+	return s.setCaptain(team.RosterID, member.UserID, member.Captain)
+}
+
+// UpdateRosterMember changes a player's jersey number, position and whether they captain the team
+func (s session) UpdateRosterMember(teamId uint, member models.RosterMember) ([]models.RosterMember, error) {
+	return s.changeRoster(teamId, func(tx session, team *models.Team, players []models.RosterPlayer) error {
+		player := models.RosterPlayer{RosterID: team.RosterID, UserID: member.UserID, JerseyNumber: member.JerseyNumber, Position: member.Position}
+		roster.Normalize(&player)
+		if err := roster.CheckUpdate(players, player); err != nil {
+			return err
+		}
+
+		err := tx.connection.Model(&models.RosterPlayer{}).
+			Where("roster_id = ? AND user_id = ?", team.RosterID, member.UserID).
+			Updates(map[string]any{"jersey_number": player.JerseyNumber, "position": player.Position}).Error
+		if err != nil {
+			return err
+		}
+		return tx.setCaptain(team.RosterID, member.UserID, member.Captain)
+	})
+}
+
+// RemoveRosterMember takes a player off a team's roster. A captain who leaves stops being captain.
+func (s session) RemoveRosterMember(teamId, userId uint) ([]models.RosterMember, error) {
+	return s.changeRoster(teamId, func(tx session, team *models.Team, players []models.RosterPlayer) error {
+		result := tx.connection.Where("roster_id = ? AND user_id = ?", team.RosterID, userId).Delete(&models.RosterPlayer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return roster.ErrNotOnRoster
+		}
+		if err := tx.endMembership(team.ID, userId, time.Now()); err != nil {
+			return err
+		}
+		return tx.setCaptain(team.RosterID, userId, false)
+	})
+}
+
+// changeRoster runs fn with a team locked against other roster changes and returns the roster
+// after the change. Archived teams can't be changed.
+func (s session) changeRoster(teamId uint, fn func(tx session, team *models.Team, players []models.RosterPlayer) error) ([]models.RosterMember, error) {
+	var members []models.RosterMember
+	err := s.Transaction(func(tx session) error {
+		team := &models.Team{}
+		result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(team, teamId)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if team.ArchivedAt != nil {
+			return roster.ErrTeamArchived
+		}
+		if err := tx.connection.Limit(1).Find(&team.League, team.LeagueID).Error; err != nil {
+			return err
+		}
+		if team.RosterID == 0 {
+			created, err := tx.createRoster(0)
+			if err != nil {
+				return err
+			}
+			if err := tx.connection.Model(team).UpdateColumn("roster_id", created.ID).Error; err != nil {
+				return err
+			}
+			team.RosterID = created.ID
+		}
+
+		players := make([]models.RosterPlayer, 0)
+		if err := tx.connection.Where("roster_id = ?", team.RosterID).Find(&players).Error; err != nil {
+			return err
+		}
+		if err := fn(tx, team, players); err != nil {
+			return err
+		}
+
+		var err error
+		members, err = tx.GetRosterMembers(team.RosterID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// setCaptain makes a player the captain of a roster, or stops them being captain
+func (s session) setCaptain(rosterId, userId uint, captain bool) error {
+	query := s.connection.Model(&models.Roster{}).Where("id = ?", rosterId)
+	if captain {
+		return query.Update("captain_id", userId).Error
+	}
+	return query.Where("captain_id = ?", userId).Update("captain_id", nil).Error
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateRosterColumns(t *testing.T) {
+	conn := dryRun(t)
+	var inserts []string
+	err := conn.Callback().Create().After("gorm:create").Register("test:record", func(tx *gorm.DB) {
+		inserts = append(inserts, tx.Statement.SQL.String())
+	})
+	require.NoError(t, err)
+	s := session{connection: conn}
+
+	_, err = s.createRoster(0)
+	require.NoError(t, err)
+	require.Len(t, inserts, 1)
+	assert.Contains(t, inserts[0], `INSERT INTO "rosters"`)
+	assert.NotContains(t, inserts[0], "captain_id")
+
+	inserts = nil
+	_, err = s.createRoster(7)
+	require.NoError(t, err)
+	require.Len(t, inserts, 1)
+	assert.Contains(t, inserts[0], "captain_id")
+}
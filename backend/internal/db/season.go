@@ -8,6 +8,13 @@ func (s session) GetSeasons() ([]models.Season, error) {
 	return resultsOrError(seasons, err)
 }
 
+// GetSeason returns a season with its leagues and their teams, or nil when it doesn't exist
+func (s session) GetSeason(id uint) (*models.Season, error) {
+	season := &models.Season{}
+	result := s.connection.Preload("Leagues.Teams").First(season, id)
+	return resultOrError(season, result)
+}
+
 func (s session) SaveSeason(season *models.Season) (*models.Season, error) {
 	result := s.connection.Create(season)
 	return resultOrError(season, result)
@@ -0,0 +1,22 @@
+package seeders
+
+import (
+	"github.com/jak103/powerplay/internal/models"
+	"gorm.io/gorm"
+)
+
+type DisciplineRuleSeeder struct{}
+
+// Seed adds the default rules shared by every league
+func (dr DisciplineRuleSeeder) Seed(db *gorm.DB) error {
+	rules := []models.DisciplineRule{
+		{Severity: "match", Threshold: 1, Games: 2},
+		{Severity: "game_misconduct", Threshold: 3, Games: 1},
+	}
+	for _, rule := range rules {
+		if err := db.FirstOrCreate(&rule, models.DisciplineRule{Severity: rule.Severity, Threshold: rule.Threshold}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
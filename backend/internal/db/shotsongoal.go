@@ -1,8 +1,96 @@
 package db
 
-import "github.com/jak103/powerplay/internal/models"
+import (
+	"errors"
+
+	"github.com/jak103/powerplay/internal/models"
+	"gorm.io/gorm"
+)
 
 func (s session) SaveShotOnGoal(shotOnGoal *models.ShotOnGoal) (*models.ShotOnGoal, error) {
-	result := s.connection.Create(shotOnGoal)
-	return resultOrError(shotOnGoal, result)
-}
\ No newline at end of file
+	err := s.Transaction(func(tx session) error {
+		if err := tx.fillShotGoalie(shotOnGoal); err != nil {
+			return err
+		}
+		if err := tx.connection.Create(shotOnGoal).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(shotEvent, shotOnGoal.GameId, shotOnGoal.ID, models.EventCreated, nil, shotOnGoal); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(shotOnGoal.GameId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shotOnGoal, nil
+}
+
+func (s session) GetShotsOnGoal(filter EventFilter) ([]models.ShotOnGoal, error) {
+	shots := make([]models.ShotOnGoal, 0)
+	err := filter.scope(s.connection).Order("game_id, period, shot_time, id").Find(&shots)
+	return resultsOrError(shots, err)
+}
+
+func (s session) GetShotOnGoal(id uint) (*models.ShotOnGoal, error) {
+	shot := &models.ShotOnGoal{}
+	result := s.connection.First(shot, id)
+	return resultOrError(shot, result)
+}
+
+// UpdateShotOnGoal replaces a recorded shot, logs the amendment and refreshes the totals of any
+// game it touched. A nil shot is returned if no shot with that ID exists.
+func (s session) UpdateShotOnGoal(shotOnGoal *models.ShotOnGoal) (*models.ShotOnGoal, error) {
+	err := s.Transaction(func(tx session) error {
+		existing := &models.ShotOnGoal{}
+		if err := tx.connection.First(existing, shotOnGoal.ID).Error; err != nil {
+			return err
+		}
+		// The event can't be moved out of a game that was signed off either
+		if err := tx.checkUnlocked(existing.GameId); err != nil {
+			return err
+		}
+
+		shotOnGoal.CreatedAt = existing.CreatedAt
+		if err := tx.fillShotGoalie(shotOnGoal); err != nil {
+			return err
+		}
+		if err := tx.connection.Save(shotOnGoal).Error; err != nil {
+			return err
+		}
+		if err := tx.logAmendment(shotEvent, existing.GameId, shotOnGoal.GameId, shotOnGoal.ID, existing, shotOnGoal); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(existing.GameId, shotOnGoal.GameId)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return shotOnGoal, nil
+}
+
+// DeleteShotOnGoal voids a recorded shot, keeping a copy in the event log, and refreshes its
+// game's totals. The deleted shot is returned, or nil if no shot with that ID exists.
+func (s session) DeleteShotOnGoal(id uint) (*models.ShotOnGoal, error) {
+	shotOnGoal := &models.ShotOnGoal{}
+	err := s.Transaction(func(tx session) error {
+		if err := tx.connection.First(shotOnGoal, id).Error; err != nil {
+			return err
+		}
+		if err := tx.connection.Delete(shotOnGoal).Error; err != nil {
+			return err
+		}
+		if err := tx.logEvent(shotEvent, shotOnGoal.GameId, shotOnGoal.ID, models.EventVoided, shotOnGoal, nil); err != nil {
+			return err
+		}
+		return tx.syncGameTotals(shotOnGoal.GameId)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return shotOnGoal, nil
+}
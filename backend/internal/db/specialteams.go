@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/powerplay"
+)
+
+// SpecialTeamsFilter narrows special teams stats to a game or to the games in a season or league. Zero IDs mean no filter.
+type SpecialTeamsFilter struct {
+	GameID   uint
+	SeasonID uint
+	LeagueID uint
+}
+
+// GetSpecialTeamsGames loads the penalties and goals of every filtered game that has started
+func (s session) GetSpecialTeamsGames(filter SpecialTeamsFilter) ([]powerplay.Game, error) {
+	games := make([]models.Game, 0)
+	query := s.connection.Model(&models.Game{}).
+		Joins("JOIN teams home ON home.id = games.home_team_id").
+		Where("games.status <> ?", models.SCHEDULED)
+	if filter.GameID != 0 {
+		query = query.Where("games.id = ?", filter.GameID)
+	}
+	if filter.SeasonID != 0 {
+		query = query.Where("games.season_id = ?", filter.SeasonID)
+	}
+	if filter.LeagueID != 0 {
+		query = query.Where("home.league_id = ?", filter.LeagueID)
+	}
+	err := query.Order("games.start, games.id").Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.specialTeamsGames(games)
+}
+
+func (s session) specialTeamsGames(games []models.Game) ([]powerplay.Game, error) {
+	ids := make([]uint, 0, len(games))
+	for _, game := range games {
+		ids = append(ids, game.ID)
+	}
+
+	penalties := make([]models.Penalty, 0)
+	goals := make([]models.Goal, 0)
+	if len(ids) > 0 {
+		err := s.connection.Preload("PenaltyType").Where("game_id IN ?", ids).Find(&penalties).Error
+		if err != nil {
+			return nil, err
+		}
+		err = s.connection.Where("game_id IN ?", ids).Find(&goals).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byGame := make(map[uint]*powerplay.Game, len(games))
+	result := make([]powerplay.Game, len(games))
+	for i, game := range games {
+		result[i] = powerplay.Game{GameID: game.ID, HomeTeamID: game.HomeTeamID, AwayTeamID: game.AwayTeamID}
+		byGame[game.ID] = &result[i]
+	}
+	for _, penalty := range penalties {
+		byGame[penalty.GameID].Penalties = append(byGame[penalty.GameID].Penalties, penalty)
+	}
+	for _, goal := range goals {
+		byGame[goal.GameId].Goals = append(byGame[goal.GameId].Goals, goal)
+	}
+	return result, nil
+}
+
+// syncGoalStrengths stores the power play, short handed or even strength classification of every goal in a game
+func (s session) syncGoalStrengths(game *models.Game) error {
+	games, err := s.specialTeamsGames([]models.Game{*game})
+	if err != nil {
+		return err
+	}
+
+	// Derived columns aren't edits, so they leave updated_at alone
+	for _, goal := range powerplay.Analyze(games[0]).Goals {
+		err := s.connection.Model(&models.Goal{}).Where("id = ?", goal.GoalID).UpdateColumns(map[string]any{
+			"strength":            goal.Strength,
+			"player_differential": goal.PlayerDifferential,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/powerplay"
+	"github.com/jak103/powerplay/internal/server/services/scoring"
+)
+
+// PlayerStatsFilter narrows and orders a player leaderboard. Zero IDs mean no filter.
+type PlayerStatsFilter struct {
+	SeasonID   uint
+	LeagueID   uint
+	TeamID     uint
+	Sort       string // One of the keys of scoring.Sorts
+	Descending bool
+	MinGames   int // Leave out players who played fewer games
+	Limit      int
+	Offset     int
+
+	LeagueCorrelationID string // Every season of a league, for all-time leaders. Empty means no filter.
+}
+
+// playerStatsQuery aggregates goals, assists, penalty minutes and plus/minus for every player with an
+// event or an appearance in the filtered games. Games played counts games that have started
+// in which the player was in their team's lineup.
+const playerStatsQuery = `
+	WITH scoped_games AS (
+		SELECT g.id, g.status, g.home_team_id, g.away_team_id
+		FROM games g
+			JOIN teams home ON home.id = g.home_team_id
+		WHERE (@season = 0 OR g.season_id = @season)
+			AND (@league = 0 OR home.league_id = @league)
+			AND (@league_correlation = '' OR home.league_id IN (SELECT id FROM leagues WHERE correlation_id = @league_correlation))
+	),
+	appearances AS (
+		SELECT DISTINCT d.user_id, d.game_id, d.team_id
+		FROM (` + dressedSql + `) d JOIN scoped_games sg ON sg.id = d.game_id
+		WHERE sg.status <> @scheduled
+	),
+	events AS (
+		SELECT g.user_id AS player_id, g.team_id, 1 AS goals, 0 AS assists, 0 AS pim, 0 AS plus_minus
+		FROM goals g JOIN scoped_games sg ON sg.id = g.game_id
+		UNION ALL
+		SELECT g.assist1_id, g.team_id, 0, 1, 0, 0
+		FROM goals g JOIN scoped_games sg ON sg.id = g.game_id
+		WHERE g.assist1_id <> 0
+		UNION ALL
+		SELECT g.assist2_id, g.team_id, 0, 1, 0, 0
+		FROM goals g JOIN scoped_games sg ON sg.id = g.game_id
+		WHERE g.assist2_id <> 0
+		UNION ALL
+		SELECT p.player_id, p.team_id, 0, 0, pt.duration, 0
+		FROM penalties p
+			JOIN scoped_games sg ON sg.id = p.game_id
+			JOIN penalty_types pt ON pt.id = p.penalty_type_id
+		UNION ALL
+		-- Power play and penalty shot goals don't count towards plus/minus
+		SELECT oi.player_id, oi.team_id, 0, 0, 0, CASE WHEN oi.team_id = g.team_id THEN 1 ELSE -1 END
+		FROM goals_on_ice oi
+			JOIN goals g ON g.id = oi.goal_id
+			JOIN scoped_games sg ON sg.id = g.game_id
+		WHERE g.strength <> @powerplay AND NOT g.is_penalty_shot
+	),
+	played AS (
+		SELECT user_id, count(DISTINCT game_id) AS games_played
+		FROM appearances
+		WHERE @team = 0 OR team_id = @team
+		GROUP BY user_id
+	),
+	totals AS (
+		SELECT player_id, sum(goals) AS goals, sum(assists) AS assists, sum(pim) AS penalty_mins, sum(plus_minus) AS plus_minus
+		FROM events
+		WHERE @team = 0 OR team_id = @team
+		GROUP BY player_id
+	),
+	lines AS (
+		SELECT u.id AS player_id, u.first_name, u.last_name,
+			COALESCE(played.games_played, 0) AS games_played,
+			COALESCE(totals.goals, 0) AS goals,
+			COALESCE(totals.assists, 0) AS assists,
+			COALESCE(totals.goals, 0) + COALESCE(totals.assists, 0) AS points,
+			COALESCE(totals.penalty_mins, 0) AS penalty_mins,
+			COALESCE(totals.plus_minus, 0) AS plus_minus,
+			COALESCE((COALESCE(totals.goals, 0) + COALESCE(totals.assists, 0))::float / NULLIF(played.games_played, 0), 0) AS points_per_game
+		FROM users u
+			LEFT JOIN played ON played.user_id = u.id
+			LEFT JOIN totals ON totals.player_id = u.id
+		WHERE played.user_id IS NOT NULL OR totals.player_id IS NOT NULL
+	)
+	SELECT *, count(*) OVER () AS total
+	FROM lines
+	WHERE games_played >= @min_games
+	ORDER BY %s %s, points DESC, goals DESC, lower(last_name), player_id
+	LIMIT @limit OFFSET @offset`
+
+// GetPlayerStats returns one page of player stat lines and the total number of players matching the
+// filter. A limit that isn't positive returns every line from the offset.
+func (s session) GetPlayerStats(filter PlayerStatsFilter) ([]models.PlayerStatLine, int, error) {
+	column, ok := scoring.Sorts[filter.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", filter.Sort)
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	var limit any
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	type row struct {
+		models.PlayerStatLine
+		Total int
+	}
+
+	rows := make([]row, 0)
+	err := s.connection.Raw(fmt.Sprintf(playerStatsQuery, column, direction), map[string]any{
+		"season":             filter.SeasonID,
+		"league":             filter.LeagueID,
+		"team":               filter.TeamID,
+		"scheduled":          models.SCHEDULED,
+		"final":              models.FINAL,
+		"league_correlation": filter.LeagueCorrelationID,
+		"powerplay":          powerplay.PowerPlay,
+		"min_games":          filter.MinGames,
+		"limit":              limit,
+		"offset":             max(filter.Offset, 0),
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lines := make([]models.PlayerStatLine, 0, len(rows))
+	total := 0
+	for _, r := range rows {
+		lines = append(lines, r.PlayerStatLine)
+		total = r.Total
+	}
+	return lines, total, nil
+}
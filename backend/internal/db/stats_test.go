@@ -0,0 +1,41 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetPlayerStatsQuery(t *testing.T) {
+	conn := dryRun(t)
+	var sql string
+	var vars []any
+	err := conn.Callback().Row().After("gorm:row").Register("test:record", func(tx *gorm.DB) {
+		sql, vars = regexp.MustCompile(`\$\d+`).ReplaceAllString(tx.Statement.SQL.String(), "?"), tx.Statement.Vars
+	})
+	require.NoError(t, err)
+
+	// Scanning raw rows isn't supported in a dry run, but the query is still built
+	s := session{connection: conn.Session(&gorm.Session{Logger: logger.Discard})}
+	_, _, err = s.GetPlayerStats(PlayerStatsFilter{Sort: "last_name", MinGames: 5, Limit: 20, Offset: 40})
+	require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+	assert.Contains(t, sql, `
+	WHERE games_played >= ?
+	ORDER BY lower(last_name) ASC, points DESC, goals DESC, lower(last_name), player_id
+	LIMIT ? OFFSET ?`)
+	assert.Equal(t, []any{5, 20, 40}, vars[len(vars)-3:])
+
+	_, _, err = s.GetPlayerStats(PlayerStatsFilter{Sort: "points_per_game", Descending: true})
+	require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+	assert.Contains(t, sql, "ORDER BY points_per_game DESC,")
+	assert.Equal(t, []any{0, nil, 0}, vars[len(vars)-3:])
+
+	sql = ""
+	_, _, err = s.GetPlayerStats(PlayerStatsFilter{Sort: "points; DROP TABLE users"})
+	assert.EqualError(t, err, `unknown sort "points; DROP TABLE users"`)
+	assert.Empty(t, sql)
+}
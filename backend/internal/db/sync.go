@@ -0,0 +1,193 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/events"
+	"github.com/jak103/powerplay/internal/server/services/offline"
+)
+
+// GetSyncOperation returns the recorded operation with an op ID in a game, or nil if it hasn't been applied
+func (s session) GetSyncOperation(gameId uint, opId string) (*models.SyncOperation, error) {
+	op := &models.SyncOperation{}
+	result := s.connection.Where("game_id = ? AND op_id = ?", gameId, opId).First(op)
+	return resultOrError(op, result)
+}
+
+func (s session) SaveSyncOperation(op *models.SyncOperation) (*models.SyncOperation, error) {
+	result := s.connection.Create(op)
+	return resultOrError(op, result)
+}
+
+func (s session) GetGoalByClientId(gameId uint, clientId string) (*models.Goal, error) {
+	goal := &models.Goal{}
+	result := s.connection.Preload("OnIce").Where("game_id = ? AND client_id = ?", gameId, clientId).First(goal)
+	return resultOrError(goal, result)
+}
+
+func (s session) GetPenaltyByClientId(gameId uint, clientId string) (*models.Penalty, error) {
+	penalty := &models.Penalty{}
+	result := s.connection.Preload("PenaltyType").Where("game_id = ? AND client_id = ?", gameId, clientId).First(penalty)
+	return resultOrError(penalty, result)
+}
+
+func (s session) GetShotOnGoalByClientId(gameId uint, clientId string) (*models.ShotOnGoal, error) {
+	shot := &models.ShotOnGoal{}
+	result := s.connection.Where("game_id = ? AND client_id = ?", gameId, clientId).First(shot)
+	return resultOrError(shot, result)
+}
+
+// UpdateGameStatus moves a game to a new status only if it still has the status the caller
+// expects and its sheet hasn't been signed off. It reports whether the game was changed.
+func (s session) UpdateGameStatus(gameId uint, from, to models.Status) (bool, error) {
+	result := s.connection.Model(&models.Game{}).
+		Where("id = ? AND status = ? AND signed_off_at IS NULL", gameId, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ApplySyncOperation applies a checked operation from a scorekeeper's device to a game. An applied
+// operation is recorded in the same transaction as its change, so a retry can't apply it twice.
+// The game's status is updated when the operation changes it.
+func (s session) ApplySyncOperation(op offline.Operation, game *events.Game, userId uint) (offline.Result, error) {
+	var result offline.Result
+	err := s.Transaction(func(tx session) error {
+		var err error
+		result, err = tx.WithReason(op.Reason).applySyncOperation(op, game)
+		if err != nil || result.Outcome != offline.Applied {
+			return err
+		}
+		_, err = tx.SaveSyncOperation(&models.SyncOperation{
+			OpID:    op.OpID,
+			GameID:  game.Game.ID,
+			Kind:    string(op.Kind),
+			Action:  string(op.Action),
+			EventID: result.EventID,
+			UserID:  userId,
+		})
+		return err
+	})
+	return result, err
+}
+
+func (s session) applySyncOperation(op offline.Operation, game *events.Game) (offline.Result, error) {
+	switch op.Kind {
+	case offline.Goal:
+		return offline.ApplyEvent(op, *game, offline.EventKind[models.Goal]{
+			Get:         s.GetGoal,
+			GetByClient: s.GetGoalByClientId,
+			Validate: func(goal *models.Goal, game events.Game) (events.Errors, error) {
+				return events.ValidateGoal(goal, game), nil
+			},
+			Create: func(goal *models.Goal) error {
+				_, err := s.SaveGoal(goal)
+				return err
+			},
+			Update: s.UpdateGoal,
+			Delete: s.DeleteGoal,
+			Identify: func(goal *models.Goal, id, gameId uint, clientId string) {
+				goal.ID, goal.GameId, goal.ClientID = id, gameId, clientId
+			},
+			Describe: func(goal *models.Goal) (uint, uint, string, time.Time) {
+				return goal.ID, goal.GameId, goal.ClientID, goal.UpdatedAt
+			},
+		})
+
+	case offline.Penalty:
+		return offline.ApplyEvent(op, *game, offline.EventKind[models.Penalty]{
+			Get:         s.GetPenalty,
+			GetByClient: s.GetPenaltyByClientId,
+			Validate: func(penalty *models.Penalty, game events.Game) (events.Errors, error) {
+				penaltyType, err := s.GetPenaltyType(penalty.PenaltyTypeID)
+				if err != nil {
+					return nil, err
+				}
+				return events.ValidatePenalty(penalty, penaltyType, game), nil
+			},
+			Create: func(penalty *models.Penalty) error {
+				penalty.PenaltyType = models.PenaltyType{}
+				return s.CreatePenalty(penalty)
+			},
+			Update: s.UpdatePenalty,
+			Delete: s.DeletePenalty,
+			Identify: func(penalty *models.Penalty, id, gameId uint, clientId string) {
+				penalty.ID, penalty.GameID, penalty.ClientID = id, gameId, clientId
+			},
+			Describe: func(penalty *models.Penalty) (uint, uint, string, time.Time) {
+				return penalty.ID, penalty.GameID, penalty.ClientID, penalty.UpdatedAt
+			},
+		})
+
+	case offline.Shot:
+		return offline.ApplyEvent(op, *game, offline.EventKind[models.ShotOnGoal]{
+			Get:         s.GetShotOnGoal,
+			GetByClient: s.GetShotOnGoalByClientId,
+			Validate: func(shot *models.ShotOnGoal, game events.Game) (events.Errors, error) {
+				return events.ValidateShot(shot, game), nil
+			},
+			Create: func(shot *models.ShotOnGoal) error {
+				_, err := s.SaveShotOnGoal(shot)
+				return err
+			},
+			Update: s.UpdateShotOnGoal,
+			Delete: s.DeleteShotOnGoal,
+			Identify: func(shot *models.ShotOnGoal, id, gameId uint, clientId string) {
+				shot.ID, shot.GameId, shot.ClientID = id, gameId, clientId
+			},
+			Describe: func(shot *models.ShotOnGoal) (uint, uint, string, time.Time) {
+				return shot.ID, shot.GameId, shot.ClientID, shot.UpdatedAt
+			},
+		})
+
+	case offline.Status:
+		result := offline.Result{OpID: op.OpID, Kind: op.Kind, EventID: game.Game.ID}
+		changed, err := s.UpdateGameStatus(game.Game.ID, op.ExpectedStatus, op.Status)
+		if err != nil {
+			return result, err
+		}
+		if !changed {
+			current, err := s.GetGame(game.Game.ID)
+			if err != nil {
+				return result, err
+			}
+			if current == nil {
+				result.Outcome = offline.NotFound
+				result.Message = fmt.Sprintf("Game %v does not exist", game.Game.ID)
+				return result, nil
+			}
+			result.Outcome = offline.Conflict
+			result.Message = fmt.Sprintf("The game is %v, not %v", current.Status, op.ExpectedStatus)
+			result.Current = current
+			return result, nil
+		}
+		game.Game.Status = op.Status
+		result.Outcome = offline.Applied
+		return result, nil
+	}
+
+	return offline.Result{OpID: op.OpID, Kind: op.Kind, Outcome: offline.Invalid, Message: "Unknown kind"}, nil
+}
+
+// GetGameState loads everything a scorekeeper's device keeps about a game. The game is nil when it
+// doesn't exist.
+func (s session) GetGameState(gameId uint) (offline.State, error) {
+	state := offline.State{}
+
+	game, err := s.GetGame(gameId)
+	if err != nil || game == nil {
+		return state, err
+	}
+	state.Game = game
+
+	filter := EventFilter{GameID: gameId}
+	if state.Goals, err = s.GetGoals(filter); err != nil {
+		return state, err
+	}
+	if state.Penalties, err = s.GetPenalties(filter); err != nil {
+		return state, err
+	}
+	state.Shots, err = s.GetShotsOnGoal(filter)
+	return state, err
+}
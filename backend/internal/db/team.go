@@ -0,0 +1,93 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// UpdateTeamRecords stores the win/loss record of each team. Only the record columns are written.
+func (s session) UpdateTeamRecords(teams []models.Team) error {
+	return s.Transaction(func(tx session) error {
+		for _, team := range teams {
+			err := tx.connection.Model(&models.Team{}).Where("id = ?", team.ID).Updates(map[string]any{
+				"wins":            team.Wins,
+				"losses":          team.Losses,
+				"ties":            team.Ties,
+				"overtime_losses": team.OvertimeLosses,
+				"goals_for":       team.GoalsFor,
+				"goals_against":   team.GoalsAgainst,
+				"points":          team.Points,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s session) GetTeamsByIds(ids []uint) ([]models.Team, error) {
+	teams := make([]models.Team, 0)
+	if len(ids) == 0 {
+		return teams, nil
+	}
+	result := s.connection.Where("id IN ?", ids).Find(&teams)
+	return resultsOrError(teams, result)
+}
+
+// GetTeam returns a team with its league, or nil when it doesn't exist
+func (s session) GetTeam(id uint) (*models.Team, error) {
+	team := &models.Team{}
+	result := s.connection.Preload("League").First(team, id)
+	return resultOrError(team, result)
+}
+
+// GetLeagueTeams returns a league's teams by name. Archived teams are left out unless asked for.
+func (s session) GetLeagueTeams(leagueId uint, includeArchived bool) ([]models.Team, error) {
+	teams := make([]models.Team, 0)
+	query := s.connection.Where("league_id = ?", leagueId)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	result := query.Order("name, id").Find(&teams)
+	return resultsOrError(teams, result)
+}
+
+// CreateTeam creates a team with an empty roster. A team without a correlation ID starts a new
+// franchise.
+func (s session) CreateTeam(team *models.Team) (*models.Team, error) {
+	if team.CorrelationId == "" {
+		team.CorrelationId = models.NewCorrelationId()
+	}
+
+	err := s.Transaction(func(tx session) error {
+		roster, err := tx.createRoster(0)
+		if err != nil {
+			return err
+		}
+		team.RosterID = roster.ID
+		return tx.connection.Omit("League", "Roster").Create(team).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// UpdateTeam changes a team's name, color and logo
+func (s session) UpdateTeam(team *models.Team) error {
+	return s.connection.Model(&models.Team{}).Where("id = ?", team.ID).Updates(map[string]any{
+		"name":    team.Name,
+		"color":   team.Color,
+		"logo_id": team.LogoId,
+	}).Error
+}
+
+// ArchiveTeam marks a team archived, keeping it and its roster for history. Archiving an archived
+// team leaves it as it was.
+func (s session) ArchiveTeam(id uint) error {
+	return s.connection.Model(&models.Team{}).
+		Where("id = ? AND archived_at IS NULL", id).
+		Update("archived_at", time.Now()).Error
+}
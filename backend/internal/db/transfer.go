@@ -0,0 +1,335 @@
+package db
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"github.com/jak103/powerplay/internal/server/services/transfer"
+	"github.com/jak103/powerplay/internal/utils/log"
+	"gorm.io/gorm/clause"
+)
+
+// TransferFilter narrows a list of transfers. Zero values mean no filter; a team filter matches
+// transfers to or from the team.
+type TransferFilter struct {
+	Status   models.TransferStatus
+	SeasonID uint
+	TeamID   uint
+	UserID   uint
+}
+
+// GetTransfers lists the transfers matching the filter, newest first
+func (s session) GetTransfers(filter TransferFilter) ([]models.Transfer, error) {
+	transfers := make([]models.Transfer, 0)
+	query := s.connection.Model(&models.Transfer{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.TeamID != 0 {
+		query = query.Where("from_team_id = ? OR to_team_id = ?", filter.TeamID, filter.TeamID)
+	}
+	if filter.SeasonID != 0 {
+		query = query.Where("from_team_id IN (SELECT t.id FROM teams t JOIN leagues l ON l.id = t.league_id WHERE l.season_id = ?)", filter.SeasonID)
+	}
+	result := query.Order("created_at DESC, id DESC").Find(&transfers)
+	return resultsOrError(transfers, result)
+}
+
+// GetTransfer returns a transfer, or nil when it doesn't exist
+func (s session) GetTransfer(id uint) (*models.Transfer, error) {
+	t := &models.Transfer{}
+	result := s.connection.First(t, id)
+	return resultOrError(t, result)
+}
+
+// RequestTransfer asks for a player to move between two teams of a season. The player, a captain
+// of either team or a manager can ask. The transfer waits for a manager's approval.
+func (s session) RequestTransfer(t *models.Transfer, requester *models.KeyRecord) (*models.Transfer, error) {
+	if t.EffectiveAt.IsZero() {
+		t.EffectiveAt = time.Now()
+	}
+
+	err := s.Transaction(func(tx session) error {
+		from, to, season, err := tx.transferTeams(t)
+		if err != nil {
+			return err
+		}
+
+		if requester.UserId != t.UserID && !slices.Contains(requester.Roles, auth.Manager) {
+			captains := make([]uint, 0)
+			err := tx.connection.Model(&models.Roster{}).Where("id IN ? AND captain_id IS NOT NULL", []uint{from.RosterID, to.RosterID}).Pluck("captain_id", &captains).Error
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(captains, requester.UserId) {
+				return transfer.ErrNotAllowed
+			}
+		}
+
+		players, err := tx.GetRosterPlayerIds(from.RosterID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(players, t.UserID) {
+			return transfer.ErrNotOnTeam
+		}
+		if err := transfer.CheckDates(*season, t.EffectiveAt); err != nil {
+			return err
+		}
+
+		var open int64
+		err = tx.connection.Model(&models.Transfer{}).
+			Where("user_id = ? AND status IN ?", t.UserID, []models.TransferStatus{models.TransferPending, models.TransferApproved}).
+			Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return transfer.ErrAlreadyRequested
+		}
+
+		t.Status = models.TransferPending
+		t.RequestedBy = requester.UserId
+		return tx.connection.Create(t).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ReviewTransfer approves or rejects a pending transfer. A transfer can only be approved when the
+// player is eligible for the new team's league. An approved transfer whose effective date has come
+// moves the player straight away; later ones wait for ApplyDueTransfers. It returns nil when the
+// transfer doesn't exist.
+func (s session) ReviewTransfer(id, reviewerId uint, approve bool, note string) (*models.Transfer, error) {
+	var reviewed *models.Transfer
+	err := s.Transaction(func(tx session) error {
+		t := &models.Transfer{}
+		result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(t, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if t.Status != models.TransferPending {
+			return transfer.ErrNotPending
+		}
+
+		t.Status = models.TransferRejected
+		if approve {
+			from, to, season, err := tx.transferTeams(t)
+			if err != nil {
+				return err
+			}
+			if err := transfer.CheckDates(*season, t.EffectiveAt); err != nil {
+				return err
+			}
+
+			// The player's jersey number is dropped if it's taken, so only the roster's size matters
+			players := make([]models.RosterPlayer, 0)
+			if err := tx.connection.Where("roster_id = ?", to.RosterID).Find(&players).Error; err != nil {
+				return err
+			}
+			joining := models.RosterPlayer{RosterID: to.RosterID, UserID: t.UserID, Position: models.Skater}
+			// A suspension is served with the team it was handed out on, so it doesn't block a move
+			if err := roster.CheckAdd(players, joining, roster.Limit(to.League), false); err != nil {
+				return err
+			}
+			// The player leaves their old team, so it doesn't count against the one team rule
+			if err := tx.checkEligibility(to.LeagueID, t.UserID, from.ID); err != nil {
+				return err
+			}
+			t.Status = models.TransferApproved
+		}
+
+		now := time.Now()
+		t.ReviewedBy, t.ReviewedAt, t.ReviewNote = &reviewerId, &now, note
+		err := tx.connection.Model(t).Updates(map[string]any{
+			"status":      t.Status,
+			"reviewed_by": t.ReviewedBy,
+			"reviewed_at": t.ReviewedAt,
+			"review_note": t.ReviewNote,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if transfer.Due(*t, now) {
+			if err := tx.applyTransfer(t); err != nil {
+				return err
+			}
+		}
+		reviewed = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviewed, nil
+}
+
+// ApplyDueTransfers moves the players of approved transfers whose effective dates have come, each
+// in its own transaction. Transfers that fail are logged and skipped. It returns the transfers it
+// completed.
+func (s session) ApplyDueTransfers() ([]models.Transfer, error) {
+	due := make([]models.Transfer, 0)
+	err := s.connection.Where("status = ? AND effective_at <= ?", models.TransferApproved, time.Now()).Order("effective_at, id").Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	completed := make([]models.Transfer, 0, len(due))
+	for _, t := range due {
+		err := s.Transaction(func(tx session) error {
+			result := tx.connection.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&t, t.ID)
+			if result.Error != nil || result.RowsAffected == 0 || !transfer.Due(t, time.Now()) {
+				return result.Error
+			}
+			if err := tx.applyTransfer(&t); err != nil {
+				return err
+			}
+			completed = append(completed, t)
+			return nil
+		})
+		if err != nil {
+			// One transfer that can't be applied, such as to a roster that filled up, mustn't hold
+			// up the rest. It stays approved and is tried again next time.
+			log.WithErr(err).Alert("Failed to apply transfer %v", t.ID)
+		}
+	}
+	return completed, nil
+}
+
+// applyTransfer moves a player between rosters and dates the change in their membership history.
+// The player keeps their position, and their jersey number unless it's taken on the new team.
+// The new team's roster limit is checked again, as it may have filled up since the approval.
+func (s session) applyTransfer(t *models.Transfer) error {
+	from, to := &models.Team{}, &models.Team{}
+	if err := s.connection.Limit(1).Find(from, t.FromTeamID).Error; err != nil {
+		return err
+	}
+	if err := s.connection.Limit(1).Find(to, t.ToTeamID).Error; err != nil {
+		return err
+	}
+	if err := s.connection.Limit(1).Find(&to.League, to.LeagueID).Error; err != nil {
+		return err
+	}
+
+	leaving := models.RosterPlayer{}
+	result := s.connection.Where("roster_id = ? AND user_id = ?", from.RosterID, t.UserID).Limit(1).Find(&leaving)
+	if result.Error != nil {
+		return result.Error
+	}
+	if err := s.connection.Where("roster_id = ? AND user_id = ?", from.RosterID, t.UserID).Delete(&models.RosterPlayer{}).Error; err != nil {
+		return err
+	}
+	if err := s.setCaptain(from.RosterID, t.UserID, false); err != nil {
+		return err
+	}
+
+	joining := models.RosterPlayer{RosterID: to.RosterID, UserID: t.UserID, JerseyNumber: leaving.JerseyNumber, Position: leaving.Position}
+	roster.Normalize(&joining)
+	if joining.JerseyNumber != nil {
+		var taken int64
+		err := s.connection.Model(&models.RosterPlayer{}).Where("roster_id = ? AND jersey_number = ?", to.RosterID, *joining.JerseyNumber).Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			joining.JerseyNumber = nil
+		}
+	}
+	// The new roster may have filled up since the transfer was approved
+	players := make([]models.RosterPlayer, 0)
+	if err := s.connection.Where("roster_id = ?", to.RosterID).Find(&players).Error; err != nil {
+		return err
+	}
+	err := roster.CheckAdd(players, joining, roster.Limit(to.League), false)
+	if err != nil && !errors.Is(err, roster.ErrAlreadyOnRoster) {
+		return err
+	}
+	if err == nil {
+		if err := s.connection.Create(&joining).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := s.endMembership(from.ID, t.UserID, t.EffectiveAt); err != nil {
+		return err
+	}
+	if err := s.startMembership(to.ID, t.UserID, t.EffectiveAt, &t.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t.Status, t.CompletedAt = models.TransferCompleted, &now
+	return s.connection.Model(t).Updates(map[string]any{"status": t.Status, "completed_at": t.CompletedAt}).Error
+}
+
+// transferTeams loads the teams and season of a transfer and checks the player can move between them
+func (s session) transferTeams(t *models.Transfer) (*models.Team, *models.Team, *models.Season, error) {
+	from, err := s.GetTeam(t.FromTeamID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	to, err := s.GetTeam(t.ToTeamID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if from == nil || to == nil {
+		return nil, nil, nil, transfer.ErrUnknownTeam
+	}
+	if err := transfer.CheckTeams(*from, *to); err != nil {
+		return nil, nil, nil, err
+	}
+
+	season := &models.Season{}
+	if err := s.connection.Limit(1).Find(season, from.League.SeasonID).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	return from, to, season, nil
+}
+
+// SetTradeDeadline sets the last moment transfers in a season can take effect, or clears it. It
+// returns nil when the season doesn't exist.
+func (s session) SetTradeDeadline(seasonId uint, deadline *time.Time) (*models.Season, error) {
+	result := s.connection.Model(&models.Season{}).Where("id = ?", seasonId).Update("trade_deadline", deadline)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	season := &models.Season{}
+	result = s.connection.First(season, seasonId)
+	return resultOrError(season, result)
+}
+
+// GetMemberships lists the teams a player has been rostered on, oldest first
+func (s session) GetMemberships(userId uint) ([]models.MembershipHistory, error) {
+	history := make([]models.MembershipHistory, 0)
+	result := s.connection.Raw(`
+		SELECT m.team_id, t.name AS team_name, t.league_id, COALESCE(l.name, '') AS league_name,
+			COALESCE(l.season_id, 0) AS season_id, m.started_at, m.ended_at, m.transfer_id
+		FROM team_memberships m
+			JOIN teams t ON t.id = m.team_id
+			LEFT JOIN leagues l ON l.id = t.league_id
+		WHERE m.user_id = ?
+		ORDER BY m.started_at NULLS FIRST, m.id`, userId).Scan(&history)
+	return resultsOrError(history, result)
+}
+
+// startMembership opens a spell of a player on a team from the given time
+func (s session) startMembership(teamId, userId uint, at time.Time, transferId *uint) error {
+	return s.connection.Create(&models.TeamMembership{UserID: userId, TeamID: teamId, StartedAt: &at, TransferID: transferId}).Error
+}
+
+// endMembership closes a player's open spell on a team at the given time
+func (s session) endMembership(teamId, userId uint, at time.Time) error {
+	return s.connection.Model(&models.TeamMembership{}).
+		Where("team_id = ? AND user_id = ? AND ended_at IS NULL", teamId, userId).
+		Update("ended_at", at).Error
+}
@@ -0,0 +1,12 @@
+package db
+
+import "github.com/jak103/powerplay/internal/models"
+
+func (s session) GetUsersByIds(ids []uint) ([]models.User, error) {
+	users := make([]models.User, 0)
+	if len(ids) == 0 {
+		return users, nil
+	}
+	result := s.connection.Where("id IN ?", ids).Find(&users)
+	return resultsOrError(users, result)
+}
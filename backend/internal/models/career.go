@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// CareerSeason is a player's scoring line for one team in one season. A player traded mid season
+// has a line for each team.
+type CareerSeason struct {
+	SeasonID            uint      `json:"season_id"`
+	SeasonName          string    `json:"season_name"`
+	SeasonStart         time.Time `json:"season_start"`
+	LeagueID            uint      `json:"league_id"`
+	LeagueName          string    `json:"league_name"`
+	LeagueCorrelationId string    `json:"league_correlation_id"`
+	TeamID              uint      `json:"team_id"`
+	TeamName            string    `json:"team_name"`
+	TeamCorrelationId   string    `json:"team_correlation_id"`
+	GamesPlayed         int       `json:"games_played"`
+	Goals               int       `json:"goals"`
+	Assists             int       `json:"assists"`
+	Points              int       `json:"points"`
+	PenaltyMins         int       `json:"penalty_minutes"`
+	PlusMinus           int       `json:"plus_minus"`
+	PointsPerGame       float64   `json:"points_per_game"`
+}
+
+// PlayerCareer is a player's career line: each season they played and their totals over all of them
+type PlayerCareer struct {
+	Totals  PlayerStatLine `json:"totals"`
+	Seasons []CareerSeason `json:"seasons"`
+}
+
+// FranchiseSeason is one season of a franchise, the teams that share a correlation ID across
+// seasons, with the name, colors, record and captain it had that season
+type FranchiseSeason struct {
+	SeasonID       uint      `json:"season_id"`
+	SeasonName     string    `json:"season_name"`
+	SeasonStart    time.Time `json:"season_start"`
+	LeagueID       uint      `json:"league_id"`
+	LeagueName     string    `json:"league_name"`
+	TeamID         uint      `json:"team_id"`
+	Name           string    `json:"name"`
+	Color          string    `json:"color"`
+	LogoId         string    `json:"logo_id"`
+	Wins           int       `json:"wins"`
+	Losses         int       `json:"losses"`
+	Ties           int       `json:"ties"`
+	OvertimeLosses int       `json:"overtime_losses"`
+	GoalsFor       int       `json:"goals_for"`
+	GoalsAgainst   int       `json:"goals_against"`
+	Points         int       `json:"points"`
+	CaptainID      *uint     `json:"captain_id"`
+	CaptainName    string    `json:"captain_name"`
+}
+
+// FranchiseRecord is a franchise's record summed over every season it played
+type FranchiseRecord struct {
+	Seasons        int `json:"seasons"`
+	Wins           int `json:"wins"`
+	Losses         int `json:"losses"`
+	Ties           int `json:"ties"`
+	OvertimeLosses int `json:"overtime_losses"`
+	GoalsFor       int `json:"goals_for"`
+	GoalsAgainst   int `json:"goals_against"`
+	Points         int `json:"points"`
+}
+
+// Franchise is the history of a team across seasons, oldest season first
+type Franchise struct {
+	CorrelationId string            `json:"correlation_id"`
+	Seasons       []FranchiseSeason `json:"seasons"`
+	Totals        FranchiseRecord   `json:"totals"`
+}
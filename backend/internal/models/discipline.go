@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DisciplineRule suspends a player once they've taken Threshold penalties of a severity in a
+// season. Rules with no league apply to every league that has no rule of its own for that severity.
+type DisciplineRule struct {
+	DbModel
+	LeagueID  uint   `json:"league_id"`
+	Severity  string `json:"severity"` // Matches PenaltyType.Severity
+	Threshold int    `json:"threshold"`
+	Games     int    `json:"games"`
+}
+
+// Suspension keeps a player out of their team's next Games games after StartsAt
+type Suspension struct {
+	DbModel
+	PlayerID  uint      `json:"player_id"`
+	TeamID    uint      `json:"team_id"`
+	SeasonID  uint      `json:"season_id"`
+	PenaltyID *uint     `json:"penalty_id"`
+	RuleID    *uint     `json:"rule_id"`
+	Games     int       `json:"games"`
+	StartsAt  time.Time `json:"starts_at"` // Only games after this count towards serving the suspension
+	Reason    string    `json:"reason"`
+
+	GamesServed int `json:"games_served" gorm:"->;-:migration"` // Final games the team has played since StartsAt
+
+	OverriddenBy   *uint      `json:"overridden_by"`
+	OverriddenAt   *time.Time `json:"overridden_at"`
+	OverrideReason string     `json:"override_reason"`
+}
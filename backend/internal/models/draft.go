@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Draft is a proposed split of a league's registrants into new teams. Managers move players
+// between its teams until they commit it, which creates the teams and their rosters.
+type Draft struct {
+	DbModel
+	SeasonID    uint           `json:"season_id"`
+	LeagueID    uint           `json:"league_id" gorm:"index"`
+	TeamNames   pq.StringArray `json:"team_names" gorm:"type:text[]"`
+	CommittedAt *time.Time     `json:"committed_at"`
+	Picks       []DraftPick    `json:"picks"`
+
+	Teams []DraftTeam       `json:"teams" gorm:"-"` // How each team shapes up
+	Apart []PlayWithRequest `json:"apart" gorm:"-"` // Play with requests the draft doesn't honor
+}
+
+// DraftPick puts a registrant on one of a draft's teams. Their skill level, position and play
+// with requests are kept as they were when the draft was made.
+type DraftPick struct {
+	DraftID    uint          `json:"-" gorm:"primaryKey"`
+	UserID     uint          `json:"user_id" gorm:"primaryKey"`
+	Team       int           `json:"team"` // Index into the draft's team names
+	SkillLevel int           `json:"skill_level"`
+	Position   Position      `json:"position" gorm:"default:skater"`
+	PlayWith   pq.Int64Array `json:"play_with" gorm:"type:bigint[]"`
+}
+
+// DraftTeam sums up one of a draft's teams
+type DraftTeam struct {
+	Name         string  `json:"name"`
+	Players      int     `json:"players"`
+	Goalies      int     `json:"goalies"`
+	SkillTotal   int     `json:"skill_total"`
+	SkillAverage float64 `json:"skill_average"`
+}
+
+// PlayWithRequest is a player's request to be on the same team as another
+type PlayWithRequest struct {
+	UserID     uint `json:"user_id"`
+	WithUserID uint `json:"with_user_id"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// EligibilityRule names one of a league's eligibility rules
+type EligibilityRule string
+
+const (
+	MinAgeRule        EligibilityRule = "min_age"
+	MaxSkillLevelRule EligibilityRule = "max_skill_level"
+	OneTeamRule       EligibilityRule = "one_team"
+)
+
+// EligibilityRules limit who can play in a league. They're checked when a player registers for
+// the league, joins a roster in it or dresses for one of its games. Zero values mean no limit.
+type EligibilityRules struct {
+	MinAge        int  `json:"min_age"`         // Age players must have reached when the season starts
+	MaxSkillLevel int  `json:"max_skill_level"` // Keeps stronger players out of lower divisions
+	OneTeam       bool `json:"one_team"`        // Players can only be rostered on one team in the league
+}
+
+// EligibilityException is a manager's approval for a player to play in a league despite one of
+// its rules
+type EligibilityException struct {
+	DbModel
+	LeagueID   uint            `json:"league_id" gorm:"uniqueIndex:idx_eligibility_exceptions"`
+	UserID     uint            `json:"user_id" gorm:"uniqueIndex:idx_eligibility_exceptions"`
+	Rule       EligibilityRule `json:"rule" gorm:"uniqueIndex:idx_eligibility_exceptions"`
+	Reason     string          `json:"reason"`
+	ApprovedBy uint            `json:"approved_by"`
+}
+
+// EligibilityViolation is a rule a player breaks, with why
+type EligibilityViolation struct {
+	Rule    EligibilityRule `json:"rule"`
+	Message string          `json:"message"`
+}
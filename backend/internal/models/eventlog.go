@@ -0,0 +1,28 @@
+package models
+
+import "encoding/json"
+
+type EventLogAction string
+
+const (
+	EventCreated EventLogAction = "create"
+	EventAmended EventLogAction = "amend"
+	EventVoided  EventLogAction = "void"
+	EventUndone  EventLogAction = "undo"
+	EventRedone  EventLogAction = "redo"
+)
+
+// EventLogEntry is one change to a goal, penalty or shot on goal. Entries are only ever added, so
+// a game's log is the full history of its scoresheet. Before and After are snapshots of the event.
+type EventLogEntry struct {
+	DbModel
+	GameID  uint            `json:"game_id" gorm:"index"`
+	Kind    string          `json:"kind"` // goal, penalty, shot or goalie_change
+	EventID uint            `json:"event_id"`
+	Action  EventLogAction  `json:"action"`
+	Reverts *uint           `json:"reverts"` // The entry an undo or redo applies to
+	UserID  uint            `json:"user_id"`
+	Reason  string          `json:"reason"`
+	Before  json.RawMessage `json:"before" gorm:"type:jsonb"`
+	After   json.RawMessage `json:"after" gorm:"type:jsonb"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// ScheduleExportRow is one game of a league schedule as exported to a spreadsheet
+type ScheduleExportRow struct {
+	GameID    uint
+	Start     time.Time
+	Status    string
+	League    string
+	HomeTeam  string
+	AwayTeam  string
+	Venue     string
+	HomeScore int
+	AwayScore int
+}
+
+// RosterExportRow is one player on a team roster, with their contact details
+type RosterExportRow struct {
+	League    string
+	TeamID    uint
+	Team      string
+	PlayerID  uint
+	FirstName string
+	LastName  string
+	Email     string
+	Phone     string
+	Jersey    *int
+	Position  Position
+	Captain   bool
+}
+
+// GoalExportRow is a goal with its players' names filled in
+type GoalExportRow struct {
+	GoalID        uint
+	GameID        uint
+	Start         time.Time
+	Team          string
+	Period        uint
+	Elapsed       uint // Seconds into the period
+	Scorer        string
+	Assist1       string
+	Assist2       string
+	Strength      string
+	EmptyNet      bool
+	IsPenaltyShot bool
+}
+
+// PenaltyExportRow is a penalty with the player's name and penalty type filled in
+type PenaltyExportRow struct {
+	PenaltyID  uint
+	GameID     uint
+	Start      time.Time
+	Team       string
+	Period     uint
+	Elapsed    uint // Seconds into the period
+	Player     string
+	Infraction string
+	Severity   string
+	Minutes    uint
+}
+
+// ShotExportRow is a shot on goal with the goalie's name filled in
+type ShotExportRow struct {
+	ShotID   uint
+	GameID   uint
+	Start    time.Time
+	Team     string
+	Period   uint
+	ShotTime uint // Seconds elapsed in the period
+	Goalie   string
+}
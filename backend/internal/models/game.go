@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Status string
 
@@ -35,9 +38,62 @@ type Game struct {
 	AwayTeamScore       int    `json:"away_team_score"`
 
 	ScoreKeeper        *User `json:"score_keeper"`
-	ScoreKeeperID      uint  `json:"score_keeper_id"`
+	ScoreKeeperID      *uint `json:"score_keeper_id"`
 	PrimaryReferee     *User `json:"primary_referee"`
 	PrimaryRefereeID   *uint `json:"primary_referee_id"`
 	SecondaryReferee   *User `json:"secondary_referee"`
 	SecondaryRefereeID *uint `json:"secondary_referee_id"`
+
+	SignedOffAt *time.Time `json:"signed_off_at"` // Set once every required signer has signed the game sheet, locking its stats
+}
+
+// GameTotals are the score and shot counts for both teams in a game
+type GameTotals struct {
+	HomeTeamScore       int `json:"home_team_score"`
+	AwayTeamScore       int `json:"away_team_score"`
+	HomeTeamShotsOnGoal int `json:"home_team_shots_on_goal"`
+	AwayTeamShotsOnGoal int `json:"away_team_shots_on_goal"`
+}
+
+// GameTotalsDiscrepancy reports a game whose stored totals disagree with its recorded goals and shots
+type GameTotalsDiscrepancy struct {
+	GameID   uint       `json:"game_id"`
+	Stored   GameTotals `json:"stored"`
+	Derived  GameTotals `json:"derived"`
+	Repaired bool       `json:"repaired"`
+}
+
+// RegulationPeriods is the number of periods in a game before overtime
+const RegulationPeriods = 3
+
+// GameResult is the outcome of a finished game used to build standings
+type GameResult struct {
+	GameID     uint `json:"game_id"`
+	HomeTeamID uint `json:"home_team_id"`
+	AwayTeamID uint `json:"away_team_id"`
+	HomeScore  int  `json:"home_score"`
+	AwayScore  int  `json:"away_score"`
+	Overtime   bool `json:"overtime"`
+}
+
+// PeriodLength is the length of a regulation period in seconds
+const PeriodLength = 20 * 60
+
+// GameSeconds converts a period and the seconds elapsed in it into seconds since puck drop
+func GameSeconds(period, elapsed uint) uint {
+	if period == 0 {
+		return elapsed
+	}
+	return (period-1)*PeriodLength + elapsed
+}
+
+// PeriodClock splits seconds since puck drop into a period and the seconds elapsed in it
+func PeriodClock(gameSeconds uint) (period, elapsed uint) {
+	return gameSeconds/PeriodLength + 1, gameSeconds % PeriodLength
+}
+
+// ScoreboardClock formats the time left in a period, given the seconds elapsed in it, as MM:SS
+func ScoreboardClock(elapsed uint) string {
+	left := PeriodLength - min(elapsed, PeriodLength)
+	return fmt.Sprintf("%02d:%02d", left/60, left%60)
 }
@@ -0,0 +1,11 @@
+package models
+
+// GameSignature is an official's or captain's sign-off on a final game sheet. Digest identifies
+// the version of the sheet that was reviewed, so a signature stops counting if the sheet changes.
+type GameSignature struct {
+	DbModel
+	GameID uint   `json:"game_id" gorm:"uniqueIndex:idx_game_signatures_role"`
+	Role   string `json:"role" gorm:"uniqueIndex:idx_game_signatures_role"` // primary_referee, secondary_referee, home_captain or away_captain
+	UserID uint   `json:"user_id"`
+	Digest string `json:"digest"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// GoalieChange records a goalie taking the net for a team. The starting goalie is
+// recorded as a change at the start of the first period, and a zero GoalieID means
+// the net was emptied for an extra attacker.
+type GoalieChange struct {
+	DbModel
+	GameID   uint `json:"game_id"`
+	TeamID   uint `json:"team_id"`
+	GoalieID uint `json:"goalie_id"`
+	Period   uint `json:"period"`
+	GameTime uint `json:"game_time"` // Seconds elapsed in the period
+}
+
+// GoalieGame is the part of a game needed to work out goalie stats
+type GoalieGame struct {
+	GameID     uint `json:"game_id"`
+	HomeTeamID uint `json:"home_team_id"`
+	AwayTeamID uint `json:"away_team_id"`
+	Length     uint `json:"length"` // Seconds from puck drop to the end of the game
+	Final      bool `json:"final"`
+}
+
+// GoalieEventCount is the number of shots and goals a team allowed in a game while a goalie
+// was in net. A zero GoalieID counts events against an empty net.
+type GoalieEventCount struct {
+	GameID   uint `json:"game_id"`
+	TeamID   uint `json:"team_id"`
+	GoalieID uint `json:"goalie_id"`
+	Shots    int  `json:"shots"`
+	Goals    int  `json:"goals"`
+}
+
+// GoalieStatLine is a goalie's totals for a game or over several games
+type GoalieStatLine struct {
+	GoalieID            uint    `json:"goalie_id"`
+	TeamID              uint    `json:"team_id,omitempty"`
+	FirstName           string  `json:"first_name"`
+	LastName            string  `json:"last_name"`
+	GamesPlayed         int     `json:"games_played"`
+	SecondsPlayed       int     `json:"seconds_played"`
+	ShotsAgainst        int     `json:"shots_against"`
+	GoalsAgainst        int     `json:"goals_against"`
+	Saves               int     `json:"saves"`
+	SavePercentage      float64 `json:"save_percentage"`
+	GoalsAgainstAverage float64 `json:"goals_against_average"`
+	Shutouts            int     `json:"shutouts"`
+}
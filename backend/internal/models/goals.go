@@ -1,21 +1,37 @@
 package models
 
-
-
 type Goal struct {
 	DbModel
-	UserId   			uint          	`json:"user_id"`
-	GameId   			uint          	`json:"game_id"` 
+	UserId uint `json:"user_id"`
+	GameId uint `json:"game_id" gorm:"index:idx_goals_game_client,unique,where:client_id <> ''"`
 	//Game     			Game          	`gorm:"game"` TODO: When seeding is finished we can officially test this
-	TeamId   			uint          	`json:"team_id"` 
+	TeamId uint `json:"team_id"`
 	//Team     			Team			`gorm:"team"` TODO: When seeding is finished we can officially test this
-	Duration 			uint			`json:"duration"` // Do we potentially want to change this to time.Duration
-	Period	    		uint			`json:"period"`
-	Assist1Id			uint   	   		`json:"assist1_id"`
-	Assist2Id			uint   	   		`json:"assist2_id"`
-	PlayerDifferential	int		   		`json:"playerdifferential"`
-	IsPenaltyShot		bool	   		`json:"ispenaltyshot"`
+	Duration           uint        `json:"duration"` // Seconds elapsed in the period. Do we potentially want to change this to time.Duration
+	Period             uint        `json:"period"`
+	Assist1Id          uint        `json:"assist1_id"`
+	Assist2Id          uint        `json:"assist2_id"`
+	PlayerDifferential int         `json:"playerdifferential"` // Worked out from the penalties, see Strength
+	IsPenaltyShot      bool        `json:"ispenaltyshot"`
+	GoalieID           uint        `json:"goalie_id"` // Goalie scored on, filled in from the goalie changes when left empty
+	EmptyNet           bool        `json:"empty_net"`
+	Strength           string      `json:"strength"` // PP, SH or EV, worked out from the penalties when the game's events change
+	OnIce              []GoalOnIce `json:"on_ice" gorm:"foreignKey:GoalID"`
+	ClientID           string      `json:"client_id" gorm:"index:idx_goals_game_client"` // Generated by the scorekeeper's device so offline retries are recorded once, unique within the game
 
 	//powerplay - was someone in the box; bool
 	//penalty - was scored on penalty shot; bool
-}
\ No newline at end of file
+}
+
+// GoalOnIce is a skater who was on the ice for either team when a goal was scored
+type GoalOnIce struct {
+	DbModel
+	GoalID   uint `json:"goal_id"`
+	TeamID   uint `json:"team_id"`
+	PlayerID uint `json:"player_id"`
+}
+
+// Should overide GOs incorrect pluralization
+func (GoalOnIce) TableName() string {
+	return "goals_on_ice"
+}
@@ -1,9 +1,16 @@
 package models
 
+import "github.com/lib/pq"
+
 type League struct {
 	DbModel
-	CorrelationId string `json:"correlation_id"`
-	SeasonID      uint   `json:"season_id"`
-	Name          string `json:"name"`
-	Teams         []Team `json:"teams"`
+	CorrelationId string         `json:"correlation_id"`
+	SeasonID      uint           `json:"season_id"`
+	Name          string         `json:"name"`
+	Teams         []Team         `json:"teams"`
+	PointSystem   string         `json:"point_system"`                   // e.g. "2-1-0" or "3-2-1-0", see the standings service
+	Tiebreakers   pq.StringArray `json:"tiebreakers" gorm:"type:text[]"` // Applied in order when teams are level on points
+	RosterLimit   int            `json:"roster_limit"`                   // Most players a team can carry, 0 for the default
+	Capacity      int            `json:"capacity"`                       // Most registrations the league holds spots for, 0 for no limit
+	EligibilityRules
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Rsvp is whether a player will be at a game. Players who decline are left out of their team's
+// lineup until the captain edits it.
+type Rsvp struct {
+	GameID    uint      `json:"game_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	Attending bool      `json:"attending"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Lineup is who dressed for a team in a game, including subs from outside the roster. A lineup
+// is only saved once the captain first edits it; until then it follows the roster and RSVPs.
+type Lineup struct {
+	DbModel
+	GameID  uint           `json:"game_id" gorm:"uniqueIndex:idx_lineups_game_team"`
+	TeamID  uint           `json:"team_id" gorm:"uniqueIndex:idx_lineups_game_team"`
+	Players []LineupMember `json:"players" gorm:"-"`
+	Locked  bool           `json:"locked" gorm:"-"` // Captains can't change lineups after puck drop
+}
+
+// LineupPlayer is a player in a saved lineup
+type LineupPlayer struct {
+	LineupID     uint     `json:"lineup_id" gorm:"primaryKey"`
+	UserID       uint     `json:"user_id" gorm:"primaryKey"`
+	JerseyNumber *int     `json:"jersey_number"`
+	Position     Position `json:"position" gorm:"default:skater"`
+	Sub          bool     `json:"sub"` // Not on the team's roster
+}
+
+// LineupMember is a player in a lineup as listed and edited through the lineup API
+type LineupMember struct {
+	RosterMember
+	Sub bool `json:"sub"`
+}
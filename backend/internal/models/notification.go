@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Notification is a message for a user, kept in their inbox until they've read it
+type Notification struct {
+	DbModel
+	UserID  uint       `json:"user_id" gorm:"index"`
+	Topic   Topic      `json:"topic"`
+	Message string     `json:"message"`
+	ReadAt  *time.Time `json:"read_at"` // nil until the user has read it
+}
@@ -7,10 +7,11 @@ import (
 type Topic string
 
 const (
-	RSVP         Topic = "new_rsvp"
-	CHAT         Topic = "new_chat"
-	GAME_UPDATE  Topic = "game_update"
-	EVENT_UPDATE Topic = "event_update"
+	RSVP                Topic = "new_rsvp"
+	CHAT                Topic = "new_chat"
+	GAME_UPDATE         Topic = "game_update"
+	EVENT_UPDATE        Topic = "event_update"
+	REGISTRATION_UPDATE Topic = "registration_update"
 )
 
 // All the JSON is blanked because this is sensitive information and should never go to the front end
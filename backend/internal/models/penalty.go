@@ -11,10 +11,12 @@ type Penalty struct {
 	DbModel
 	PlayerID      uint        `json:"player_id"`
 	TeamID        uint        `json:"team_id"`
-	GameID        uint        `json:"game_id"`
+	GameID        uint        `json:"game_id" gorm:"index:idx_penalties_game_client,unique,where:client_id <> ''"`
 	Period        uint        `json:"period"`
-	Duration      uint        `json:"duration"`
+	GameTime      uint        `json:"game_time"` // Seconds elapsed in the period when the penalty was called
+	Duration      uint        `json:"duration"`  // Seconds served, defaults to the penalty type's duration
 	CreatedBy     uint        `json:"created_by"`
 	PenaltyType   PenaltyType `json:"penalty_type"`
 	PenaltyTypeID uint        `json:"penalty_type_id"`
+	ClientID      string      `json:"client_id" gorm:"index:idx_penalties_game_client"` // Generated by the scorekeeper's device so offline retries are recorded once, unique within the game
 }
@@ -1,17 +1,76 @@
 package models
 
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
 type Registration struct {
 	DbModel
-	SeasonID  uint `json:"season_id"`
-	UserID    uint
-	User      User       `json:"user"`
-	Questions []Question `type:"questions"`
+	SeasonID   uint               `json:"season_id"`
+	LeagueID   uint               `json:"league_id"` // The league the player wants to play in
+	UserID     uint               `json:"user_id"`
+	User       User               `json:"user"`
+	Position   Position           `json:"position" gorm:"default:skater"`
+	PlayWith   pq.Int64Array      `json:"play_with" gorm:"type:bigint[]"` // Players the registrant asked to be on a team with
+	Questions  []Question         `json:"questions"`                      // The registrant's answers to the season's form
+	Status     RegistrationStatus `json:"status" gorm:"default:pending;index"`
+	ReviewedBy *uint              `json:"reviewed_by"`
+	ReviewedAt *time.Time         `json:"reviewed_at"`
+	ReviewNote string             `json:"review_note"`
 }
 
+// RegistrationStatus is where a registration is in its league's approval queue. Pending and
+// approved registrations hold a spot in the league; waitlisted ones wait for a spot to open.
+type RegistrationStatus string
+
+const (
+	RegistrationPending    RegistrationStatus = "pending" // Holds a spot while it waits for a manager
+	RegistrationApproved   RegistrationStatus = "approved"
+	RegistrationWaitlisted RegistrationStatus = "waitlisted" // Moves to pending, oldest first, as spots open
+	RegistrationRejected   RegistrationStatus = "rejected"
+	RegistrationWithdrawn  RegistrationStatus = "withdrawn"
+)
+
+// Question is a registrant's answer to a question on their season's registration form. The
+// question's text and type are kept as they were when the answer was given.
 type Question struct {
 	DbModel
-	RegistrationID uint
+	RegistrationID uint   `json:"registration_id" gorm:"index"`
+	Key            string `json:"key"`
 	Text           string `json:"text"`
 	Answer         string `json:"answer"`
-	Render         string `json:"render"`
+	Render         string `json:"render"` // The question's type
+}
+
+// QuestionType is the kind of answer a registration form question takes
+type QuestionType string
+
+const (
+	TextQuestion     QuestionType = "text"
+	ChoiceQuestion   QuestionType = "choice"   // One of the question's choices
+	CheckboxQuestion QuestionType = "checkbox" // "true" or "false"
+	DateQuestion     QuestionType = "date"     // YYYY-MM-DD
+	WaiverQuestion   QuestionType = "waiver"   // Must be acknowledged with "true" whenever it's asked
+)
+
+// FormQuestion is a question on a season's registration form, asked in ID order. A question with
+// a condition is only asked when the question it depends on was given the matching answer.
+type FormQuestion struct {
+	DbModel
+	SeasonID     uint           `json:"season_id" gorm:"index"`
+	Key          string         `json:"key"` // Names the question in answers, conditions and filters
+	Text         string         `json:"text"`
+	Type         QuestionType   `json:"type"`
+	Choices      pq.StringArray `json:"choices" gorm:"type:text[]"`
+	Required     bool           `json:"required"`
+	ShowIfKey    string         `json:"show_if_key"`
+	ShowIfAnswer string         `json:"show_if_answer"`
+}
+
+// AnswerProblem is why an answer to a registration form question was refused
+type AnswerProblem struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
 }
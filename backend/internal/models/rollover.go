@@ -0,0 +1,55 @@
+package models
+
+import "github.com/lib/pq"
+
+// RolloverTeam is a team copied into a new season. Its record starts at zero.
+type RolloverTeam struct {
+	FromTeamID    uint   `json:"from_team_id"`
+	TeamID        uint   `json:"team_id"`
+	Name          string `json:"name"`
+	CorrelationId string `json:"correlation_id"`
+	Color         string `json:"color"`
+	LogoId        string `json:"logo_id"`
+	FromRosterID  uint   `json:"-"`
+	RosterID      uint   `json:"roster_id"`
+	Players       int    `json:"players"`    // Players carried forward from last season's roster
+	CaptainID     uint   `json:"captain_id"` // Carried forward with the roster
+
+	// Players left off the copied roster because they break the new league's eligibility rules
+	Refused []RolloverRefusal `json:"refused"`
+}
+
+// RolloverRefusal is a player who wasn't carried forward and the eligibility rules they break
+type RolloverRefusal struct {
+	UserID     uint                   `json:"user_id"`
+	Violations []EligibilityViolation `json:"violations"`
+}
+
+// RolloverLeague is a league copied into a new season with its teams
+type RolloverLeague struct {
+	FromLeagueID  uint           `json:"from_league_id"`
+	LeagueID      uint           `json:"league_id"`
+	Name          string         `json:"name"`
+	CorrelationId string         `json:"correlation_id"`
+	PointSystem   string         `json:"point_system"`
+	Tiebreakers   pq.StringArray `json:"tiebreakers"`
+	RosterLimit   int            `json:"roster_limit"`
+	Capacity      int            `json:"capacity"`
+	EligibilityRules
+	Teams []RolloverTeam `json:"teams"`
+}
+
+// Rollover reports the season created from another and every league and team copied into it.
+// A preview reports the same without saving anything, so its new IDs are zero.
+type Rollover struct {
+	FromSeasonID uint             `json:"from_season_id"`
+	Season       Season           `json:"season"`
+	CarryRosters bool             `json:"carry_rosters"`
+	Leagues      []RolloverLeague `json:"leagues"`
+	Preview      bool             `json:"preview"`
+
+	// Source leagues and teams that had no correlation ID are given one so they're linked to
+	// their copies
+	AssignedLeagueCorrelations map[uint]string `json:"-"`
+	AssignedTeamCorrelations   map[uint]string `json:"-"`
+}
@@ -6,3 +6,34 @@ type Roster struct {
 	Captain   User    `json:"captain"`
 	CaptainID uint    `json:"captain_id"`
 }
+
+// Position is where a player plays
+type Position string
+
+const (
+	Skater Position = "skater"
+	Goalie Position = "goalie"
+)
+
+// RosterPlayer is a player's membership on a roster, stored in the join table behind
+// Roster.Players. A jersey number is optional but unique within a roster.
+type RosterPlayer struct {
+	RosterID     uint     `json:"roster_id" gorm:"primaryKey"`
+	UserID       uint     `json:"user_id" gorm:"primaryKey"`
+	JerseyNumber *int     `json:"jersey_number"`
+	Position     Position `json:"position" gorm:"default:skater"`
+}
+
+func (RosterPlayer) TableName() string {
+	return "player_rosters"
+}
+
+// RosterMember is a player on a team's roster as listed and edited through the roster API
+type RosterMember struct {
+	UserID       uint     `json:"user_id"`
+	FirstName    string   `json:"first_name"`
+	LastName     string   `json:"last_name"`
+	JerseyNumber *int     `json:"jersey_number"`
+	Position     Position `json:"position"`
+	Captain      bool     `json:"captain"`
+}
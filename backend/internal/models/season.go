@@ -4,10 +4,13 @@ import "time"
 
 type Season struct {
 	DbModel
-	Name          string         `json:"name"`
-	Start         time.Time      `json:"start"`
-	End           time.Time      `json:"end"`
-	Registrations []Registration `json:"registrations"`
-	Schedule      []Game         `json:"schedule"`
-	Leagues       []League       `json:"leagues"`
+	Name               string         `json:"name"`
+	Start              time.Time      `json:"start"`
+	End                time.Time      `json:"end"`
+	Registrations      []Registration `json:"registrations"`
+	Schedule           []Game         `json:"schedule"`
+	Leagues            []League       `json:"leagues"`
+	TradeDeadline      *time.Time     `json:"trade_deadline"`      // Transfers can't take effect after it, nil for no deadline
+	RegistrationOpens  *time.Time     `json:"registration_opens"`  // nil to open as soon as the season is created
+	RegistrationCloses *time.Time     `json:"registration_closes"` // nil to close when the season starts
 }
\ No newline at end of file
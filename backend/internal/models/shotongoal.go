@@ -1,15 +1,17 @@
 package models
 
-
-type ShotOnGoal struct{
+type ShotOnGoal struct {
 	DbModel
-	GameId uint `json:"game_id" gorm:"not_null"`
-	TeamId uint `json:"team_id" gorm:"not_null"`
-	ShotTime uint `json:"shot_time" gorm:"not_null"`
-	Scorekeeper uint `json:"scorekeeper gorm:"not_null"`
+	GameId      uint   `json:"game_id" gorm:"not_null;index:idx_shots_on_goal_game_client,unique,where:client_id <> ''"`
+	TeamId      uint   `json:"team_id" gorm:"not_null"`
+	Period      uint   `json:"period"`
+	ShotTime    uint   `json:"shot_time" gorm:"not_null"` // Seconds elapsed in the period
+	Scorekeeper uint   `json:"scorekeeper" gorm:"not_null"`
+	GoalieID    uint   `json:"goalie_id"`                                            // Goalie who faced the shot, filled in from the goalie changes when left empty
+	ClientID    string `json:"client_id" gorm:"index:idx_shots_on_goal_game_client"` // Generated by the scorekeeper's device so offline retries are recorded once, unique within the game
 }
 
 // Should overide GOs incorrect pluralization
 func (ShotOnGoal) TableName() string {
-    return "shots_on_goal"
-}
\ No newline at end of file
+	return "shots_on_goal"
+}
@@ -0,0 +1,15 @@
+package models
+
+// PlayerStatLine is a player's scoring totals over a set of games
+type PlayerStatLine struct {
+	PlayerID      uint    `json:"player_id"`
+	FirstName     string  `json:"first_name"`
+	LastName      string  `json:"last_name"`
+	GamesPlayed   int     `json:"games_played"`
+	Goals         int     `json:"goals"`
+	Assists       int     `json:"assists"`
+	Points        int     `json:"points"`
+	PenaltyMins   int     `json:"penalty_minutes"`
+	PlusMinus     int     `json:"plus_minus"`
+	PointsPerGame float64 `json:"points_per_game"`
+}
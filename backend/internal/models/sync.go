@@ -0,0 +1,13 @@
+package models
+
+// SyncOperation is an offline scorekeeping operation that has been applied. Replaying the same
+// OpID for the same game returns the recorded outcome instead of applying it again.
+type SyncOperation struct {
+	DbModel
+	OpID    string `json:"op_id" gorm:"uniqueIndex:idx_sync_operations_game_op,priority:2"`
+	GameID  uint   `json:"game_id" gorm:"uniqueIndex:idx_sync_operations_game_op,priority:1"`
+	Kind    string `json:"kind"`
+	Action  string `json:"action"`
+	EventID uint   `json:"event_id"`
+	UserID  uint   `json:"user_id"`
+}
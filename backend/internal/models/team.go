@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Team struct {
 	DbModel
 	CorrelationId string `json:"correlation_id"`
@@ -11,6 +13,13 @@ type Team struct {
 	Roster        Roster `json:"roster"`
 	RosterID      uint   `json:"roster_id"`
 
-	Wins   int `json:"wins"`
-	Losses int `json:"losses"`
+	ArchivedAt *time.Time `json:"archived_at"` // Archived teams are kept for history but their rosters can't change
+
+	Wins           int `json:"wins"`
+	Losses         int `json:"losses"`
+	Ties           int `json:"ties"`
+	OvertimeLosses int `json:"overtime_losses"`
+	GoalsFor       int `json:"goals_for"`
+	GoalsAgainst   int `json:"goals_against"`
+	Points         int `json:"points"`
 }
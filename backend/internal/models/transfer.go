@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// TransferStatus is where a transfer is in its review
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "pending"
+	TransferApproved  TransferStatus = "approved" // Waiting for its effective date
+	TransferRejected  TransferStatus = "rejected"
+	TransferCompleted TransferStatus = "completed" // The player has moved rosters
+)
+
+// Transfer moves a player from one team's roster to another's within a season. It takes effect on
+// its effective date once a manager approves it.
+type Transfer struct {
+	DbModel
+	UserID      uint           `json:"user_id" gorm:"index"`
+	FromTeamID  uint           `json:"from_team_id"`
+	ToTeamID    uint           `json:"to_team_id"`
+	EffectiveAt time.Time      `json:"effective_at"`
+	Status      TransferStatus `json:"status" gorm:"index"`
+	Reason      string         `json:"reason"`
+	RequestedBy uint           `json:"requested_by"`
+	ReviewedBy  *uint          `json:"reviewed_by"`
+	ReviewedAt  *time.Time     `json:"reviewed_at"`
+	ReviewNote  string         `json:"review_note"`
+	CompletedAt *time.Time     `json:"completed_at"`
+}
+
+// TeamMembership is a spell a player spent on a team's roster. Games are credited to the team a
+// player belonged to at puck drop. StartedAt is nil for players rostered before history was kept,
+// and EndedAt is nil while they're still on the team.
+type TeamMembership struct {
+	ID         uint       `json:"id" gorm:"primarykey"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	TeamID     uint       `json:"team_id" gorm:"index"`
+	StartedAt  *time.Time `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	TransferID *uint      `json:"transfer_id"` // The transfer that started the spell, if any
+}
+
+// MembershipHistory is a spell on a team as listed for a player
+type MembershipHistory struct {
+	TeamID     uint       `json:"team_id"`
+	TeamName   string     `json:"team_name"`
+	LeagueID   uint       `json:"league_id"`
+	LeagueName string     `json:"league_name"`
+	SeasonID   uint       `json:"season_id"`
+	StartedAt  *time.Time `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	TransferID *uint      `json:"transfer_id"`
+}
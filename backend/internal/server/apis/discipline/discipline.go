@@ -0,0 +1,205 @@
+package discipline
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/discipline"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/discipline/rules", auth.Public, getRulesHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/discipline/rules", auth.ManagerOnly, postRuleHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/discipline/rules/:id", auth.ManagerOnly, deleteRuleHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/suspensions", auth.Public, getSuspensionsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/suspensions", auth.ManagerOnly, postSuspensionHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/suspensions/:id/override", auth.ManagerOnly, overrideSuspensionHandler)
+}
+
+func getRulesHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	db := db.GetSession(c)
+	rules, err := db.GetDisciplineRules()
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get discipline rules from the database")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, rules)
+}
+
+func postRuleHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	rule := &models.DisciplineRule{}
+	err := c.BodyParser(rule)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse discipline rule request payload")
+		return responder.BadRequest(c, "Failed to parse discipline rule request payload")
+	}
+
+	if err := discipline.Validate(*rule); err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
+	db := db.GetSession(c)
+	record, err := db.SaveDisciplineRule(rule)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to save discipline rule")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, record)
+}
+
+func deleteRuleHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return responder.BadRequest(c, "Invalid rule id")
+	}
+
+	db := db.GetSession(c)
+	deleted, err := db.DeleteDisciplineRule(uint(id))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to delete discipline rule %v", id)
+		return responder.InternalServerError(c)
+	}
+	if !deleted {
+		return responder.BadRequest(c, "Discipline rule %v does not exist", id)
+	}
+
+	return responder.Ok(c)
+}
+
+func getSuspensionsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	query := struct {
+		PlayerID uint `query:"player_id"`
+		SeasonID uint `query:"season_id"`
+		Active   bool `query:"active"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	session := db.GetSession(c)
+	suspensions, err := session.GetSuspensions(db.SuspensionFilter{
+		PlayerID: query.PlayerID,
+		SeasonID: query.SeasonID,
+		Active:   query.Active,
+	})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get suspensions from the database")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, suspensions)
+}
+
+// postSuspensionHandler lets a manager suspend a player directly, for incidents that don't
+// set off a discipline rule
+func postSuspensionHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	suspension := &models.Suspension{}
+	err := c.BodyParser(suspension)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse suspension request payload")
+		return responder.BadRequest(c, "Failed to parse suspension request payload")
+	}
+
+	if suspension.PlayerID == 0 || suspension.TeamID == 0 {
+		return responder.BadRequest(c, "player_id and team_id are required")
+	}
+	if suspension.Games < 1 {
+		return responder.BadRequest(c, "games must be at least 1")
+	}
+	if suspension.Reason == "" {
+		return responder.BadRequest(c, "A reason is required")
+	}
+	if suspension.StartsAt.IsZero() {
+		suspension.StartsAt = time.Now()
+	}
+	suspension.PenaltyID = nil
+	suspension.RuleID = nil
+
+	session := db.GetSession(c)
+	teams, err := session.GetTeamsByIds([]uint{suspension.TeamID})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get team %v from the database", suspension.TeamID)
+		return responder.InternalServerError(c)
+	}
+	if len(teams) == 0 {
+		return responder.BadRequest(c, "Team %v does not exist", suspension.TeamID)
+	}
+	if suspension.SeasonID == 0 {
+		league, err := session.GetLeague(teams[0].LeagueID)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get league %v from the database", teams[0].LeagueID)
+			return responder.InternalServerError(c)
+		}
+		if league != nil {
+			suspension.SeasonID = league.SeasonID
+		}
+	}
+
+	record, err := session.SaveSuspension(suspension)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to save suspension")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, record)
+}
+
+// overrideSuspensionHandler lets a manager shorten, lengthen or lift (zero games) a suspension.
+// A reason is always required and kept with the suspension.
+func overrideSuspensionHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return responder.BadRequest(c, "Invalid suspension id")
+	}
+
+	request := struct {
+		Games  int    `json:"games"`
+		Reason string `json:"reason"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse override request payload")
+	}
+	if request.Games < 0 {
+		return responder.BadRequest(c, "games can't be negative")
+	}
+	if request.Reason == "" {
+		return responder.BadRequest(c, "A reason is required to override a suspension")
+	}
+
+	db := db.GetSession(c)
+	suspension, err := db.GetSuspension(uint(id))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get suspension %v from the database", id)
+		return responder.InternalServerError(c)
+	}
+	if suspension == nil {
+		return responder.BadRequest(c, "Suspension %v does not exist", id)
+	}
+
+	var managerId *uint
+	if record := locals.KeyRecord(c); record != nil {
+		managerId = &record.UserId
+	}
+
+	suspension, err = db.OverrideSuspension(suspension, request.Games, managerId, request.Reason)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to override suspension %v", id)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, suspension)
+}
@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/export"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/log"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/export/schedule", auth.Public, getScheduleExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/rosters", auth.ManagerOnly, getRosterExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/goals", auth.Public, getGoalExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/penalties", auth.Public, getPenaltyExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/shots", auth.Public, getShotExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/standings", auth.Public, getStandingsExportHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/export/registrations", auth.ManagerOnly, getRegistrationExportHandler)
+}
+
+type exportRequest struct {
+	Filter db.ExportFilter
+	Format export.Format
+}
+
+// parseExportRequest reads the filter and format of an export from the query string. When it
+// returns a nil request the response has already been written.
+func parseExportRequest(c *fiber.Ctx) (*exportRequest, error) {
+	query := struct {
+		SeasonID uint   `query:"season_id"`
+		LeagueID uint   `query:"league_id"`
+		TeamID   uint   `query:"team_id"`
+		GameID   uint   `query:"game_id"`
+		Format   string `query:"format"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return nil, responder.BadRequest(c, "Invalid query parameters")
+	}
+	format, err := export.ParseFormat(query.Format)
+	if err != nil {
+		return nil, responder.BadRequest(c, err.Error())
+	}
+
+	return &exportRequest{
+		Filter: db.ExportFilter{
+			SeasonID: query.SeasonID,
+			LeagueID: query.LeagueID,
+			TeamID:   query.TeamID,
+			GameID:   query.GameID,
+		},
+		Format: format,
+	}, nil
+}
+
+// streamExport sends an export as a download, writing rows as rows produces them rather than
+// building the whole file first. The body is written after the handler returns and fasthttp has
+// recycled c, so rows and log must not hold on to c or anything read from it. Once streaming has
+// started the status can't change, so a failure part way through is logged and the file is left
+// unfinished for the client to notice.
+func streamExport(c *fiber.Ctx, log log.Logger, format export.Format, name string, columns []export.Column, rows func(write func([]string) error) error) error {
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s", name, format)))
+	c.Status(fiber.StatusOK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, err := export.NewWriter(w, format, name, columns)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to start the %v export", name)
+			return
+		}
+		if err := rows(writer.Write); err != nil {
+			log.WithErr(err).Alert("Failed to export %v", name)
+			return
+		}
+		if err := writer.Close(); err != nil {
+			log.WithErr(err).Alert("Failed to finish the %v export", name)
+		}
+	})
+	return nil
+}
+
+func getScheduleExportHandler(c *fiber.Ctx) error {
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+
+	log := locals.Logger(c)
+	session := db.NewSession(log)
+	return streamExport(c, log, request.Format, "schedule", export.ScheduleColumns, func(write func([]string) error) error {
+		return session.StreamSchedule(request.Filter, func(row models.ScheduleExportRow) error {
+			return write(export.ScheduleRecord(row))
+		})
+	})
+}
+
+// getRosterExportHandler exports team rosters with each player's contact details, which is why
+// it is limited to managers
+func getRosterExportHandler(c *fiber.Ctx) error {
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+
+	log := locals.Logger(c)
+	session := db.NewSession(log)
+	return streamExport(c, log, request.Format, "rosters", export.RosterColumns, func(write func([]string) error) error {
+		return session.StreamRosters(request.Filter, func(row models.RosterExportRow) error {
+			return write(export.RosterRecord(row))
+		})
+	})
+}
+
+func getGoalExportHandler(c *fiber.Ctx) error {
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+
+	log := locals.Logger(c)
+	session := db.NewSession(log)
+	return streamExport(c, log, request.Format, "goals", export.GoalColumns, func(write func([]string) error) error {
+		return session.StreamGoals(request.Filter, func(row models.GoalExportRow) error {
+			return write(export.GoalRecord(row))
+		})
+	})
+}
+
+func getPenaltyExportHandler(c *fiber.Ctx) error {
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+
+	log := locals.Logger(c)
+	session := db.NewSession(log)
+	return streamExport(c, log, request.Format, "penalties", export.PenaltyColumns, func(write func([]string) error) error {
+		return session.StreamPenalties(request.Filter, func(row models.PenaltyExportRow) error {
+			return write(export.PenaltyRecord(row))
+		})
+	})
+}
+
+func getShotExportHandler(c *fiber.Ctx) error {
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+
+	log := locals.Logger(c)
+	session := db.NewSession(log)
+	return streamExport(c, log, request.Format, "shots", export.ShotColumns, func(write func([]string) error) error {
+		return session.StreamShots(request.Filter, func(row models.ShotExportRow) error {
+			return write(export.ShotRecord(row))
+		})
+	})
+}
+
+// getStandingsExportHandler exports a league's table. The league and its standings configuration
+// are checked before anything is sent so a bad request still gets an error status.
+func getStandingsExportHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+	leagueId := request.Filter.LeagueID
+	if leagueId == 0 {
+		return responder.BadRequest(c, "A league_id is required to export standings")
+	}
+
+	session := db.NewSession(log)
+	league, err := session.GetLeague(leagueId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get league %v from the database", leagueId)
+		return responder.InternalServerError(c)
+	}
+	if league == nil {
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	config, err := standings.ConfigFor(league)
+	if err != nil {
+		return responder.BadRequest(c, "League %v has an invalid standings configuration: %v", leagueId, err)
+	}
+
+	return streamExport(c, log, request.Format, "standings", export.StandingsColumns, func(write func([]string) error) error {
+		return session.StreamStandings(league, config, func(row standings.Row) error {
+			return write(export.StandingsRecord(row))
+		})
+	})
+}
+
+// getRegistrationExportHandler exports a season's registrations with a column for each question on
+// its form. Like the review list, it can be narrowed to a league or to one answer to a question.
+// Registrants' contact details are included, so it is limited to managers.
+func getRegistrationExportHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	request, err := parseExportRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+	seasonId := request.Filter.SeasonID
+	if seasonId == 0 {
+		return responder.BadRequest(c, "A season_id is required to export registrations")
+	}
+
+	session := db.NewSession(log)
+	form, err := session.GetForm(seasonId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the registration form of season %v", seasonId)
+		return responder.InternalServerError(c)
+	}
+	// The query strings are only valid until the handler returns, so they're copied for the
+	// stream writer
+	filter := db.RegistrationFilter{
+		SeasonID: seasonId,
+		LeagueID: request.Filter.LeagueID,
+		Status:   models.RegistrationStatus(utils.CopyString(c.Query("status"))),
+		Key:      utils.CopyString(c.Query("question")),
+		Answer:   utils.CopyString(c.Query("answer")),
+	}
+
+	return streamExport(c, log, request.Format, "registrations", export.RegistrationColumns(form), func(write func([]string) error) error {
+		return session.StreamRegistrations(filter, func(r models.Registration) error {
+			return write(export.RegistrationRecord(r, form))
+		})
+	})
+}
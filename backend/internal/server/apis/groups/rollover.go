@@ -0,0 +1,59 @@
+package season
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/rollover"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/seasons/:id/rollover", auth.ManagerOnly, postRolloverHandler)
+}
+
+// postRolloverHandler starts a new season from an existing one by copying its leagues and teams.
+// With preview=true it reports what would be created without saving anything.
+func postRolloverHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	options := rollover.Options{}
+	if err := c.BodyParser(&options); err != nil {
+		return responder.BadRequest(c, "Failed to parse rollover request payload")
+	}
+	if err := options.Validate(); err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
+	query := struct {
+		Preview bool `query:"preview"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	session := db.GetSession(c)
+	result, err := session.RollOverSeason(uint(seasonId), options, query.Preview)
+	switch {
+	case errors.Is(err, rollover.ErrUnknownLeague):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to roll over season %v", seasonId)
+		return responder.InternalServerError(c)
+	case result == nil:
+		return responder.BadRequest(c, "Season %v does not exist", seasonId)
+	}
+
+	if !query.Preview {
+		log.Info("Rolled season %v over into season %v", seasonId, result.Season.ID)
+	}
+	return responder.OkWithData(c, result)
+}
@@ -1,6 +1,8 @@
 package season
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/jak103/powerplay/internal/db"
 	"github.com/jak103/powerplay/internal/models"
@@ -13,6 +15,7 @@ import (
 func init() {
 	apis.RegisterHandler(fiber.MethodGet, "/seasons", auth.Public, getSeasonsHandler)
 	apis.RegisterHandler(fiber.MethodPost, "/seasons", auth.Public, postSeasonsHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/seasons/:id/trade-deadline", auth.ManagerOnly, putTradeDeadlineHandler)
 
 }
 
@@ -52,3 +55,32 @@ func postSeasonsHandler(c *fiber.Ctx) error {
 
 	return responder.Ok(c)
 }
+
+// putTradeDeadlineHandler sets the last moment transfers in a season can take effect. A null
+// trade_deadline removes the deadline.
+func putTradeDeadlineHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	request := struct {
+		TradeDeadline *time.Time `json:"trade_deadline"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse trade deadline request payload")
+	}
+
+	db := db.GetSession(c)
+	season, err := db.SetTradeDeadline(uint(seasonId), request.TradeDeadline)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to set the trade deadline of season %v", seasonId)
+		return responder.InternalServerError(c)
+	}
+	if season == nil {
+		return responder.BadRequest(c, "Season %v does not exist", seasonId)
+	}
+
+	return responder.OkWithData(c, season)
+}
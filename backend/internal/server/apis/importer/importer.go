@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/importer"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/import/:kind", auth.ManagerOnly, postImportHandler)
+}
+
+// postImportHandler imports users, roster memberships or games from a CSV file, sent either as
+// the request body or as the "file" field of a form. With dry_run=true the file is checked
+// against the database and every problem is reported without saving anything.
+func postImportHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	kind, err := importer.ParseKind(c.Params("kind"))
+	if err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
+	query := struct {
+		DryRun   bool   `query:"dry_run"`
+		TimeZone string `query:"time_zone"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+	location, err := time.LoadLocation(query.TimeZone)
+	if err != nil {
+		return responder.BadRequest(c, "Unknown time zone %q", query.TimeZone)
+	}
+
+	file, err := uploadedFile(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to read the uploaded file")
+	}
+	defer file.Close()
+
+	session := db.GetSession(c)
+	result, err := session.Import(kind, file, query.DryRun, location)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to import %v", kind)
+		return responder.InternalServerError(c)
+	}
+
+	if !query.DryRun && !result.Saved {
+		return responder.BadRequestWithData(c, result, "The file has problems, nothing was imported")
+	}
+	if result.Saved {
+		log.Info("Imported %v: %v created, %v matched", kind, result.Created, result.Matched)
+	}
+	return responder.OkWithData(c, result)
+}
+
+// uploadedFile returns the CSV from a multipart form's "file" field, or else the raw body
+func uploadedFile(c *fiber.Ctx) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return io.NopCloser(bytes.NewReader(c.Body())), nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return header.Open()
+}
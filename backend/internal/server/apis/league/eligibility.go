@@ -0,0 +1,173 @@
+package league
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPut, "/leagues/:id/eligibility", auth.ManagerOnly, putEligibilityRulesHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/leagues/:id/eligibility/players/:user_id", auth.ManagerOnly, getPlayerEligibilityHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/leagues/:id/eligibility/exceptions", auth.ManagerOnly, getEligibilityExceptionsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/leagues/:id/eligibility/exceptions", auth.ManagerOnly, postEligibilityExceptionHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/leagues/:id/eligibility/exceptions/:exception_id", auth.ManagerOnly, deleteEligibilityExceptionHandler)
+}
+
+// putEligibilityRulesHandler replaces a league's eligibility rules. Players already rostered
+// aren't removed by stricter rules; they're checked the next time they join a roster or lineup.
+func putEligibilityRulesHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	rules := models.EligibilityRules{}
+	if err := c.BodyParser(&rules); err != nil {
+		return responder.BadRequest(c, "Failed to parse eligibility rules payload")
+	}
+	if rules.MinAge < 0 || rules.MaxSkillLevel < 0 {
+		return responder.BadRequest(c, "The min_age and max_skill_level can't be negative")
+	}
+
+	db := db.GetSession(c)
+	league, err := db.SetEligibilityRules(uint(leagueId), rules)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to set the eligibility rules of league %v", leagueId)
+		return responder.InternalServerError(c)
+	}
+	if league == nil {
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	return responder.OkWithData(c, league)
+}
+
+// getPlayerEligibilityHandler lists the rules of a league a player breaks, less their exceptions
+func getPlayerEligibilityHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+
+	db := db.GetSession(c)
+	violations, err := db.CheckEligibility(uint(leagueId), uint(userId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to check the eligibility of player %v for league %v", userId, leagueId)
+		return responder.InternalServerError(c)
+	}
+	if violations == nil {
+		return responder.BadRequest(c, "League %v or player %v does not exist", leagueId, userId)
+	}
+
+	return responder.OkWithData(c, fiber.Map{
+		"eligible":   len(violations) == 0,
+		"violations": violations,
+	})
+}
+
+func getEligibilityExceptionsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	db := db.GetSession(c)
+	exceptions, err := db.GetEligibilityExceptions(uint(leagueId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the eligibility exceptions of league %v", leagueId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, exceptions)
+}
+
+// postEligibilityExceptionHandler lets a player play in a league despite one of its rules
+func postEligibilityExceptionHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	request := struct {
+		UserID uint                   `json:"user_id"`
+		Rule   models.EligibilityRule `json:"rule"`
+		Reason string                 `json:"reason"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse eligibility exception payload")
+	}
+	if request.UserID == 0 {
+		return responder.BadRequest(c, "A user_id is required")
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	league, err := session.GetLeague(uint(leagueId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get league %v", leagueId)
+		return responder.InternalServerError(c)
+	}
+	if league == nil {
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	exception, err := session.SaveEligibilityException(&models.EligibilityException{
+		LeagueID:   uint(leagueId),
+		UserID:     request.UserID,
+		Rule:       request.Rule,
+		Reason:     request.Reason,
+		ApprovedBy: record.UserId,
+	})
+	switch {
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to save an eligibility exception for player %v in league %v", request.UserID, leagueId)
+		return responder.InternalServerError(c)
+	}
+
+	log.Info("Player %v is excepted from the %v rule of league %v", exception.UserID, exception.Rule, exception.LeagueID)
+	return responder.OkWithData(c, exception)
+}
+
+func deleteEligibilityExceptionHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+	exceptionId, err := c.ParamsInt("exception_id")
+	if err != nil || exceptionId <= 0 {
+		return responder.BadRequest(c, "Invalid exception id")
+	}
+
+	db := db.GetSession(c)
+	deleted, err := db.DeleteEligibilityException(uint(leagueId), uint(exceptionId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to delete eligibility exception %v", exceptionId)
+		return responder.InternalServerError(c)
+	}
+	if !deleted {
+		return responder.BadRequest(c, "Exception %v does not exist", exceptionId)
+	}
+
+	return responder.Ok(c)
+}
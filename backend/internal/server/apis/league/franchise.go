@@ -0,0 +1,34 @@
+package league
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/career"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/franchises/:correlation_id", auth.Public, getFranchiseHandler)
+}
+
+// getFranchiseHandler returns the history of the teams sharing a correlation ID: each season's
+// name, colors, record and captain, and the all-time record
+func getFranchiseHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	correlationId := c.Params("correlation_id")
+
+	db := db.GetSession(c)
+	seasons, err := db.GetFranchiseSeasons(correlationId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the history of franchise %v", correlationId)
+		return responder.InternalServerError(c)
+	}
+	if len(seasons) == 0 {
+		return responder.BadRequest(c, "Franchise %v does not exist", correlationId)
+	}
+
+	return responder.OkWithData(c, career.Franchise(correlationId, seasons))
+}
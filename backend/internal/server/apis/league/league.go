@@ -6,6 +6,7 @@ import (
 	"github.com/jak103/powerplay/internal/models"
 	"github.com/jak103/powerplay/internal/server/apis"
 	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/standings"
 	"github.com/jak103/powerplay/internal/utils/locals"
 	"github.com/jak103/powerplay/internal/utils/responder"
 )
@@ -38,6 +39,10 @@ func postLeagueHandler(c *fiber.Ctx) error {
 		return responder.BadRequest(c, "Failed to parse leagues request payload")
 	}
 
+	if _, err := standings.ConfigFor(leagueRequest); err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
 	db := db.GetSession(c)
 	err = db.CreateLeague(leagueRequest)
 	if err != nil {
@@ -0,0 +1,79 @@
+package league
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/leagues/:id/standings", auth.Public, getStandingsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/leagues/:id/standings", auth.ManagerOnly, postStandingsHandler)
+}
+
+func getStandingsHandler(c *fiber.Ctx) error {
+	table, err := computeStandings(c)
+	if err != nil || table == nil {
+		return err
+	}
+
+	return responder.OkWithData(c, table)
+}
+
+// postStandingsHandler recomputes the standings and stores each team's record on the team
+func postStandingsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	table, err := computeStandings(c)
+	if err != nil || table == nil {
+		return err
+	}
+
+	db := db.GetSession(c)
+	err = db.UpdateTeamRecords(standings.Records(table))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to save team records")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, table)
+}
+
+// computeStandings builds the table for the league in the request path. When it returns
+// a nil table the response has already been written.
+func computeStandings(c *fiber.Ctx) ([]standings.Row, error) {
+	log := locals.Logger(c)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return nil, responder.BadRequest(c, "Invalid league id")
+	}
+
+	db := db.GetSession(c)
+	league, err := db.GetLeague(uint(id))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get league %v from the database", id)
+		return nil, responder.InternalServerError(c)
+	}
+	if league == nil {
+		return nil, responder.BadRequest(c, "League %v does not exist", id)
+	}
+
+	config, err := standings.ConfigFor(league)
+	if err != nil {
+		log.WithErr(err).Error("League %v has an invalid standings configuration", id)
+		return nil, responder.BadRequest(c, err.Error())
+	}
+
+	results, err := db.GetFinalGameResults(league.ID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get game results for league %v", id)
+		return nil, responder.InternalServerError(c)
+	}
+
+	return standings.Compute(league.Teams, results, config), nil
+}
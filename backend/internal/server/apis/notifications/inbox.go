@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/notifications", auth.Authenticated, getNotificationsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/notifications/read", auth.Authenticated, postReadNotificationsHandler)
+}
+
+// getNotificationsHandler lists the signed in user's notifications, newest first, or only the
+// unread ones
+func getNotificationsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	query := struct {
+		Unread bool `query:"unread"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	session := db.GetSession(c)
+	notifications, err := session.GetNotifications(record.UserId, query.Unread)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the notifications of user %v", record.UserId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, notifications)
+}
+
+// postReadNotificationsHandler marks all of the signed in user's notifications as read
+func postReadNotificationsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	if err := session.ReadNotifications(record.UserId); err != nil {
+		log.WithErr(err).Alert("Failed to mark the notifications of user %v as read", record.UserId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.Ok(c)
+}
@@ -0,0 +1,81 @@
+package registration
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/registration"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPut, "/leagues/:id/capacity", auth.ManagerOnly, putCapacityHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/seasons/:id/registration-window", auth.ManagerOnly, putRegistrationWindowHandler)
+}
+
+// putCapacityHandler sets how many registrations a league holds spots for, 0 for no limit. Spots
+// that open promote registrations off the waitlist straight away.
+func putCapacityHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	request := struct {
+		Capacity int `json:"capacity"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse capacity request payload")
+	}
+
+	session := db.GetSession(c)
+	league, err := session.SetLeagueCapacity(uint(leagueId), request.Capacity)
+	switch {
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to set the capacity of league %v", leagueId)
+		return responder.InternalServerError(c)
+	case league == nil:
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	return responder.OkWithData(c, league)
+}
+
+// putRegistrationWindowHandler sets when registration for a season opens and closes. Left out, it
+// opens straight away and closes when the season starts.
+func putRegistrationWindowHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	request := struct {
+		Opens  *time.Time `json:"registration_opens"`
+		Closes *time.Time `json:"registration_closes"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse registration window request payload")
+	}
+
+	session := db.GetSession(c)
+	season, err := session.SetRegistrationWindow(uint(seasonId), request.Opens, request.Closes)
+	switch {
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to set the registration window of season %v", seasonId)
+		return responder.InternalServerError(c)
+	case season == nil:
+		return responder.BadRequest(c, "Season %v does not exist", seasonId)
+	}
+
+	return responder.OkWithData(c, season)
+}
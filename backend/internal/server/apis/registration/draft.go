@@ -0,0 +1,135 @@
+package registration
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/draft"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/leagues/:id/drafts", auth.ManagerOnly, postDraftHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/drafts/:id", auth.ManagerOnly, getDraftHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/drafts/:id/picks/:user_id", auth.ManagerOnly, putDraftPickHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/drafts/:id/commit", auth.ManagerOnly, postCommitDraftHandler)
+}
+
+// postDraftHandler splits the players registered for a league, and not yet on one of its teams,
+// into balanced teams. Nothing is created until the draft is committed.
+func postDraftHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	request := struct {
+		Teams     int      `json:"teams"`
+		TeamNames []string `json:"team_names"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse draft request payload")
+	}
+
+	db := db.GetSession(c)
+	d, err := db.CreateDraft(uint(leagueId), request.Teams, request.TeamNames)
+	switch {
+	case draft.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to draft teams for league %v", leagueId)
+		return responder.InternalServerError(c)
+	case d == nil:
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	log.Info("Drafted %v players into %v teams for league %v", len(d.Picks), len(d.TeamNames), leagueId)
+	return responder.OkWithData(c, d)
+}
+
+func getDraftHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	draftId, err := c.ParamsInt("id")
+	if err != nil || draftId <= 0 {
+		return responder.BadRequest(c, "Invalid draft id")
+	}
+
+	db := db.GetSession(c)
+	d, err := db.GetDraft(uint(draftId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get draft %v", draftId)
+		return responder.InternalServerError(c)
+	}
+	if d == nil {
+		return responder.BadRequest(c, "Draft %v does not exist", draftId)
+	}
+
+	return responder.OkWithData(c, d)
+}
+
+// putDraftPickHandler moves a player to another of the draft's teams
+func putDraftPickHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	draftId, err := c.ParamsInt("id")
+	if err != nil || draftId <= 0 {
+		return responder.BadRequest(c, "Invalid draft id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+
+	request := struct {
+		Team *int `json:"team"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse draft pick payload")
+	}
+	if request.Team == nil {
+		return responder.BadRequest(c, "A team is required")
+	}
+
+	db := db.GetSession(c)
+	d, err := db.MoveDraftPick(uint(draftId), uint(userId), *request.Team)
+	switch {
+	case draft.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to move player %v in draft %v", userId, draftId)
+		return responder.InternalServerError(c)
+	case d == nil:
+		return responder.BadRequest(c, "Draft %v does not exist", draftId)
+	}
+
+	return responder.OkWithData(c, d)
+}
+
+// postCommitDraftHandler creates the draft's teams, each with a roster of its players
+func postCommitDraftHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	draftId, err := c.ParamsInt("id")
+	if err != nil || draftId <= 0 {
+		return responder.BadRequest(c, "Invalid draft id")
+	}
+
+	db := db.GetSession(c)
+	teams, err := db.CommitDraft(uint(draftId))
+	switch {
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequestWithData(c, eligibility.Violations(err), err.Error())
+	case draft.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to commit draft %v", draftId)
+		return responder.InternalServerError(c)
+	case teams == nil:
+		return responder.BadRequest(c, "Draft %v does not exist", draftId)
+	}
+
+	log.Info("Committed draft %v as %v teams", draftId, len(teams))
+	return responder.OkWithData(c, teams)
+}
@@ -0,0 +1,63 @@
+package registration
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/registration"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/seasons/:id/form", auth.Public, getFormHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/seasons/:id/form", auth.ManagerOnly, putFormHandler)
+}
+
+func getFormHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	db := db.GetSession(c)
+	form, err := db.GetForm(uint(seasonId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the registration form of season %v", seasonId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, form)
+}
+
+// putFormHandler replaces a season's registration form with the questions given, asked in order
+func putFormHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	questions := make([]models.FormQuestion, 0)
+	if err := c.BodyParser(&questions); err != nil {
+		return responder.BadRequest(c, "Failed to parse registration form payload")
+	}
+
+	session := db.GetSession(c)
+	form, err := session.SetForm(uint(seasonId), questions)
+	switch {
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to save the registration form of season %v", seasonId)
+		return responder.InternalServerError(c)
+	case form == nil:
+		return responder.BadRequest(c, "Season %v does not exist", seasonId)
+	}
+
+	log.Info("Season %v's registration form has %v questions", seasonId, len(form))
+	return responder.OkWithData(c, form)
+}
@@ -0,0 +1,242 @@
+package registration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/registration"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+	"github.com/lib/pq"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/registrations", auth.Authenticated, postRegistrationHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/registrations", auth.Authenticated, getMyRegistrationsHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/seasons/:id/registrations", auth.ManagerOnly, getRegistrationsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/registrations/:id/approve", auth.ManagerOnly, postApproveRegistrationHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/registrations/:id/reject", auth.ManagerOnly, postRejectRegistrationHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/registrations/:id/withdraw", auth.Authenticated, postWithdrawRegistrationHandler)
+}
+
+// postRegistrationHandler registers the signed in player for a league, with the position they play,
+// who they'd like to play with and their answers to the season's form. Players who break the
+// league's eligibility rules, or whose answers don't fit the form, are turned away with what's wrong.
+// The registration waits for a manager's approval, or on the waitlist when the league is full.
+func postRegistrationHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	request := struct {
+		SeasonID uint            `json:"season_id"`
+		LeagueID uint            `json:"league_id"`
+		Position models.Position `json:"position"`
+		PlayWith []uint          `json:"play_with"`
+		Answers  map[string]any  `json:"answers"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse registration request payload")
+	}
+	if request.SeasonID == 0 || request.LeagueID == 0 {
+		return responder.BadRequest(c, "A season_id and league_id are required")
+	}
+	if request.Position == "" {
+		request.Position = models.Skater
+	}
+	if request.Position != models.Skater && request.Position != models.Goalie {
+		return responder.BadRequest(c, "The position must be %v or %v", models.Skater, models.Goalie)
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	r := &models.Registration{
+		SeasonID: request.SeasonID,
+		LeagueID: request.LeagueID,
+		UserID:   record.UserId,
+		Position: request.Position,
+		PlayWith: make(pq.Int64Array, 0, len(request.PlayWith)),
+	}
+	for _, id := range request.PlayWith {
+		if id != record.UserId {
+			r.PlayWith = append(r.PlayWith, int64(id))
+		}
+	}
+	r, err := session.CreateRegistration(r, answers(request.Answers))
+	switch {
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequestWithData(c, eligibility.Violations(err), err.Error())
+	case errors.Is(err, registration.ErrInvalidAnswers):
+		return responder.BadRequestWithData(c, registration.Problems(err), err.Error())
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to register player %v for league %v", record.UserId, request.LeagueID)
+		return responder.InternalServerError(c)
+	}
+
+	log.Info("Player %v registered for league %v and is %v", r.UserID, r.LeagueID, r.Status)
+	return responder.OkWithData(c, r)
+}
+
+// answers reads form answers as text, so checkboxes and waivers can be answered with JSON booleans
+func answers(given map[string]any) map[string]string {
+	text := make(map[string]string, len(given))
+	for key, value := range given {
+		switch value := value.(type) {
+		case nil:
+			text[key] = ""
+		case string:
+			text[key] = value
+		default:
+			text[key] = fmt.Sprint(value)
+		}
+	}
+	return text
+}
+
+// getMyRegistrationsHandler lists the signed in player's registrations and where each one stands
+func getMyRegistrationsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	registrations, err := session.GetRegistrations(db.RegistrationFilter{UserID: record.UserId})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the registrations of player %v", record.UserId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, registrations)
+}
+
+// getRegistrationsHandler lists a season's registrations with each registrant's answers. They can
+// be narrowed to a league, a status, or to those who gave an answer to a question. A league's
+// pending registrations are its approval queue, and its waitlisted ones its waitlist, in turn.
+func getRegistrationsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	query := struct {
+		LeagueID uint   `query:"league_id"`
+		Status   string `query:"status"`
+		Question string `query:"question"`
+		Answer   string `query:"answer"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	filter := db.RegistrationFilter{
+		SeasonID: uint(seasonId),
+		LeagueID: query.LeagueID,
+		Status:   models.RegistrationStatus(query.Status),
+		Key:      query.Question,
+		Answer:   query.Answer,
+	}
+	switch filter.Status {
+	case "", models.RegistrationPending, models.RegistrationApproved, models.RegistrationWaitlisted, models.RegistrationRejected, models.RegistrationWithdrawn:
+	default:
+		return responder.BadRequest(c, "Invalid registration status %v", query.Status)
+	}
+	session := db.GetSession(c)
+	registrations, err := session.GetRegistrations(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the registrations of season %v", seasonId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, registrations)
+}
+
+// postApproveRegistrationHandler approves a registration waiting in its league's approval queue
+func postApproveRegistrationHandler(c *fiber.Ctx) error {
+	return reviewRegistration(c, true)
+}
+
+// postRejectRegistrationHandler rejects a registration, promoting the next one off the waitlist
+func postRejectRegistrationHandler(c *fiber.Ctx) error {
+	return reviewRegistration(c, false)
+}
+
+func reviewRegistration(c *fiber.Ctx, approve bool) error {
+	log := locals.Logger(c)
+	registrationId, err := c.ParamsInt("id")
+	if err != nil || registrationId <= 0 {
+		return responder.BadRequest(c, "Invalid registration id")
+	}
+
+	request := struct {
+		Note string `json:"note"`
+	}{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return responder.BadRequest(c, "Failed to parse review request payload")
+		}
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	r, err := session.ReviewRegistration(uint(registrationId), record.UserId, approve, request.Note)
+	switch {
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to review registration %v", registrationId)
+		return responder.InternalServerError(c)
+	case r == nil:
+		return responder.BadRequest(c, "Registration %v does not exist", registrationId)
+	}
+
+	log.Info("Registration %v is %v", r.ID, r.Status)
+	return responder.OkWithData(c, r)
+}
+
+// postWithdrawRegistrationHandler withdraws a registration for the registrant or a manager. The
+// spot it held goes to the next registration on the waitlist.
+func postWithdrawRegistrationHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	registrationId, err := c.ParamsInt("id")
+	if err != nil || registrationId <= 0 {
+		return responder.BadRequest(c, "Invalid registration id")
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	r, err := session.WithdrawRegistration(uint(registrationId), record)
+	switch {
+	case errors.Is(err, registration.ErrNotAllowed):
+		return responder.Forbidden(c, err.Error())
+	case registration.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to withdraw registration %v", registrationId)
+		return responder.InternalServerError(c)
+	case r == nil:
+		return responder.BadRequest(c, "Registration %v does not exist", registrationId)
+	}
+
+	log.Info("Registration %v was withdrawn by user %v", r.ID, record.UserId)
+	return responder.OkWithData(c, r)
+}
@@ -0,0 +1,42 @@
+package schedule
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/reconcile", auth.ManagerOnly, getReconcileHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/reconcile", auth.ManagerOnly, postReconcileHandler)
+}
+
+// getReconcileHandler reports games whose stored totals disagree with their events without changing them
+func getReconcileHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	db := db.GetSession(c)
+	discrepancies, err := db.ReconcileGameTotals(false)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to reconcile game totals")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, discrepancies)
+}
+
+// postReconcileHandler repairs games whose stored totals disagree with their events
+func postReconcileHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	db := db.GetSession(c)
+	discrepancies, err := db.ReconcileGameTotals(true)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to repair game totals")
+		return responder.InternalServerError(c)
+	}
+
+	log.Info("Repaired totals for %v games", len(discrepancies))
+	return responder.OkWithData(c, discrepancies)
+}
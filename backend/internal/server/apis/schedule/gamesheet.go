@@ -0,0 +1,200 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/printable"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/unsigned", auth.ManagerOnly, getUnsignedGamesHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/sheet", auth.Authenticated, getGameSheetHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/sheet/sign", auth.Authenticated, postSignGameSheetHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/sheet/reopen", auth.ManagerOnly, postReopenGameSheetHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/sheet/print", auth.Staff, getPrintableGameSheetHandler)
+}
+
+// getUnsignedGamesHandler reports final games that are still waiting on signatures
+func getUnsignedGamesHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	query := struct {
+		SeasonID uint `query:"season_id"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	db := db.GetSession(c)
+	sheets, err := db.GetUnsignedGames(query.SeasonID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get unsigned games")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, sheets)
+}
+
+func getGameSheetHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	db := db.GetSession(c)
+	sheet, err := db.GetGameSheet(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the sheet of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if sheet == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	return responder.OkWithData(c, sheet)
+}
+
+// postSignGameSheetHandler signs a final game's sheet as the signed in referee or captain. The
+// digest from the reviewed sheet has to be sent back so nobody signs a sheet that has changed.
+func postSignGameSheetHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	request := struct {
+		Digest string `json:"digest"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse request payload")
+	}
+	if request.Digest == "" {
+		return responder.BadRequest(c, "The digest of the reviewed game sheet is required")
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	sheet, err := session.SignGameSheet(uint(gameId), record.UserId, request.Digest)
+	switch {
+	case errors.Is(err, db.ErrGameLocked), errors.Is(err, db.ErrGameNotFinal), errors.Is(err, db.ErrGameSheetChanged):
+		return responder.BadRequest(c, err.Error())
+	case errors.Is(err, db.ErrNotASigner):
+		return responder.Forbidden(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to sign the sheet of game %v", gameId)
+		return responder.InternalServerError(c)
+	case sheet == nil:
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	return responder.OkWithData(c, sheet)
+}
+
+// postReopenGameSheetHandler unlocks a signed off game so it can be corrected and signed again
+func postReopenGameSheetHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	db := db.GetSession(c)
+	sheet, err := db.ReopenGameSheet(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to reopen the sheet of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if sheet == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	log.Info("Reopened the sheet of game %v", gameId)
+	return responder.OkWithData(c, sheet)
+}
+
+// getPrintableGameSheetHandler renders a game sheet for printing as HTML or PDF. With blank=true
+// the scoring and penalty tables are left empty for the penalty box to fill in by hand.
+func getPrintableGameSheetHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	query := struct {
+		Format string `query:"format"`
+		Blank  bool   `query:"blank"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+	format, err := printable.ParseFormat(query.Format)
+	if err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
+	session := db.GetSession(c)
+	game, err := session.GetGameDetails(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get game %v from the database", gameId)
+		return responder.InternalServerError(c)
+	}
+	if game == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	data := printable.GameSheetData{Game: game, Blank: query.Blank}
+	home, err := session.GetLineup(game.ID, game.HomeTeamID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the home lineup of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	away, err := session.GetLineup(game.ID, game.AwayTeamID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the away lineup of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	for _, player := range home.Players {
+		data.HomePlayers = append(data.HomePlayers, player.RosterMember)
+	}
+	for _, player := range away.Players {
+		data.AwayPlayers = append(data.AwayPlayers, player.RosterMember)
+	}
+
+	if !query.Blank {
+		filter := db.EventFilter{GameID: game.ID}
+		data.Goals, err = session.GetGoals(filter)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the goals of game %v", gameId)
+			return responder.InternalServerError(c)
+		}
+		data.Penalties, err = session.GetPenalties(filter)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the penalties of game %v", gameId)
+			return responder.InternalServerError(c)
+		}
+		data.Shots, err = session.GetShotsOnGoal(filter)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the shots of game %v", gameId)
+			return responder.InternalServerError(c)
+		}
+	}
+
+	doc := printable.GameSheet(data)
+	return responder.OkWithFile(c, format.ContentType(), fmt.Sprintf("game-%d.%s", game.ID, format), func(w io.Writer) error {
+		return printable.Render(w, doc, format)
+	})
+}
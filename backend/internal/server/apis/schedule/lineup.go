@@ -0,0 +1,151 @@
+package schedule
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/lineup"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/lineups/:team_id", auth.Public, getLineupHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/lineups/:team_id", auth.Authenticated, postLineupPlayerHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/games/:id/lineups/:team_id/:user_id", auth.Authenticated, putLineupPlayerHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/games/:id/lineups/:team_id/:user_id", auth.Authenticated, deleteLineupPlayerHandler)
+}
+
+// lineupPlayerRequest is a player dressed for a game. A null jersey number means none.
+type lineupPlayerRequest struct {
+	UserID       uint            `json:"user_id"`
+	JerseyNumber *int            `json:"jersey_number"`
+	Position     models.Position `json:"position"`
+}
+
+func (r lineupPlayerRequest) member() models.LineupMember {
+	return models.LineupMember{RosterMember: models.RosterMember{UserID: r.UserID, JerseyNumber: r.JerseyNumber, Position: r.Position}}
+}
+
+// getLineupHandler lists who is dressed for a team in a game. Until the captain edits it, the
+// lineup is the team's roster less players who declined or are suspended.
+func getLineupHandler(c *fiber.Ctx) error {
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+	teamId, err := c.ParamsInt("team_id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+
+	db := db.GetSession(c)
+	saved, err := db.GetLineup(uint(gameId), uint(teamId))
+	return lineupResponse(c, uint(gameId), saved, err)
+}
+
+// postLineupPlayerHandler dresses a player for the game, such as a sub from outside the roster
+func postLineupPlayerHandler(c *fiber.Ctx) error {
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+	teamId, err := c.ParamsInt("team_id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+
+	request := lineupPlayerRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse lineup request payload")
+	}
+	if request.UserID == 0 {
+		return responder.BadRequest(c, "A user_id is required")
+	}
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	saved, err := session.AddLineupPlayer(uint(gameId), uint(teamId), request.member(), record)
+	return lineupResponse(c, uint(gameId), saved, err)
+}
+
+// putLineupPlayerHandler sets the jersey number and position a player wears in the game
+func putLineupPlayerHandler(c *fiber.Ctx) error {
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+	teamId, err := c.ParamsInt("team_id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+
+	request := lineupPlayerRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse lineup request payload")
+	}
+	request.UserID = uint(userId)
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	saved, err := session.UpdateLineupPlayer(uint(gameId), uint(teamId), request.member(), record)
+	return lineupResponse(c, uint(gameId), saved, err)
+}
+
+func deleteLineupPlayerHandler(c *fiber.Ctx) error {
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+	teamId, err := c.ParamsInt("team_id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	saved, err := session.RemoveLineupPlayer(uint(gameId), uint(teamId), uint(userId), record)
+	return lineupResponse(c, uint(gameId), saved, err)
+}
+
+// lineupResponse reports the outcome of reading or changing a lineup
+func lineupResponse(c *fiber.Ctx, gameId uint, saved *models.Lineup, err error) error {
+	switch {
+	case errors.Is(err, lineup.ErrNotCaptain):
+		return responder.Forbidden(c, err.Error())
+	case errors.Is(err, db.ErrGameLocked):
+		return responder.BadRequest(c, err.Error())
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequestWithData(c, eligibility.Violations(err), err.Error())
+	case lineup.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		locals.Logger(c).WithErr(err).Alert("Failed to get or change a lineup for game %v", gameId)
+		return responder.InternalServerError(c)
+	case saved == nil:
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+	return responder.OkWithData(c, saved)
+}
@@ -1,9 +1,15 @@
 package schedule
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
 	"github.com/jak103/powerplay/internal/server/apis"
 	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/lineup"
+	"github.com/jak103/powerplay/internal/utils/locals"
 	"github.com/jak103/powerplay/internal/utils/responder"
 )
 
@@ -11,7 +17,44 @@ func init() {
 	apis.RegisterHandler(fiber.MethodPost, "/rsvp", auth.Authenticated, handleRsvp)
 }
 
+// handleRsvp records whether the signed in player will be at a game. Players who decline are left
+// out of their team's lineup until the captain edits it. RSVPs close at puck drop.
 func handleRsvp(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	request := struct {
+		GameID    uint  `json:"game_id"`
+		Attending *bool `json:"attending"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse RSVP request payload")
+	}
+	if request.GameID == 0 || request.Attending == nil {
+		return responder.BadRequest(c, "A game_id and attending are required")
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	game, err := session.GetGame(request.GameID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get game %v from the database", request.GameID)
+		return responder.InternalServerError(c)
+	}
+	if game == nil {
+		return responder.BadRequest(c, "Game %v does not exist", request.GameID)
+	}
+	if lineup.Locked(*game, time.Now()) {
+		return responder.BadRequest(c, "RSVPs for game %v closed at puck drop", request.GameID)
+	}
+
+	rsvp, err := session.SaveRsvp(&models.Rsvp{GameID: game.ID, UserID: record.UserId, Attending: *request.Attending})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to save the RSVP of user %v to game %v", record.UserId, game.ID)
+		return responder.InternalServerError(c)
+	}
 
-	return responder.NotYetImplemented(c)
+	return responder.OkWithData(c, rsvp)
 }
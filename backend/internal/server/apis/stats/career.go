@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/career"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+const defaultAllTimeLeaders = 10
+
+// allTimeLeaderSorts are the categories listed by the all-time leaders
+var allTimeLeaderSorts = []string{"points", "goals", "assists", "games_played", "penalty_minutes"}
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/players/:id/career", auth.Public, getPlayerCareerHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/stats/all-time-leaders", auth.Public, getAllTimeLeadersHandler)
+}
+
+// getPlayerCareerHandler returns a player's stat line for every team and season they've played
+// and their career totals
+func getPlayerCareerHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	playerId, err := c.ParamsInt("id")
+	if err != nil || playerId <= 0 {
+		return responder.BadRequest(c, "Invalid player id")
+	}
+
+	session := db.GetSession(c)
+	players, err := session.GetUsersByIds([]uint{uint(playerId)})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get player %v from the database", playerId)
+		return responder.InternalServerError(c)
+	}
+	if len(players) == 0 {
+		return responder.BadRequest(c, "Player %v does not exist", playerId)
+	}
+
+	seasons, err := session.GetCareerSeasons(uint(playerId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the career of player %v", playerId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, career.Career(players[0], seasons))
+}
+
+// getAllTimeLeadersHandler lists the leaders in each scoring category over every season of a
+// league, which are the leagues sharing league_correlation_id
+func getAllTimeLeadersHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	query := struct {
+		LeagueCorrelationID string `query:"league_correlation_id"`
+		Limit               int    `query:"limit"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+	if query.LeagueCorrelationID == "" {
+		return responder.BadRequest(c, "A league_correlation_id is required")
+	}
+	if query.Limit < 1 || query.Limit > maxPageSize {
+		query.Limit = defaultAllTimeLeaders
+	}
+
+	session := db.GetSession(c)
+	leaders := make(map[string][]models.PlayerStatLine, len(allTimeLeaderSorts))
+	for _, sort := range allTimeLeaderSorts {
+		players, _, err := session.GetPlayerStats(db.PlayerStatsFilter{
+			LeagueCorrelationID: query.LeagueCorrelationID,
+			Sort:                sort,
+			Descending:          true,
+			Limit:               query.Limit,
+		})
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the all-time %v leaders of league %v", sort, query.LeagueCorrelationID)
+			return responder.InternalServerError(c)
+		}
+		leaders[sort] = players
+	}
+
+	return responder.OkWithData(c, leaders)
+}
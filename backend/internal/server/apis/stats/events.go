@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+// eventFilter reads the game, team, player and period filters from the query string. On the
+// /games/:id routes the game comes from the path instead. When it returns a nil filter the
+// response has already been written.
+func eventFilter(c *fiber.Ctx) (*db.EventFilter, error) {
+	query := struct {
+		GameID   uint `query:"game_id"`
+		TeamID   uint `query:"team_id"`
+		PlayerID uint `query:"player_id"`
+		Period   uint `query:"period"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return nil, responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	filter := &db.EventFilter{
+		GameID:   query.GameID,
+		TeamID:   query.TeamID,
+		PlayerID: query.PlayerID,
+		Period:   query.Period,
+	}
+	if c.Params("id") != "" {
+		id, err := c.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return nil, responder.BadRequest(c, "Invalid game id")
+		}
+		filter.GameID = uint(id)
+	}
+	return filter, nil
+}
+
+// eventId reads the ID of the goal, penalty or shot in the request path
+func eventId(c *fiber.Ctx) (uint, bool) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// eventReason reads why an event is being corrected, voided or replayed from the request body,
+// where it's kept next to the event's fields. An empty body has no reason.
+func eventReason(c *fiber.Ctx) (string, error) {
+	request := struct {
+		Reason string `json:"reason"`
+	}{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return "", err
+		}
+	}
+	return request.Reason, nil
+}
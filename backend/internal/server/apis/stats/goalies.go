@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/events"
+	"github.com/jak103/powerplay/internal/server/services/goalies"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+const defaultMinGoalieGames = 3
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/goalies", auth.Staff, postGoalieChangeHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/goalies", auth.Public, getGameGoaliesHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/stats/goalies", auth.Public, getGoalieStatsHandler)
+}
+
+// postGoalieChangeHandler records a goalie taking the net. Send the starter at period 1, time 0,
+// and a goalie_id of 0 when the net is emptied.
+func postGoalieChangeHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	change := &models.GoalieChange{}
+	err = c.BodyParser(change)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse goalie change request payload")
+		return responder.BadRequest(c, "Failed to parse goalie change request payload")
+	}
+	change.GameID = uint(gameId)
+
+	game, err := loadEventGame(c, change.GameID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the goalie change's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidateGoalieChange(change, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid goalie change")
+	}
+
+	session := db.GetSession(c)
+	record, err := session.SaveGoalieChange(change)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to save goalie change")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, record)
+}
+
+func getGameGoaliesHandler(c *fiber.Ctx) error {
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	lines, err := goalieLines(c, db.GoalieStatsFilter{GameID: uint(gameId)}, 0)
+	if err != nil {
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, lines)
+}
+
+// getGoalieStatsHandler returns the goalie leaderboard. Supported query parameters are season_id,
+// league_id, min_games and sort (see goalies.Sorts).
+func getGoalieStatsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	query := struct {
+		SeasonID uint   `query:"season_id"`
+		LeagueID uint   `query:"league_id"`
+		MinGames *int   `query:"min_games"`
+		Sort     string `query:"sort"`
+	}{}
+
+	err := c.QueryParser(&query)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse goalie stats query")
+		return responder.BadRequest(c, "Failed to parse goalie stats query")
+	}
+
+	minGames := defaultMinGoalieGames
+	if query.MinGames != nil {
+		minGames = *query.MinGames
+	}
+	if query.Sort == "" {
+		query.Sort = "save_percentage"
+	}
+	if _, ok := goalies.Sorts[query.Sort]; !ok {
+		return responder.BadRequest(c, "Cannot sort goalies by %q", query.Sort)
+	}
+
+	lines, err := goalieLines(c, db.GoalieStatsFilter{SeasonID: query.SeasonID, LeagueID: query.LeagueID}, minGames)
+	if err != nil {
+		return responder.InternalServerError(c)
+	}
+
+	err = goalies.Sort(lines, query.Sort)
+	if err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+
+	return responder.OkWithData(c, lines)
+}
+
+// goalieLines computes goalie lines for the filtered games. A game filter gives one line per
+// goalie per game, anything else gives season totals for goalies with at least minGames.
+func goalieLines(c *fiber.Ctx, filter db.GoalieStatsFilter, minGames int) ([]models.GoalieStatLine, error) {
+	log := locals.Logger(c)
+	session := db.GetSession(c)
+
+	games, changes, counts, err := session.GetGoalieFacts(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get goalie stats from the database")
+		return nil, err
+	}
+
+	var lines []models.GoalieStatLine
+	if filter.GameID != 0 {
+		lines = make([]models.GoalieStatLine, 0)
+		for _, game := range games {
+			lines = append(lines, goalies.GameLines(game, changes, counts)...)
+		}
+	} else {
+		lines = goalies.SeasonLines(games, changes, counts, minGames)
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.GoalieID)
+	}
+	users, err := session.GetUsersByIds(ids)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get goalies from the database")
+		return nil, err
+	}
+
+	names := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		names[user.ID] = user
+	}
+	for i := range lines {
+		lines[i].FirstName = names[lines[i].GoalieID].FirstName
+		lines[i].LastName = names[lines[i].GoalieID].LastName
+	}
+
+	return lines, nil
+}
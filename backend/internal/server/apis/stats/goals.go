@@ -1,19 +1,24 @@
 package stats
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
 	"github.com/jak103/powerplay/internal/server/apis"
 	"github.com/jak103/powerplay/internal/server/services/auth"
-	"github.com/jak103/powerplay/internal/utils/responder"
-	"github.com/jak103/powerplay/internal/models"
-	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/services/events"
 	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
 )
 
-
 func init() {
-	apis.RegisterHandler(fiber.MethodPost, "/goals", auth.Public, postGoalsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/goals", auth.Staff, postGoalsHandler)
 	apis.RegisterHandler(fiber.MethodGet, "/goals", auth.Public, getGoalsHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/goals", auth.Public, getGoalsHandler)
+	apis.RegisterHandler(fiber.MethodPatch, "/goals/:id", auth.Staff, patchGoalHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/goals/:id", auth.Staff, deleteGoalHandler)
 }
 
 func postGoalsHandler(c *fiber.Ctx) error {
@@ -21,18 +26,27 @@ func postGoalsHandler(c *fiber.Ctx) error {
 	log.Debug("body: %q", c.Request().Body())
 	goalPostRequest := new(models.Goal)
 	err := c.BodyParser(goalPostRequest)
-	
+
 	// If valid structure in post request, continue on
-	if err != nil{
+	if err != nil {
 		log.WithErr(err).Error("Failed to parse Goal POST request.")
 		return err
 	}
 
+	game, err := loadEventGame(c, goalPostRequest.GameId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the goal's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidateGoal(goalPostRequest, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid goal")
+	}
+
 	// Connect to database and insert goal
 	db := db.GetSession(c)
 	record, err := db.SaveGoal(goalPostRequest)
-	
-	if err != nil{
+
+	if err != nil {
 		log.WithErr(err).Alert("Failed to parse goal request payload")
 		return responder.InternalServerError(c)
 	}
@@ -40,19 +54,110 @@ func postGoalsHandler(c *fiber.Ctx) error {
 	if record == nil {
 		return responder.BadRequest(c, "Could not post goal into database")
 	}
-	
+
 	return responder.Ok(c)
 
 }
 
 func getGoalsHandler(c *fiber.Ctx) error {
 	log := locals.Logger(c)
+	filter, err := eventFilter(c)
+	if err != nil || filter == nil {
+		return err
+	}
+
 	db := db.GetSession(c)
-	goals, err := db.GetGoals()
+	goals, err := db.GetGoals(*filter)
 	if err != nil {
-		log.WithErr(err).Alert("Failed to get all goals from the database")
+		log.WithErr(err).Alert("Failed to get goals from the database")
 		return err
 	}
 	// Send JSON response
 	return responder.OkWithData(c, goals)
-}
\ No newline at end of file
+}
+
+// patchGoalHandler corrects a recorded goal. Fields left out of the body keep their recorded
+// values; on_ice replaces every on ice player when it's given.
+func patchGoalHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid goal id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse goal request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	goal, err := session.GetGoal(id)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get goal %v from the database", id)
+		return responder.InternalServerError(c)
+	}
+	if goal == nil {
+		return responder.BadRequest(c, "Goal %v does not exist", id)
+	}
+
+	// on_ice is decoded into a new slice so the players given replace the recorded ones instead of
+	// being merged into them. Leaving it out keeps the recorded players.
+	recordedOnIce := goal.OnIce
+	goal.OnIce = nil
+	if err := c.BodyParser(goal); err != nil {
+		return responder.BadRequest(c, "Failed to parse goal request payload")
+	}
+	if goal.OnIce == nil {
+		goal.OnIce = recordedOnIce
+	}
+	goal.ID = id
+
+	game, err := loadEventGame(c, goal.GameId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the goal's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidateGoal(goal, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid goal")
+	}
+
+	record, err := session.UpdateGoal(goal)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to update goal %v", id)
+		return responder.InternalServerError(c)
+	}
+	if record == nil {
+		return responder.BadRequest(c, "Goal %v does not exist", id)
+	}
+
+	return responder.OkWithData(c, record)
+}
+
+func deleteGoalHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid goal id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	goal, err := session.DeleteGoal(id)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to delete goal %v", id)
+		return responder.InternalServerError(c)
+	}
+	if goal == nil {
+		return responder.BadRequest(c, "Goal %v does not exist", id)
+	}
+
+	return responder.OkWithData(c, goal)
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eventlog"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/history", auth.Authenticated, getHistoryHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/undo", auth.Staff, postUndoHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/redo", auth.Staff, postRedoHandler)
+}
+
+type history struct {
+	Entries  []models.EventLogEntry `json:"entries"`
+	NextUndo *models.EventLogEntry  `json:"next_undo"`
+	NextRedo *models.EventLogEntry  `json:"next_redo"`
+}
+
+// getHistoryHandler returns every change made to a game's goals, penalties and shots, oldest
+// first, along with what undo and redo would reverse next
+func getHistoryHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	db := db.GetSession(c)
+	entries, err := db.GetEventLog(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the event log of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+
+	result := history{Entries: entries}
+	undo, redo := eventlog.Stacks(entries)
+	if len(undo) > 0 {
+		result.NextUndo = &undo[len(undo)-1]
+	}
+	if len(redo) > 0 {
+		result.NextRedo = &redo[len(redo)-1]
+	}
+
+	return responder.OkWithData(c, result)
+}
+
+func postUndoHandler(c *fiber.Ctx) error {
+	return replayHandler(c, true)
+}
+
+func postRedoHandler(c *fiber.Ctx) error {
+	return replayHandler(c, false)
+}
+
+// replayHandler undoes the latest change to a game's events or redoes the latest undone one.
+// The body may carry a reason, which is kept in the event log.
+func replayHandler(c *fiber.Ctx, undo bool) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse request payload")
+	}
+
+	session := db.GetSession(c).WithReason(reason)
+	var entry *models.EventLogEntry
+	if undo {
+		entry, err = session.UndoEvent(uint(gameId))
+	} else {
+		entry, err = session.RedoEvent(uint(gameId))
+	}
+	if errors.Is(err, db.ErrEventLogOutOfSync) || errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to replay the event log of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if entry == nil {
+		if undo {
+			return responder.BadRequest(c, "There is nothing to undo")
+		}
+		return responder.BadRequest(c, "There is nothing to redo")
+	}
+
+	return responder.OkWithData(c, entry)
+}
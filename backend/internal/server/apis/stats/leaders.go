@@ -0,0 +1,93 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/scoring"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+const (
+	defaultPageSize = 25
+	maxPageSize     = 100
+)
+
+type playerStatsResponse struct {
+	Players  []models.PlayerStatLine `json:"players"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Total    int                     `json:"total"`
+}
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/stats/players", auth.Public, getPlayerStatsHandler)
+}
+
+// getPlayerStatsHandler returns a page of player scoring lines. Supported query parameters are
+// season_id, league_id, team_id, sort (see scoring.Sorts), order (asc or desc), min_games, page and
+// page_size.
+func getPlayerStatsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	query := struct {
+		SeasonID uint   `query:"season_id"`
+		LeagueID uint   `query:"league_id"`
+		TeamID   uint   `query:"team_id"`
+		Sort     string `query:"sort"`
+		Order    string `query:"order"`
+		MinGames int    `query:"min_games"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}{}
+
+	err := c.QueryParser(&query)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse player stats query")
+		return responder.BadRequest(c, "Failed to parse player stats query")
+	}
+
+	if query.Sort == "" {
+		query.Sort = "points"
+	}
+	if _, ok := scoring.Sorts[query.Sort]; !ok {
+		return responder.BadRequest(c, "Cannot sort players by %q", query.Sort)
+	}
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		return responder.BadRequest(c, "order must be asc or desc")
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > maxPageSize {
+		query.PageSize = defaultPageSize
+	}
+
+	filter := db.PlayerStatsFilter{
+		SeasonID:   query.SeasonID,
+		LeagueID:   query.LeagueID,
+		TeamID:     query.TeamID,
+		Sort:       query.Sort,
+		Descending: query.Order == "desc" || (query.Order == "" && query.Sort != "last_name"),
+		MinGames:   query.MinGames,
+		Limit:      query.PageSize,
+		Offset:     (query.Page - 1) * query.PageSize,
+	}
+
+	session := db.GetSession(c)
+	players, total, err := session.GetPlayerStats(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get player stats from the database")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, playerStatsResponse{
+		Players:  players,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	})
+}
@@ -1,11 +1,14 @@
 package stats
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/jak103/powerplay/internal/db"
 	"github.com/jak103/powerplay/internal/models"
 	"github.com/jak103/powerplay/internal/server/apis"
 	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/events"
 	"github.com/jak103/powerplay/internal/utils/locals"
 	"github.com/jak103/powerplay/internal/utils/log"
 	"github.com/jak103/powerplay/internal/utils/responder"
@@ -14,7 +17,10 @@ import (
 func init() {
 	apis.RegisterHandler(fiber.MethodGet, "/penaltyTypes", auth.Public, getPenaltyTypes)
 	apis.RegisterHandler(fiber.MethodGet, "/penalties", auth.Public, getPenaltiesHandler)
-	apis.RegisterHandler(fiber.MethodPost, "/penalties", auth.Public, postPenaltyHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/penalties", auth.Staff, postPenaltyHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/penalties", auth.Public, getPenaltiesHandler)
+	apis.RegisterHandler(fiber.MethodPatch, "/penalties/:id", auth.Staff, patchPenaltyHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/penalties/:id", auth.Staff, deletePenaltyHandler)
 }
 
 func getPenaltyTypes(c *fiber.Ctx) error {
@@ -31,10 +37,15 @@ func getPenaltyTypes(c *fiber.Ctx) error {
 
 func getPenaltiesHandler(c *fiber.Ctx) error {
 	log := locals.Logger(c)
+	filter, err := eventFilter(c)
+	if err != nil || filter == nil {
+		return err
+	}
+
 	db := db.GetSession(c)
-	penalties, err := db.GetPenalties()
+	penalties, err := db.GetPenalties(*filter)
 	if err != nil {
-		log.WithErr(err).Alert("Failed to get all penalties from the database")
+		log.WithErr(err).Alert("Failed to get penalties from the database")
 		return err
 	}
 
@@ -54,8 +65,22 @@ func postPenaltyHandler(c *fiber.Ctx) error {
 		return responder.BadRequest(c, "Failed to parse penalty request payload")
 	}
 
-	db := db.GetSession(c)
-	err = db.CreatePenalty(penaltyRequest)
+	session := db.GetSession(c)
+	penaltyType, err := session.GetPenaltyType(penaltyRequest.PenaltyTypeID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get penalty type %v", penaltyRequest.PenaltyTypeID)
+		return responder.InternalServerError(c)
+	}
+	game, err := loadEventGame(c, penaltyRequest.GameID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the penalty's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidatePenalty(penaltyRequest, penaltyType, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid penalty")
+	}
+
+	err = session.CreatePenalty(penaltyRequest)
 	if err != nil {
 		log.WithErr(err).Alert("Failed to save penalty request")
 		return responder.InternalServerError(c)
@@ -63,3 +88,88 @@ func postPenaltyHandler(c *fiber.Ctx) error {
 
 	return responder.Ok(c)
 }
+
+// patchPenaltyHandler corrects a recorded penalty. Fields left out of the body keep their
+// recorded values.
+func patchPenaltyHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid penalty id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse penalty request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	penalty, err := session.GetPenalty(id)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get penalty %v from the database", id)
+		return responder.InternalServerError(c)
+	}
+	if penalty == nil {
+		return responder.BadRequest(c, "Penalty %v does not exist", id)
+	}
+
+	if err := c.BodyParser(penalty); err != nil {
+		return responder.BadRequest(c, "Failed to parse penalty request payload")
+	}
+	penalty.ID = id
+
+	penaltyType, err := session.GetPenaltyType(penalty.PenaltyTypeID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get penalty type %v", penalty.PenaltyTypeID)
+		return responder.InternalServerError(c)
+	}
+	game, err := loadEventGame(c, penalty.GameID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the penalty's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidatePenalty(penalty, penaltyType, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid penalty")
+	}
+
+	record, err := session.UpdatePenalty(penalty)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to update penalty %v", id)
+		return responder.InternalServerError(c)
+	}
+	if record == nil {
+		return responder.BadRequest(c, "Penalty %v does not exist", id)
+	}
+	record.PenaltyType = *penaltyType
+
+	return responder.OkWithData(c, record)
+}
+
+func deletePenaltyHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid penalty id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	penalty, err := session.DeletePenalty(id)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to delete penalty %v", id)
+		return responder.InternalServerError(c)
+	}
+	if penalty == nil {
+		return responder.BadRequest(c, "Penalty %v does not exist", id)
+	}
+
+	return responder.OkWithData(c, penalty)
+}
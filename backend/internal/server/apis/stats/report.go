@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/goalies"
+	"github.com/jak103/powerplay/internal/server/services/printable"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+const defaultReportLeaders = 10
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/seasons/:id/report", auth.Public, getSeasonReportHandler)
+}
+
+// getSeasonReportHandler renders the end of season report as HTML or PDF: each league's final
+// standings and its scoring and goaltending leaders. leaders sets how many of each are listed.
+func getSeasonReportHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	seasonId, err := c.ParamsInt("id")
+	if err != nil || seasonId <= 0 {
+		return responder.BadRequest(c, "Invalid season id")
+	}
+
+	query := struct {
+		Format  string `query:"format"`
+		Leaders int    `query:"leaders"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+	format, err := printable.ParseFormat(query.Format)
+	if err != nil {
+		return responder.BadRequest(c, err.Error())
+	}
+	if query.Leaders < 1 || query.Leaders > maxPageSize {
+		query.Leaders = defaultReportLeaders
+	}
+
+	session := db.GetSession(c)
+	season, err := session.GetSeason(uint(seasonId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get season %v from the database", seasonId)
+		return responder.InternalServerError(c)
+	}
+	if season == nil {
+		return responder.BadRequest(c, "Season %v does not exist", seasonId)
+	}
+
+	leagues := make([]printable.LeagueReport, 0, len(season.Leagues))
+	for _, league := range season.Leagues {
+		report := printable.LeagueReport{League: league}
+
+		config, err := standings.ConfigFor(&league)
+		if err != nil {
+			return responder.BadRequest(c, "League %v has an invalid standings configuration: %v", league.ID, err)
+		}
+		results, err := session.GetFinalGameResults(league.ID)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get game results for league %v", league.ID)
+			return responder.InternalServerError(c)
+		}
+		report.Standings = standings.Compute(league.Teams, results, config)
+
+		report.Scorers, _, err = session.GetPlayerStats(db.PlayerStatsFilter{
+			LeagueID:   league.ID,
+			Sort:       "points",
+			Descending: true,
+			Limit:      query.Leaders,
+		})
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the scoring leaders of league %v", league.ID)
+			return responder.InternalServerError(c)
+		}
+
+		report.Goalies, err = goalieLines(c, db.GoalieStatsFilter{LeagueID: league.ID}, defaultMinGoalieGames)
+		if err != nil {
+			return responder.InternalServerError(c)
+		}
+		if err := goalies.Sort(report.Goalies, "save_percentage"); err != nil {
+			return responder.InternalServerError(c)
+		}
+		report.Goalies = report.Goalies[:min(len(report.Goalies), query.Leaders)]
+
+		leagues = append(leagues, report)
+	}
+
+	doc := printable.SeasonReport(*season, leagues)
+	return responder.OkWithFile(c, format.ContentType(), fmt.Sprintf("season-%d-report.%s", season.ID, format), func(w io.Writer) error {
+		return printable.Render(w, doc, format)
+	})
+}
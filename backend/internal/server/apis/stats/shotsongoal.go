@@ -1,18 +1,24 @@
 package stats
 
 import (
-	"github.com/jak103/powerplay/internal/server/apis"
-	"github.com/jak103/powerplay/internal/utils/locals"
-	"github.com/jak103/powerplay/internal/utils/responder"
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/jak103/powerplay/internal/db"
-	"github.com/jak103/powerplay/internal/server/services/auth"
 	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/events"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
 )
 
 func init() {
-	apis.RegisterHandler(fiber.MethodPost, "/shotsongoal", auth.Public, postShotsOnGoalHandler)
-	// Todo add register handler for GET
+	apis.RegisterHandler(fiber.MethodPost, "/shotsongoal", auth.Staff, postShotsOnGoalHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/shotsongoal", auth.Public, getShotsOnGoalHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/shotsongoal", auth.Public, getShotsOnGoalHandler)
+	apis.RegisterHandler(fiber.MethodPatch, "/shotsongoal/:id", auth.Staff, patchShotOnGoalHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/shotsongoal/:id", auth.Staff, deleteShotOnGoalHandler)
 }
 
 func postShotsOnGoalHandler(c *fiber.Ctx) error {
@@ -26,6 +32,15 @@ func postShotsOnGoalHandler(c *fiber.Ctx) error {
 		return err
 	}
 
+	game, err := loadEventGame(c, shotOnGoalRequest.GameId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the shot's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidateShot(shotOnGoalRequest, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid shot on goal")
+	}
+
 	db := db.GetSession(c)
 	record, err := db.SaveShotOnGoal(shotOnGoalRequest)
 
@@ -35,7 +50,105 @@ func postShotsOnGoalHandler(c *fiber.Ctx) error {
 	}
 
 	if record == nil {
-		return responder.BadRequest(c,"Could not Post shot on goal to database.")
+		return responder.BadRequest(c, "Could not Post shot on goal to database.")
 	}
-	return responder.Ok(c)	
-}
\ No newline at end of file
+	return responder.Ok(c)
+}
+
+// getShotsOnGoalHandler lists shots on goal. Shots aren't credited to a player, so only the
+// game, team and period filters apply.
+func getShotsOnGoalHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	filter, err := eventFilter(c)
+	if err != nil || filter == nil {
+		return err
+	}
+
+	db := db.GetSession(c)
+	shots, err := db.GetShotsOnGoal(*filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get shots on goal from the database")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, shots)
+}
+
+// patchShotOnGoalHandler corrects a recorded shot. Fields left out of the body keep their
+// recorded values.
+func patchShotOnGoalHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid shot on goal id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse shot on goal request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	shot, err := session.GetShotOnGoal(id)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get shot on goal %v from the database", id)
+		return responder.InternalServerError(c)
+	}
+	if shot == nil {
+		return responder.BadRequest(c, "Shot on goal %v does not exist", id)
+	}
+
+	if err := c.BodyParser(shot); err != nil {
+		return responder.BadRequest(c, "Failed to parse shot on goal request payload")
+	}
+	shot.ID = id
+
+	game, err := loadEventGame(c, shot.GameId)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the shot's game")
+		return responder.InternalServerError(c)
+	}
+	if errs := events.ValidateShot(shot, game); errs != nil {
+		return responder.BadRequestWithData(c, errs, "Invalid shot on goal")
+	}
+
+	record, err := session.UpdateShotOnGoal(shot)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to update shot on goal %v", id)
+		return responder.InternalServerError(c)
+	}
+	if record == nil {
+		return responder.BadRequest(c, "Shot on goal %v does not exist", id)
+	}
+
+	return responder.OkWithData(c, record)
+}
+
+func deleteShotOnGoalHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	id, ok := eventId(c)
+	if !ok {
+		return responder.BadRequest(c, "Invalid shot on goal id")
+	}
+
+	reason, err := eventReason(c)
+	if err != nil {
+		return responder.BadRequest(c, "Failed to parse request payload")
+	}
+	session := db.GetSession(c).WithReason(reason)
+	shot, err := session.DeleteShotOnGoal(id)
+	if errors.Is(err, db.ErrGameLocked) {
+		return responder.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		log.WithErr(err).Alert("Failed to delete shot on goal %v", id)
+		return responder.InternalServerError(c)
+	}
+	if shot == nil {
+		return responder.BadRequest(c, "Shot on goal %v does not exist", id)
+	}
+
+	return responder.OkWithData(c, shot)
+}
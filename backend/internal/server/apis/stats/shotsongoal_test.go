@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   db.EventFilter
+	}{
+		{"No filters", "/shotsongoal", fiber.StatusOK, db.EventFilter{}},
+		{"Query filters", "/shotsongoal?game_id=4&team_id=2&player_id=9&period=3", fiber.StatusOK, db.EventFilter{GameID: 4, TeamID: 2, PlayerID: 9, Period: 3}},
+		{"Game from the path", "/games/7/shotsongoal?game_id=4&period=1", fiber.StatusOK, db.EventFilter{GameID: 7, Period: 1}},
+		{"Invalid game in the path", "/games/abc/shotsongoal", fiber.StatusBadRequest, db.EventFilter{}},
+		{"Negative game in the path", "/games/-1/shotsongoal", fiber.StatusBadRequest, db.EventFilter{}},
+		{"Invalid query filter", "/shotsongoal?period=first", fiber.StatusBadRequest, db.EventFilter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got db.EventFilter
+			handler := func(c *fiber.Ctx) error {
+				filter, err := eventFilter(c)
+				if err != nil || filter == nil {
+					return err
+				}
+				got = *filter
+				return c.SendStatus(fiber.StatusOK)
+			}
+			app := fiber.New()
+			app.Get("/shotsongoal", handler)
+			app.Get("/games/:id/shotsongoal", handler)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShotOnGoalInvalidId(t *testing.T) {
+	app := fiber.New()
+	app.Patch("/shotsongoal/:id", patchShotOnGoalHandler)
+	app.Delete("/shotsongoal/:id", deleteShotOnGoalHandler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"Patch with a word", fiber.MethodPatch, "/shotsongoal/abc"},
+		{"Patch with zero", fiber.MethodPatch, "/shotsongoal/0"},
+		{"Delete with a word", fiber.MethodDelete, "/shotsongoal/abc"},
+		{"Delete with a negative id", fiber.MethodDelete, "/shotsongoal/-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var body struct {
+				Message string `json:"message"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, "Invalid shot on goal id", body.Message)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/powerplay"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/special-teams", auth.Public, getGameSpecialTeamsHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/stats/special-teams", auth.Public, getSpecialTeamsHandler)
+}
+
+// getGameSpecialTeamsHandler returns the man advantage windows, goal strengths and special teams lines of a game
+func getGameSpecialTeamsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	session := db.GetSession(c)
+	games, err := session.GetSpecialTeamsGames(db.SpecialTeamsFilter{GameID: uint(gameId)})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get special teams events for game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if len(games) == 0 {
+		return responder.BadRequest(c, "Game %v does not exist or has not started", gameId)
+	}
+
+	return responder.OkWithData(c, powerplay.Analyze(games[0]))
+}
+
+// getSpecialTeamsHandler returns each team's power play and penalty kill percentages. Supported
+// query parameters are season_id and league_id.
+func getSpecialTeamsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	query := struct {
+		SeasonID uint `query:"season_id"`
+		LeagueID uint `query:"league_id"`
+	}{}
+
+	err := c.QueryParser(&query)
+	if err != nil {
+		log.WithErr(err).Error("Failed to parse special teams query")
+		return responder.BadRequest(c, "Failed to parse special teams query")
+	}
+
+	session := db.GetSession(c)
+	games, err := session.GetSpecialTeamsGames(db.SpecialTeamsFilter{SeasonID: query.SeasonID, LeagueID: query.LeagueID})
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get special teams events")
+		return responder.InternalServerError(c)
+	}
+
+	lines := powerplay.Season(games)
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.TeamID)
+	}
+	teams, err := session.GetTeamsByIds(ids)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get teams from the database")
+		return responder.InternalServerError(c)
+	}
+
+	names := make(map[uint]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+	for i := range lines {
+		lines[i].TeamName = names[lines[i].TeamID]
+	}
+
+	return responder.OkWithData(c, lines)
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/offline"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/state", auth.Staff, getGameStateHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/games/:id/sync", auth.Staff, postSyncHandler)
+}
+
+func getGameStateHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	state, err := db.GetSession(c).GetGameState(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the state of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if state.Game == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	return responder.OkWithData(c, state)
+}
+
+// postSyncHandler applies a batch of operations queued on a scorekeeper's device, in order. Each
+// operation succeeds or fails on its own; the response reports every outcome along with the
+// authoritative state of the game for the device to replace its copy with.
+func postSyncHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	request := struct {
+		Operations []offline.Operation `json:"operations"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse sync request payload")
+	}
+	if len(request.Operations) > offline.MaxBatch {
+		return responder.BadRequest(c, "At most %v operations can be synced at once", offline.MaxBatch)
+	}
+
+	game, err := loadEventGame(c, uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	if game.Game == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	var userId uint
+	if record := locals.KeyRecord(c); record != nil {
+		userId = record.UserId
+	}
+
+	session := db.GetSession(c)
+	results := make([]offline.Result, 0, len(request.Operations))
+	for _, op := range request.Operations {
+		result := offline.Result{OpID: op.OpID, Kind: op.Kind, Action: op.Action, ClientID: op.ClientID}
+		if err := offline.Check(op); err != nil {
+			result.Outcome = offline.Invalid
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		applied, err := session.GetSyncOperation(game.Game.ID, op.OpID)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to look up sync operation %v", op.OpID)
+			return responder.InternalServerError(c)
+		}
+		if applied != nil {
+			result.Outcome = offline.Duplicate
+			result.EventID = applied.EventID
+			results = append(results, result)
+			continue
+		}
+		if game.Game.SignedOffAt != nil {
+			result.Outcome = offline.Conflict
+			result.Message = db.ErrGameLocked.Error()
+			results = append(results, result)
+			continue
+		}
+
+		result, err = session.ApplySyncOperation(op, &game, userId)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to apply sync operation %v", op.OpID)
+			return responder.InternalServerError(c)
+		}
+		results = append(results, result)
+	}
+
+	state, err := session.GetGameState(game.Game.ID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to load the state of game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, fiber.Map{
+		"results": results,
+		"state":   state,
+	})
+}
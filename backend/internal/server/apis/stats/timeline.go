@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/timeline"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/games/:id/timeline", auth.Public, getTimelineHandler)
+}
+
+// getTimelineHandler returns a game's goals, penalties and shots as one chronological play-by-play
+func getTimelineHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+
+	gameId, err := c.ParamsInt("id")
+	if err != nil || gameId <= 0 {
+		return responder.BadRequest(c, "Invalid game id")
+	}
+
+	session := db.GetSession(c)
+	game, err := session.GetGame(uint(gameId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get game %v from the database", gameId)
+		return responder.InternalServerError(c)
+	}
+	if game == nil {
+		return responder.BadRequest(c, "Game %v does not exist", gameId)
+	}
+
+	filter := db.EventFilter{GameID: game.ID}
+	goals, err := session.GetGoals(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get goals for game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	penalties, err := session.GetPenalties(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get penalties for game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+	shots, err := session.GetShotsOnGoal(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get shots on goal for game %v", gameId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, timeline.Build(game, goals, penalties, shots))
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/server/services/events"
+)
+
+// loadEventGame loads the game an event is posted against along with both teams' lineups and
+// which of their players are suspended for it
+func loadEventGame(c *fiber.Ctx, gameId uint) (events.Game, error) {
+	eventGame := events.Game{}
+	if gameId == 0 {
+		return eventGame, nil
+	}
+
+	session := db.GetSession(c)
+	game, err := session.GetGame(gameId)
+	if err != nil || game == nil {
+		return eventGame, err
+	}
+	eventGame.Game = game
+
+	eventGame.HomeLineup, err = session.GetLineupPlayerIds(game, game.HomeTeamID)
+	if err != nil {
+		return eventGame, err
+	}
+	eventGame.AwayLineup, err = session.GetLineupPlayerIds(game, game.AwayTeamID)
+	if err != nil {
+		return eventGame, err
+	}
+
+	// Suspended players are left out of lineups, so they're looked for on the rosters too, to
+	// report them as suspended rather than missing
+	home, err := session.GetRosterPlayerIds(game.HomeTeamRosterID)
+	if err != nil {
+		return eventGame, err
+	}
+	away, err := session.GetRosterPlayerIds(game.AwayTeamRosterID)
+	if err != nil {
+		return eventGame, err
+	}
+
+	players := slices.Concat(eventGame.HomeLineup, eventGame.AwayLineup, home, away)
+	eventGame.Suspended, err = session.GetSuspendedPlayerIds(gameId, players)
+	return eventGame, err
+}
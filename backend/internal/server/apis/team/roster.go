@@ -0,0 +1,126 @@
+package team
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/teams/:id/roster", auth.Public, getRosterHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/teams/:id/roster", auth.ManagerOnly, postRosterMemberHandler)
+	apis.RegisterHandler(fiber.MethodPut, "/teams/:id/roster/:user_id", auth.ManagerOnly, putRosterMemberHandler)
+	apis.RegisterHandler(fiber.MethodDelete, "/teams/:id/roster/:user_id", auth.ManagerOnly, deleteRosterMemberHandler)
+}
+
+// rosterMemberRequest is a player's place on a roster. A null jersey number means none.
+type rosterMemberRequest struct {
+	UserID       uint            `json:"user_id"`
+	JerseyNumber *int            `json:"jersey_number"`
+	Position     models.Position `json:"position"`
+	Captain      bool            `json:"captain"`
+}
+
+func (r rosterMemberRequest) member() models.RosterMember {
+	return models.RosterMember{UserID: r.UserID, JerseyNumber: r.JerseyNumber, Position: r.Position, Captain: r.Captain}
+}
+
+func getRosterHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	team, err := findTeam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	members := make([]models.RosterMember, 0)
+	if team.RosterID != 0 {
+		db := db.GetSession(c)
+		members, err = db.GetRosterMembers(team.RosterID)
+		if err != nil {
+			log.WithErr(err).Alert("Failed to get the roster of team %v", team.ID)
+			return responder.InternalServerError(c)
+		}
+	}
+
+	return responder.OkWithData(c, members)
+}
+
+// postRosterMemberHandler adds a player to a team's roster, optionally as captain. The roster
+// can't go over the league's limit and jersey numbers can't repeat.
+func postRosterMemberHandler(c *fiber.Ctx) error {
+	teamId, err := c.ParamsInt("id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+
+	request := rosterMemberRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse roster request payload")
+	}
+	if request.UserID == 0 {
+		return responder.BadRequest(c, "A user_id is required")
+	}
+
+	session := db.GetSession(c)
+	members, err := session.AddRosterMember(uint(teamId), request.member())
+	return rosterResponse(c, uint(teamId), members, err)
+}
+
+// putRosterMemberHandler sets a rostered player's jersey number, position and captaincy
+func putRosterMemberHandler(c *fiber.Ctx) error {
+	teamId, err := c.ParamsInt("id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+
+	request := rosterMemberRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse roster request payload")
+	}
+	request.UserID = uint(userId)
+
+	session := db.GetSession(c)
+	members, err := session.UpdateRosterMember(uint(teamId), request.member())
+	return rosterResponse(c, uint(teamId), members, err)
+}
+
+func deleteRosterMemberHandler(c *fiber.Ctx) error {
+	teamId, err := c.ParamsInt("id")
+	if err != nil || teamId <= 0 {
+		return responder.BadRequest(c, "Invalid team id")
+	}
+	userId, err := c.ParamsInt("user_id")
+	if err != nil || userId <= 0 {
+		return responder.BadRequest(c, "Invalid user id")
+	}
+
+	session := db.GetSession(c)
+	members, err := session.RemoveRosterMember(uint(teamId), uint(userId))
+	return rosterResponse(c, uint(teamId), members, err)
+}
+
+// rosterResponse reports the outcome of a roster change, with the roster after it
+func rosterResponse(c *fiber.Ctx, teamId uint, members []models.RosterMember, err error) error {
+	switch {
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequestWithData(c, eligibility.Violations(err), err.Error())
+	case roster.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		locals.Logger(c).WithErr(err).Alert("Failed to change the roster of team %v", teamId)
+		return responder.InternalServerError(c)
+	case members == nil:
+		return responder.BadRequest(c, "Team %v does not exist", teamId)
+	}
+	return responder.OkWithData(c, members)
+}
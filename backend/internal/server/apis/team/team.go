@@ -0,0 +1,206 @@
+package team
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodGet, "/leagues/:id/teams", auth.Public, getLeagueTeamsHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/leagues/:id/teams", auth.ManagerOnly, postTeamHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/teams/:id", auth.Public, getTeamHandler)
+	apis.RegisterHandler(fiber.MethodPatch, "/teams/:id", auth.ManagerOnly, patchTeamHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/teams/:id/archive", auth.ManagerOnly, postArchiveTeamHandler)
+}
+
+// teamRequest is the part of a team that can be set through the API
+type teamRequest struct {
+	Name          *string `json:"name"`
+	Color         *string `json:"color"`
+	LogoId        *string `json:"logo_id"`
+	CorrelationId string  `json:"correlation_id"` // Only on create, to continue a franchise from an earlier season
+}
+
+func getLeagueTeamsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	query := struct {
+		IncludeArchived bool `query:"include_archived"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	db := db.GetSession(c)
+	teams, err := db.GetLeagueTeams(uint(leagueId), query.IncludeArchived)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the teams of league %v", leagueId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, teams)
+}
+
+// postTeamHandler creates a team in a league with an empty roster
+func postTeamHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	leagueId, err := c.ParamsInt("id")
+	if err != nil || leagueId <= 0 {
+		return responder.BadRequest(c, "Invalid league id")
+	}
+
+	request := teamRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse team request payload")
+	}
+	if request.Name == nil || strings.TrimSpace(*request.Name) == "" {
+		return responder.BadRequest(c, "A team needs a name")
+	}
+
+	session := db.GetSession(c)
+	league, err := session.GetLeague(uint(leagueId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get league %v from the database", leagueId)
+		return responder.InternalServerError(c)
+	}
+	if league == nil {
+		return responder.BadRequest(c, "League %v does not exist", leagueId)
+	}
+
+	team := &models.Team{LeagueID: league.ID, CorrelationId: request.CorrelationId}
+	applyTeamRequest(team, request)
+	if nameTaken(league.Teams, team) {
+		return responder.BadRequest(c, "%v already has a team named %v", league.Name, team.Name)
+	}
+
+	team, err = session.CreateTeam(team)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to create a team in league %v", leagueId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, team)
+}
+
+func getTeamHandler(c *fiber.Ctx) error {
+	team, err := findTeam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	return responder.OkWithData(c, team)
+}
+
+// patchTeamHandler changes a team's name, color or logo. Fields left out keep their values.
+func patchTeamHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	team, err := findTeam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	request := teamRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse team request payload")
+	}
+	if request.Name != nil && strings.TrimSpace(*request.Name) == "" {
+		return responder.BadRequest(c, "A team needs a name")
+	}
+	applyTeamRequest(team, request)
+
+	session := db.GetSession(c)
+	teams, err := session.GetLeagueTeams(team.LeagueID, true)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the teams of league %v", team.LeagueID)
+		return responder.InternalServerError(c)
+	}
+	if nameTaken(teams, team) {
+		return responder.BadRequest(c, "%v already has a team named %v", team.League.Name, team.Name)
+	}
+
+	if err := session.UpdateTeam(team); err != nil {
+		log.WithErr(err).Alert("Failed to update team %v", team.ID)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, team)
+}
+
+// postArchiveTeamHandler archives a team that has folded or withdrawn. It stays in the league's
+// history, but its roster is frozen and it's left out of the league's team list.
+func postArchiveTeamHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	team, err := findTeam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	session := db.GetSession(c)
+	if err := session.ArchiveTeam(team.ID); err != nil {
+		log.WithErr(err).Alert("Failed to archive team %v", team.ID)
+		return responder.InternalServerError(c)
+	}
+
+	team, err = session.GetTeam(team.ID)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get team %v from the database", team.ID)
+		return responder.InternalServerError(c)
+	}
+
+	log.Info("Archived team %v", team.ID)
+	return responder.OkWithData(c, team)
+}
+
+// findTeam loads the team in the request path. When it returns a nil team the response has
+// already been written.
+func findTeam(c *fiber.Ctx) (*models.Team, error) {
+	log := locals.Logger(c)
+	teamId, err := c.ParamsInt("id")
+	if err != nil || teamId <= 0 {
+		return nil, responder.BadRequest(c, "Invalid team id")
+	}
+
+	db := db.GetSession(c)
+	team, err := db.GetTeam(uint(teamId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get team %v from the database", teamId)
+		return nil, responder.InternalServerError(c)
+	}
+	if team == nil {
+		return nil, responder.BadRequest(c, "Team %v does not exist", teamId)
+	}
+	return team, nil
+}
+
+func applyTeamRequest(team *models.Team, request teamRequest) {
+	if request.Name != nil {
+		team.Name = strings.TrimSpace(*request.Name)
+	}
+	if request.Color != nil {
+		team.Color = *request.Color
+	}
+	if request.LogoId != nil {
+		team.LogoId = *request.LogoId
+	}
+}
+
+// nameTaken reports whether another team in the league already has the team's name
+func nameTaken(teams []models.Team, team *models.Team) bool {
+	for _, other := range teams {
+		if other.ID != team.ID && strings.EqualFold(other.Name, team.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package team
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jak103/powerplay/internal/db"
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/apis"
+	"github.com/jak103/powerplay/internal/server/services/auth"
+	"github.com/jak103/powerplay/internal/server/services/eligibility"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"github.com/jak103/powerplay/internal/server/services/transfer"
+	"github.com/jak103/powerplay/internal/utils/locals"
+	"github.com/jak103/powerplay/internal/utils/responder"
+)
+
+func init() {
+	apis.RegisterHandler(fiber.MethodPost, "/transfers", auth.Authenticated, postTransferHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/transfers", auth.ManagerOnly, getTransfersHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/transfers/:id/approve", auth.ManagerOnly, postApproveTransferHandler)
+	apis.RegisterHandler(fiber.MethodPost, "/transfers/:id/reject", auth.ManagerOnly, postRejectTransferHandler)
+	apis.RegisterHandler(fiber.MethodGet, "/players/:id/teams", auth.Public, getPlayerTeamsHandler)
+}
+
+// postTransferHandler asks for a player to move teams. The player, a captain of either team or a
+// manager can ask; a manager then approves or rejects it.
+func postTransferHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	request := struct {
+		UserID      uint      `json:"user_id"`
+		FromTeamID  uint      `json:"from_team_id"`
+		ToTeamID    uint      `json:"to_team_id"`
+		EffectiveAt time.Time `json:"effective_at"`
+		Reason      string    `json:"reason"`
+	}{}
+	if err := c.BodyParser(&request); err != nil {
+		return responder.BadRequest(c, "Failed to parse transfer request payload")
+	}
+	if request.UserID == 0 || request.FromTeamID == 0 || request.ToTeamID == 0 {
+		return responder.BadRequest(c, "A user_id, from_team_id and to_team_id are required")
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	t := &models.Transfer{
+		UserID:      request.UserID,
+		FromTeamID:  request.FromTeamID,
+		ToTeamID:    request.ToTeamID,
+		EffectiveAt: request.EffectiveAt,
+		Reason:      request.Reason,
+	}
+	session := db.GetSession(c)
+	t, err := session.RequestTransfer(t, record)
+	switch {
+	case errors.Is(err, transfer.ErrNotAllowed):
+		return responder.Forbidden(c, err.Error())
+	case transfer.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to request a transfer of player %v", request.UserID)
+		return responder.InternalServerError(c)
+	}
+
+	log.Info("Player %v requested to transfer from team %v to team %v", t.UserID, t.FromTeamID, t.ToTeamID)
+	return responder.OkWithData(c, t)
+}
+
+func getTransfersHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	query := struct {
+		Status   string `query:"status"`
+		SeasonID uint   `query:"season_id"`
+		TeamID   uint   `query:"team_id"`
+		UserID   uint   `query:"user_id"`
+	}{}
+	if err := c.QueryParser(&query); err != nil {
+		return responder.BadRequest(c, "Invalid query parameters")
+	}
+
+	filter := db.TransferFilter{
+		Status:   models.TransferStatus(query.Status),
+		SeasonID: query.SeasonID,
+		TeamID:   query.TeamID,
+		UserID:   query.UserID,
+	}
+	switch filter.Status {
+	case "", models.TransferPending, models.TransferApproved, models.TransferRejected, models.TransferCompleted:
+	default:
+		return responder.BadRequest(c, "Invalid transfer status %v", query.Status)
+	}
+
+	db := db.GetSession(c)
+	transfers, err := db.GetTransfers(filter)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get transfers from the database")
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, transfers)
+}
+
+// postApproveTransferHandler approves a transfer. The player moves rosters straight away when its
+// effective date has come, or on that date otherwise.
+func postApproveTransferHandler(c *fiber.Ctx) error {
+	return reviewTransfer(c, true)
+}
+
+func postRejectTransferHandler(c *fiber.Ctx) error {
+	return reviewTransfer(c, false)
+}
+
+func reviewTransfer(c *fiber.Ctx, approve bool) error {
+	log := locals.Logger(c)
+	transferId, err := c.ParamsInt("id")
+	if err != nil || transferId <= 0 {
+		return responder.BadRequest(c, "Invalid transfer id")
+	}
+
+	request := struct {
+		Note string `json:"note"`
+	}{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return responder.BadRequest(c, "Failed to parse review request payload")
+		}
+	}
+
+	record := locals.KeyRecord(c)
+	if record == nil {
+		return responder.Unauthorized(c)
+	}
+
+	session := db.GetSession(c)
+	t, err := session.ReviewTransfer(uint(transferId), record.UserId, approve, request.Note)
+	switch {
+	case eligibility.IsRuleViolation(err):
+		return responder.BadRequestWithData(c, eligibility.Violations(err), err.Error())
+	case transfer.IsRuleViolation(err), roster.IsRuleViolation(err):
+		return responder.BadRequest(c, err.Error())
+	case err != nil:
+		log.WithErr(err).Alert("Failed to review transfer %v", transferId)
+		return responder.InternalServerError(c)
+	case t == nil:
+		return responder.BadRequest(c, "Transfer %v does not exist", transferId)
+	}
+
+	log.Info("Transfer %v is %v", t.ID, t.Status)
+	return responder.OkWithData(c, t)
+}
+
+// getPlayerTeamsHandler lists the teams a player has been rostered on and when
+func getPlayerTeamsHandler(c *fiber.Ctx) error {
+	log := locals.Logger(c)
+	playerId, err := c.ParamsInt("id")
+	if err != nil || playerId <= 0 {
+		return responder.BadRequest(c, "Invalid player id")
+	}
+
+	db := db.GetSession(c)
+	history, err := db.GetMemberships(uint(playerId))
+	if err != nil {
+		log.WithErr(err).Alert("Failed to get the team history of player %v", playerId)
+		return responder.InternalServerError(c)
+	}
+
+	return responder.OkWithData(c, history)
+}
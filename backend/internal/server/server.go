@@ -12,9 +12,15 @@ import (
 	// Blank imports for apis to cause init functions to run
 	_ "github.com/jak103/powerplay/internal/server/apis/auth"
 	_ "github.com/jak103/powerplay/internal/server/apis/chat"
+	_ "github.com/jak103/powerplay/internal/server/apis/discipline"
+	_ "github.com/jak103/powerplay/internal/server/apis/export"
+	_ "github.com/jak103/powerplay/internal/server/apis/importer"
 	_ "github.com/jak103/powerplay/internal/server/apis/league"
 	_ "github.com/jak103/powerplay/internal/server/apis/notifications"
+	_ "github.com/jak103/powerplay/internal/server/apis/registration"
+	_ "github.com/jak103/powerplay/internal/server/apis/schedule"
 	_ "github.com/jak103/powerplay/internal/server/apis/stats"
+	_ "github.com/jak103/powerplay/internal/server/apis/team"
 	_ "github.com/jak103/powerplay/internal/server/apis/user"
 	_ "github.com/jak103/powerplay/internal/server/apis/groups"
 
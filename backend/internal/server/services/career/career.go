@@ -0,0 +1,53 @@
+package career
+
+import "github.com/jak103/powerplay/internal/models"
+
+// Career totals a player's season lines into their career line
+func Career(player models.User, seasons []models.CareerSeason) models.PlayerCareer {
+	totals := models.PlayerStatLine{
+		PlayerID:  player.ID,
+		FirstName: player.FirstName,
+		LastName:  player.LastName,
+	}
+	for _, season := range seasons {
+		totals.GamesPlayed += season.GamesPlayed
+		totals.Goals += season.Goals
+		totals.Assists += season.Assists
+		totals.PenaltyMins += season.PenaltyMins
+		totals.PlusMinus += season.PlusMinus
+	}
+	totals.Points = totals.Goals + totals.Assists
+	if totals.GamesPlayed > 0 {
+		totals.PointsPerGame = float64(totals.Points) / float64(totals.GamesPlayed)
+	}
+
+	if seasons == nil {
+		seasons = make([]models.CareerSeason, 0)
+	}
+	return models.PlayerCareer{Totals: totals, Seasons: seasons}
+}
+
+// Franchise totals a franchise's seasons into its all-time record. Seasons counts distinct
+// seasons, so a franchise that somehow has two teams in one season isn't counted twice.
+func Franchise(correlationId string, seasons []models.FranchiseSeason) models.Franchise {
+	totals := models.FranchiseRecord{}
+	counted := map[uint]bool{}
+	for _, season := range seasons {
+		if !counted[season.SeasonID] {
+			counted[season.SeasonID] = true
+			totals.Seasons++
+		}
+		totals.Wins += season.Wins
+		totals.Losses += season.Losses
+		totals.Ties += season.Ties
+		totals.OvertimeLosses += season.OvertimeLosses
+		totals.GoalsFor += season.GoalsFor
+		totals.GoalsAgainst += season.GoalsAgainst
+		totals.Points += season.Points
+	}
+
+	if seasons == nil {
+		seasons = make([]models.FranchiseSeason, 0)
+	}
+	return models.Franchise{CorrelationId: correlationId, Seasons: seasons, Totals: totals}
+}
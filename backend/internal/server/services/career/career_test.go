@@ -0,0 +1,59 @@
+package career
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCareer(t *testing.T) {
+	player := models.User{DbModel: models.DbModel{ID: 7}, FirstName: "Ann", LastName: "Zed"}
+	seasons := []models.CareerSeason{
+		{SeasonID: 1, TeamID: 10, GamesPlayed: 10, Goals: 4, Assists: 6, Points: 10, PenaltyMins: 4, PlusMinus: 3},
+		{SeasonID: 2, TeamID: 20, GamesPlayed: 5, Goals: 1, Assists: 1, Points: 2, PenaltyMins: 2, PlusMinus: -4},
+		{SeasonID: 2, TeamID: 21, GamesPlayed: 5, Goals: 3, Assists: 0, Points: 3},
+	}
+
+	c := Career(player, seasons)
+	assert.Equal(t, models.PlayerStatLine{
+		PlayerID:      7,
+		FirstName:     "Ann",
+		LastName:      "Zed",
+		GamesPlayed:   20,
+		Goals:         8,
+		Assists:       7,
+		Points:        15,
+		PenaltyMins:   6,
+		PlusMinus:     -1,
+		PointsPerGame: 0.75,
+	}, c.Totals)
+	assert.Equal(t, seasons, c.Seasons)
+}
+
+func TestCareerWithoutGames(t *testing.T) {
+	c := Career(models.User{DbModel: models.DbModel{ID: 7}}, nil)
+	assert.Equal(t, 0.0, c.Totals.PointsPerGame)
+	assert.NotNil(t, c.Seasons)
+}
+
+func TestFranchise(t *testing.T) {
+	seasons := []models.FranchiseSeason{
+		{SeasonID: 1, Name: "Otters", Wins: 8, Losses: 2, GoalsFor: 40, GoalsAgainst: 20, Points: 16},
+		{SeasonID: 2, Name: "River Otters", Wins: 5, Losses: 4, Ties: 1, OvertimeLosses: 2, GoalsFor: 30, GoalsAgainst: 31, Points: 13},
+		{SeasonID: 2, Name: "River Otters II", Wins: 1},
+	}
+
+	f := Franchise("otters", seasons)
+	assert.Equal(t, "otters", f.CorrelationId)
+	assert.Equal(t, models.FranchiseRecord{
+		Seasons:        2,
+		Wins:           14,
+		Losses:         6,
+		Ties:           1,
+		OvertimeLosses: 2,
+		GoalsFor:       70,
+		GoalsAgainst:   51,
+		Points:         29,
+	}, f.Totals)
+}
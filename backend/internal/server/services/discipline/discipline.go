@@ -0,0 +1,60 @@
+package discipline
+
+import (
+	"fmt"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// Triggered returns the rules a player sets off by taking their count-th penalty of a severity
+// this season. A league's own rules for a severity replace the rules shared by every league.
+func Triggered(rules []models.DisciplineRule, leagueId uint, severity string, count int) []models.DisciplineRule {
+	shared := make([]models.DisciplineRule, 0)
+	own := make([]models.DisciplineRule, 0)
+	for _, rule := range rules {
+		if rule.Severity != severity {
+			continue
+		}
+		switch rule.LeagueID {
+		case leagueId:
+			own = append(own, rule)
+		case 0:
+			shared = append(shared, rule)
+		}
+	}
+
+	applicable := shared
+	if len(own) > 0 {
+		applicable = own
+	}
+
+	triggered := make([]models.DisciplineRule, 0)
+	for _, rule := range applicable {
+		if rule.Threshold > 0 && count > 0 && count%rule.Threshold == 0 {
+			triggered = append(triggered, rule)
+		}
+	}
+	return triggered
+}
+
+// Reason describes why a rule suspended a player
+func Reason(rule models.DisciplineRule, count int) string {
+	if rule.Threshold == 1 {
+		return fmt.Sprintf("%v-game suspension for a %v penalty", rule.Games, rule.Severity)
+	}
+	return fmt.Sprintf("%v-game suspension for %v %v penalties this season", rule.Games, count, rule.Severity)
+}
+
+// Validate checks a rule can be applied
+func Validate(rule models.DisciplineRule) error {
+	if rule.Severity == "" {
+		return fmt.Errorf("severity is required")
+	}
+	if rule.Threshold < 1 {
+		return fmt.Errorf("threshold must be at least 1")
+	}
+	if rule.Games < 1 {
+		return fmt.Errorf("games must be at least 1")
+	}
+	return nil
+}
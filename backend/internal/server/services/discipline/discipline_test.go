@@ -0,0 +1,41 @@
+package discipline
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggered(t *testing.T) {
+	rules := []models.DisciplineRule{
+		{Severity: "match", Threshold: 1, Games: 2},
+		{Severity: "game_misconduct", Threshold: 3, Games: 1},
+		{LeagueID: 7, Severity: "game_misconduct", Threshold: 2, Games: 3},
+	}
+
+	assert.Len(t, Triggered(rules, 1, "match", 1), 1)
+	assert.Len(t, Triggered(rules, 1, "match", 2), 1, "every match penalty suspends")
+	assert.Empty(t, Triggered(rules, 1, "minor", 1))
+
+	assert.Empty(t, Triggered(rules, 1, "game_misconduct", 2))
+	assert.Equal(t, 1, Triggered(rules, 1, "game_misconduct", 3)[0].Games)
+	assert.Equal(t, 1, Triggered(rules, 1, "game_misconduct", 6)[0].Games)
+
+	// League 7 replaces the shared game misconduct rule with its own
+	assert.Empty(t, Triggered(rules, 7, "game_misconduct", 3))
+	assert.Equal(t, 3, Triggered(rules, 7, "game_misconduct", 2)[0].Games)
+	assert.Len(t, Triggered(rules, 7, "match", 1), 1)
+}
+
+func TestReason(t *testing.T) {
+	assert.Equal(t, "2-game suspension for a match penalty", Reason(models.DisciplineRule{Severity: "match", Threshold: 1, Games: 2}, 1))
+	assert.Equal(t, "1-game suspension for 3 game_misconduct penalties this season", Reason(models.DisciplineRule{Severity: "game_misconduct", Threshold: 3, Games: 1}, 3))
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(models.DisciplineRule{Severity: "match", Threshold: 1, Games: 1}))
+	assert.NotNil(t, Validate(models.DisciplineRule{Threshold: 1, Games: 1}))
+	assert.NotNil(t, Validate(models.DisciplineRule{Severity: "match", Games: 1}))
+	assert.NotNil(t, Validate(models.DisciplineRule{Severity: "match", Threshold: 1}))
+}
@@ -0,0 +1,256 @@
+package draft
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+var (
+	ErrTooFewTeams  = errors.New("a draft needs at least two teams")
+	ErrNoPlayers    = errors.New("there are no registered players left to draft")
+	ErrTooManyTeams = errors.New("there are more teams than players")
+	ErrTeamNames    = errors.New("each team needs its own name")
+	ErrRosterLimit  = errors.New("the teams would go over the league's roster limit")
+	ErrUnknownTeam  = errors.New("the draft doesn't have that team")
+	ErrNotInDraft   = errors.New("the player isn't in the draft")
+	ErrCommitted    = errors.New("the draft has already been committed")
+	ErrNameTaken    = errors.New("a team in the league already has that name")
+)
+
+// TeamNames returns the names of a draft's teams, "Team 1", "Team 2" and so on unless names are given
+func TeamNames(teams int, names []string) ([]string, error) {
+	if teams < 2 {
+		return nil, ErrTooFewTeams
+	}
+	if len(names) == 0 {
+		names = make([]string, teams)
+		for i := range names {
+			names[i] = fmt.Sprintf("Team %d", i+1)
+		}
+		return names, nil
+	}
+	if len(names) != teams {
+		return nil, ErrTeamNames
+	}
+
+	trimmed := make([]string, 0, teams)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.ContainsFunc(trimmed, func(other string) bool { return strings.EqualFold(other, name) }) {
+			return nil, ErrTeamNames
+		}
+		trimmed = append(trimmed, name)
+	}
+	return trimmed, nil
+}
+
+// unit is a group of players who asked to play together, placed on a team as one
+type unit struct {
+	players []int // Indexes into the picks
+	goalies int
+	skill   int
+}
+
+// Generate splits players into balanced teams, setting the team of each pick. Goalies are spread
+// first so as many teams as possible get one. Players who asked to play together are kept together
+// when their group fits on one team. Everyone else is placed strongest first on the smallest, then
+// weakest, team, so teams end up close in both size and total skill.
+func Generate(picks []models.DraftPick, teams, limit int) error {
+	switch {
+	case teams < 2:
+		return ErrTooFewTeams
+	case len(picks) == 0:
+		return ErrNoPlayers
+	case len(picks) < teams:
+		return ErrTooManyTeams
+	}
+	size := (len(picks) + teams - 1) / teams
+	if size > limit {
+		return fmt.Errorf("%w, %d teams would need %d players each and the limit is %d", ErrRosterLimit, teams, size, limit)
+	}
+
+	units := groups(picks, size)
+	slices.SortFunc(units, func(a, b unit) int {
+		return cmp.Or(
+			cmp.Compare(b.goalies, a.goalies),
+			cmp.Compare(len(b.players), len(a.players)),
+			cmp.Compare(b.skill, a.skill),
+			cmp.Compare(picks[a.players[0]].UserID, picks[b.players[0]].UserID),
+		)
+	})
+
+	players, goalies, skill := make([]int, teams), make([]int, teams), make([]int, teams)
+	for _, u := range units {
+		best := -1
+		for t := 0; t < teams; t++ {
+			if best < 0 || better(u, t, best, players, goalies, skill, size) {
+				best = t
+			}
+		}
+		for _, i := range u.players {
+			picks[i].Team = best
+		}
+		players[best] += len(u.players)
+		goalies[best] += u.goalies
+		skill[best] += u.skill
+	}
+	return nil
+}
+
+// better reports whether team a is a better home for a unit than team b. Teams with room come
+// first; goalies go to the teams with the fewest goalies, then everyone goes to the smallest team
+// and finally the weakest.
+func better(u unit, a, b int, players, goalies, skill []int, size int) bool {
+	roomA, roomB := players[a]+len(u.players) <= size, players[b]+len(u.players) <= size
+	if roomA != roomB {
+		return roomA
+	}
+	if u.goalies > 0 && goalies[a] != goalies[b] {
+		return goalies[a] < goalies[b]
+	}
+	if players[a] != players[b] {
+		return players[a] < players[b]
+	}
+	return skill[a] < skill[b]
+}
+
+// groups joins players linked by play with requests, in either direction, into units of at most
+// size players. Larger groups are split in user ID order.
+func groups(picks []models.DraftPick, size int) []unit {
+	index := make(map[uint]int, len(picks))
+	for i, pick := range picks {
+		index[pick.UserID] = i
+	}
+
+	parent := make([]int, len(picks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, pick := range picks {
+		for _, with := range pick.PlayWith {
+			if j, ok := index[uint(with)]; ok {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	roots := make([]int, 0)
+	for i := range picks {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	units := make([]unit, 0, len(roots))
+	for _, root := range roots {
+		group := members[root]
+		slices.SortFunc(group, func(a, b int) int { return cmp.Compare(picks[a].UserID, picks[b].UserID) })
+		for len(group) > 0 {
+			n := min(size, len(group))
+			u := unit{players: group[:n]}
+			for _, i := range u.players {
+				u.skill += picks[i].SkillLevel
+				if picks[i].Position == models.Goalie {
+					u.goalies++
+				}
+			}
+			units = append(units, u)
+			group = group[n:]
+		}
+	}
+	return units
+}
+
+// Summarize sums up each of a draft's teams and lists the play with requests it doesn't honor.
+// Requests for players outside the draft are left out.
+func Summarize(draft models.Draft) ([]models.DraftTeam, []models.PlayWithRequest) {
+	teams := make([]models.DraftTeam, len(draft.TeamNames))
+	for i, name := range draft.TeamNames {
+		teams[i].Name = name
+	}
+
+	team := make(map[uint]int, len(draft.Picks))
+	for _, pick := range draft.Picks {
+		team[pick.UserID] = pick.Team
+		if pick.Team < 0 || pick.Team >= len(teams) {
+			continue
+		}
+		t := &teams[pick.Team]
+		t.Players++
+		t.SkillTotal += pick.SkillLevel
+		if pick.Position == models.Goalie {
+			t.Goalies++
+		}
+	}
+	for i := range teams {
+		if teams[i].Players > 0 {
+			teams[i].SkillAverage = float64(teams[i].SkillTotal) / float64(teams[i].Players)
+		}
+	}
+
+	apart := make([]models.PlayWithRequest, 0)
+	for _, pick := range draft.Picks {
+		for _, with := range pick.PlayWith {
+			if other, ok := team[uint(with)]; ok && other != pick.Team {
+				apart = append(apart, models.PlayWithRequest{UserID: pick.UserID, WithUserID: uint(with)})
+			}
+		}
+	}
+	return teams, apart
+}
+
+// CheckMove checks a player can be moved to one of a draft's teams
+func CheckMove(draft models.Draft, userId uint, team int) error {
+	switch {
+	case draft.CommittedAt != nil:
+		return ErrCommitted
+	case team < 0 || team >= len(draft.TeamNames):
+		return ErrUnknownTeam
+	case !slices.ContainsFunc(draft.Picks, func(pick models.DraftPick) bool { return pick.UserID == userId }):
+		return ErrNotInDraft
+	}
+	return nil
+}
+
+// CheckCommit checks a draft's teams can be created in a league with the given roster limit
+// and existing team names
+func CheckCommit(draft models.Draft, limit int, taken []string) error {
+	if draft.CommittedAt != nil {
+		return ErrCommitted
+	}
+	teams, _ := Summarize(draft)
+	for _, team := range teams {
+		if team.Players > limit {
+			return fmt.Errorf("%w, %v has %d players and the limit is %d", ErrRosterLimit, team.Name, team.Players, limit)
+		}
+		if slices.ContainsFunc(taken, func(name string) bool { return strings.EqualFold(name, team.Name) }) {
+			return fmt.Errorf("%w, %v", ErrNameTaken, team.Name)
+		}
+	}
+	return nil
+}
+
+// IsRuleViolation reports whether err is one of the draft rules rather than a failure
+func IsRuleViolation(err error) bool {
+	for _, rule := range []error{ErrTooFewTeams, ErrNoPlayers, ErrTooManyTeams, ErrTeamNames, ErrRosterLimit, ErrUnknownTeam, ErrNotInDraft, ErrCommitted, ErrNameTaken} {
+		if errors.Is(err, rule) {
+			return true
+		}
+	}
+	return false
+}
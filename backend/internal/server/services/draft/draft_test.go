@@ -0,0 +1,107 @@
+package draft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skaters(skills ...int) []models.DraftPick {
+	picks := make([]models.DraftPick, 0, len(skills))
+	for i, skill := range skills {
+		picks = append(picks, models.DraftPick{UserID: uint(i + 1), SkillLevel: skill, Position: models.Skater})
+	}
+	return picks
+}
+
+func TestTeamNames(t *testing.T) {
+	names, err := TeamNames(3, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Team 1", "Team 2", "Team 3"}, names)
+
+	names, err = TeamNames(2, []string{" Otters ", "Ravens"})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Otters", "Ravens"}, names)
+
+	_, err = TeamNames(2, []string{"Otters"})
+	assert.ErrorIs(t, err, ErrTeamNames)
+	_, err = TeamNames(2, []string{"Otters", "otters"})
+	assert.ErrorIs(t, err, ErrTeamNames)
+	_, err = TeamNames(1, nil)
+	assert.ErrorIs(t, err, ErrTooFewTeams)
+}
+
+func TestGenerateBalancesSkill(t *testing.T) {
+	picks := skaters(5, 5, 4, 4, 3, 3, 2, 2, 1, 1)
+	require.Nil(t, Generate(picks, 2, 20))
+
+	teams, _ := Summarize(models.Draft{TeamNames: pq.StringArray{"A", "B"}, Picks: picks})
+	assert.Equal(t, 5, teams[0].Players)
+	assert.Equal(t, 5, teams[1].Players)
+	assert.InDelta(t, teams[0].SkillTotal, teams[1].SkillTotal, 2)
+}
+
+func TestGenerateSpreadsGoalies(t *testing.T) {
+	picks := skaters(3, 3, 3, 3, 3, 3, 3, 3, 3)
+	picks[0].Position, picks[1].Position, picks[2].Position = models.Goalie, models.Goalie, models.Goalie
+	require.Nil(t, Generate(picks, 3, 20))
+
+	teams, _ := Summarize(models.Draft{TeamNames: pq.StringArray{"A", "B", "C"}, Picks: picks})
+	for _, team := range teams {
+		assert.Equal(t, 1, team.Goalies, team.Name)
+		assert.Equal(t, 3, team.Players, team.Name)
+	}
+}
+
+func TestGenerateKeepsFriendsTogether(t *testing.T) {
+	picks := skaters(5, 5, 1, 1, 3, 3)
+	picks[0].PlayWith = pq.Int64Array{2}
+	picks[2].PlayWith = pq.Int64Array{4, 99}
+	require.Nil(t, Generate(picks, 2, 20))
+
+	assert.Equal(t, picks[0].Team, picks[1].Team)
+	assert.Equal(t, picks[2].Team, picks[3].Team)
+	_, apart := Summarize(models.Draft{TeamNames: pq.StringArray{"A", "B"}, Picks: picks})
+	assert.Empty(t, apart, "requests for players outside the draft don't count")
+}
+
+func TestGenerateSplitsGroupsTooBigForATeam(t *testing.T) {
+	picks := skaters(3, 3, 3, 3)
+	picks[0].PlayWith = pq.Int64Array{2, 3, 4}
+	require.Nil(t, Generate(picks, 2, 20))
+
+	teams, apart := Summarize(models.Draft{TeamNames: pq.StringArray{"A", "B"}, Picks: picks})
+	assert.Equal(t, 2, teams[0].Players)
+	assert.Equal(t, 2, teams[1].Players)
+	assert.NotEmpty(t, apart)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	assert.ErrorIs(t, Generate(skaters(1, 2, 3), 1, 20), ErrTooFewTeams)
+	assert.ErrorIs(t, Generate(nil, 2, 20), ErrNoPlayers)
+	assert.ErrorIs(t, Generate(skaters(1), 2, 20), ErrTooManyTeams)
+	assert.ErrorIs(t, Generate(skaters(1, 2, 3, 4, 5), 2, 2), ErrRosterLimit)
+}
+
+func TestCheckMove(t *testing.T) {
+	draft := models.Draft{TeamNames: pq.StringArray{"A", "B"}, Picks: skaters(1, 2)}
+	assert.NoError(t, CheckMove(draft, 1, 1))
+	assert.ErrorIs(t, CheckMove(draft, 1, 2), ErrUnknownTeam)
+	assert.ErrorIs(t, CheckMove(draft, 9, 0), ErrNotInDraft)
+
+	now := time.Now()
+	draft.CommittedAt = &now
+	assert.ErrorIs(t, CheckMove(draft, 1, 1), ErrCommitted)
+}
+
+func TestCheckCommit(t *testing.T) {
+	draft := models.Draft{TeamNames: pq.StringArray{"Otters", "Ravens"}, Picks: skaters(1, 2, 3)}
+	draft.Picks[1].Team = 1
+	assert.NoError(t, CheckCommit(draft, 2, []string{"Bears"}))
+	assert.ErrorIs(t, CheckCommit(draft, 1, nil), ErrRosterLimit)
+	assert.ErrorIs(t, CheckCommit(draft, 2, []string{"OTTERS"}), ErrNameTaken)
+}
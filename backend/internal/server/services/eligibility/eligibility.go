@@ -0,0 +1,103 @@
+package eligibility
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+var (
+	ErrIneligible  = errors.New("the player isn't eligible for the league")
+	ErrUnknownRule = fmt.Errorf("the rule must be %v, %v or %v", models.MinAgeRule, models.MaxSkillLevelRule, models.OneTeamRule)
+)
+
+// Error lists the rules a player breaks. It matches ErrIneligible.
+type Error struct {
+	Violations []models.EligibilityViolation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return fmt.Sprintf("%v: %v", ErrIneligible, strings.Join(messages, "; "))
+}
+
+func (e *Error) Unwrap() error {
+	return ErrIneligible
+}
+
+// Player is a player as the rules see them
+type Player struct {
+	User       models.User
+	Teams      []string                 // Other teams in the league the player is rostered on
+	Exceptions []models.EligibilityRule // Rules a manager has let the player break
+}
+
+// Check lists the rules a player breaks in a league whose season starts on the given date
+func Check(rules models.EligibilityRules, player Player, seasonStart time.Time) []models.EligibilityViolation {
+	violations := make([]models.EligibilityViolation, 0)
+	broken := func(rule models.EligibilityRule, format string, args ...any) {
+		for _, excepted := range player.Exceptions {
+			if excepted == rule {
+				return
+			}
+		}
+		violations = append(violations, models.EligibilityViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if rules.MinAge > 0 {
+		if player.User.DateOfBirth.IsZero() {
+			broken(models.MinAgeRule, "players must be at least %d and the player's date of birth isn't on file", rules.MinAge)
+		} else if age := Age(player.User.DateOfBirth, seasonStart); age < rules.MinAge {
+			broken(models.MinAgeRule, "players must be at least %d when the season starts and the player will be %d", rules.MinAge, age)
+		}
+	}
+	if rules.MaxSkillLevel > 0 && player.User.SkillLevel > rules.MaxSkillLevel {
+		broken(models.MaxSkillLevelRule, "the league is for skill levels up to %d and the player's is %d", rules.MaxSkillLevel, player.User.SkillLevel)
+	}
+	if rules.OneTeam && len(player.Teams) > 0 {
+		broken(models.OneTeamRule, "players can only be on one team in the league and the player is on %v", strings.Join(player.Teams, ", "))
+	}
+	return violations
+}
+
+// Enforce returns an *Error when a player breaks any of a league's rules
+func Enforce(rules models.EligibilityRules, player Player, seasonStart time.Time) error {
+	if violations := Check(rules, player, seasonStart); len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+// Age is how old someone born on one date is on another
+func Age(born, on time.Time) int {
+	age := on.Year() - born.Year()
+	if on.Month() < born.Month() || (on.Month() == born.Month() && on.Day() < born.Day()) {
+		age--
+	}
+	return age
+}
+
+// ValidRule reports whether a rule exceptions can be made for exists
+func ValidRule(rule models.EligibilityRule) bool {
+	return rule == models.MinAgeRule || rule == models.MaxSkillLevelRule || rule == models.OneTeamRule
+}
+
+// Violations returns the rules an error from Enforce lists, or nil for other errors
+func Violations(err error) []models.EligibilityViolation {
+	var ineligible *Error
+	if errors.As(err, &ineligible) {
+		return ineligible.Violations
+	}
+	return nil
+}
+
+// IsRuleViolation reports whether err is one of the eligibility rules rather than a failure
+func IsRuleViolation(err error) bool {
+	return errors.Is(err, ErrIneligible) || errors.Is(err, ErrUnknownRule)
+}
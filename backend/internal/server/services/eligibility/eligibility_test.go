@@ -0,0 +1,64 @@
+package eligibility
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAge(t *testing.T) {
+	born := time.Date(2006, 9, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 17, Age(born, start))
+	assert.Equal(t, 18, Age(born, start.AddDate(0, 0, 1)))
+	assert.Equal(t, 18, Age(born, start.AddDate(0, 3, 0)))
+}
+
+func TestCheck(t *testing.T) {
+	rules := models.EligibilityRules{MinAge: 18, MaxSkillLevel: 3, OneTeam: true}
+	adult := models.User{DateOfBirth: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), SkillLevel: 3}
+
+	assert.Empty(t, Check(rules, Player{User: adult}, start))
+	assert.Empty(t, Check(models.EligibilityRules{}, Player{User: models.User{SkillLevel: 9}, Teams: []string{"Otters"}}, start), "leagues without rules take anyone")
+
+	junior := models.User{DateOfBirth: time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC), SkillLevel: 5}
+	violations := Check(rules, Player{User: junior, Teams: []string{"Otters"}}, start)
+	require.Len(t, violations, 3)
+	assert.Equal(t, models.MinAgeRule, violations[0].Rule)
+	assert.Contains(t, violations[0].Message, "will be 17")
+	assert.Equal(t, models.MaxSkillLevelRule, violations[1].Rule)
+	assert.Equal(t, models.OneTeamRule, violations[2].Rule)
+	assert.Contains(t, violations[2].Message, "Otters")
+
+	violations = Check(rules, Player{User: models.User{}}, start)
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "date of birth isn't on file")
+}
+
+func TestCheckWithExceptions(t *testing.T) {
+	rules := models.EligibilityRules{MinAge: 18, MaxSkillLevel: 3}
+	junior := models.User{DateOfBirth: time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC), SkillLevel: 5}
+
+	violations := Check(rules, Player{User: junior, Exceptions: []models.EligibilityRule{models.MinAgeRule}}, start)
+	require.Len(t, violations, 1)
+	assert.Equal(t, models.MaxSkillLevelRule, violations[0].Rule)
+}
+
+func TestEnforce(t *testing.T) {
+	rules := models.EligibilityRules{MaxSkillLevel: 3}
+	assert.NoError(t, Enforce(rules, Player{User: models.User{SkillLevel: 2}}, start))
+
+	err := Enforce(rules, Player{User: models.User{SkillLevel: 4}}, start)
+	assert.ErrorIs(t, err, ErrIneligible)
+	assert.True(t, IsRuleViolation(err))
+	assert.Len(t, Violations(err), 1)
+	assert.Contains(t, err.Error(), "skill levels up to 3")
+
+	assert.False(t, IsRuleViolation(errors.New("connection refused")))
+	assert.Nil(t, Violations(errors.New("connection refused")))
+}
@@ -0,0 +1,67 @@
+package eventlog
+
+import (
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// Stacks replays a game's log, oldest first, and returns the entries that can be undone and
+// redone. The next entry to undo or redo is the last of each. Any new change clears the redo
+// stack, the same as an editor's undo history.
+func Stacks(entries []models.EventLogEntry) (undo, redo []models.EventLogEntry) {
+	byId := make(map[uint]models.EventLogEntry, len(entries))
+	for _, entry := range entries {
+		byId[entry.ID] = entry
+	}
+
+	undo = make([]models.EventLogEntry, 0)
+	redo = make([]models.EventLogEntry, 0)
+	for _, entry := range entries {
+		switch entry.Action {
+		case models.EventUndone:
+			if len(undo) > 0 && entry.Reverts != nil && undo[len(undo)-1].ID == *entry.Reverts {
+				redo = append(redo, undo[len(undo)-1])
+				undo = undo[:len(undo)-1]
+			}
+		case models.EventRedone:
+			if len(redo) > 0 && entry.Reverts != nil && redo[len(redo)-1].ID == *entry.Reverts {
+				undo = append(undo, redo[len(redo)-1])
+				redo = redo[:len(redo)-1]
+			}
+		default:
+			undo = append(undo, entry)
+			redo = redo[:0]
+		}
+	}
+	return undo, redo
+}
+
+// Step is what undoing or redoing an entry does to its event: recreate it from a snapshot, put a
+// snapshot back over it, or void it
+type Step struct {
+	Action   models.EventLogAction // EventCreated, EventAmended or EventVoided
+	Snapshot []byte                // The event to recreate or restore
+}
+
+// UndoStep is how to reverse an entry
+func UndoStep(entry models.EventLogEntry) Step {
+	switch entry.Action {
+	case models.EventCreated:
+		return Step{Action: models.EventVoided}
+	case models.EventAmended:
+		return Step{Action: models.EventAmended, Snapshot: entry.Before}
+	default:
+		return Step{Action: models.EventCreated, Snapshot: entry.Before}
+	}
+}
+
+// RedoStep is how to apply an entry again after it was undone
+func RedoStep(entry models.EventLogEntry) Step {
+	switch entry.Action {
+	case models.EventCreated:
+		return Step{Action: models.EventCreated, Snapshot: entry.After}
+	case models.EventAmended:
+		return Step{Action: models.EventAmended, Snapshot: entry.After}
+	default:
+		return Step{Action: models.EventVoided}
+	}
+}
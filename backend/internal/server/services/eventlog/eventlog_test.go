@@ -0,0 +1,69 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func entry(id uint, action models.EventLogAction, reverts uint) models.EventLogEntry {
+	e := models.EventLogEntry{Action: action}
+	e.ID = id
+	if reverts != 0 {
+		e.Reverts = &reverts
+	}
+	return e
+}
+
+func ids(entries []models.EventLogEntry) []uint {
+	result := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.ID)
+	}
+	return result
+}
+
+func TestStacks(t *testing.T) {
+	log := []models.EventLogEntry{
+		entry(1, models.EventCreated, 0),
+		entry(2, models.EventAmended, 0),
+		entry(3, models.EventCreated, 0),
+	}
+	undo, redo := Stacks(log)
+	assert.Equal(t, []uint{1, 2, 3}, ids(undo))
+	assert.Empty(t, redo)
+
+	log = append(log, entry(4, models.EventUndone, 3), entry(5, models.EventUndone, 2))
+	undo, redo = Stacks(log)
+	assert.Equal(t, []uint{1}, ids(undo))
+	assert.Equal(t, []uint{3, 2}, ids(redo))
+
+	log = append(log, entry(6, models.EventRedone, 2))
+	undo, redo = Stacks(log)
+	assert.Equal(t, []uint{1, 2}, ids(undo))
+	assert.Equal(t, []uint{3}, ids(redo))
+
+	// A new change can't be followed by redoing something from before it
+	log = append(log, entry(7, models.EventVoided, 0))
+	undo, redo = Stacks(log)
+	assert.Equal(t, []uint{1, 2, 7}, ids(undo))
+	assert.Empty(t, redo)
+}
+
+func TestSteps(t *testing.T) {
+	before, after := json.RawMessage(`{"period":1}`), json.RawMessage(`{"period":2}`)
+
+	created := models.EventLogEntry{Action: models.EventCreated, After: after}
+	assert.Equal(t, Step{Action: models.EventVoided}, UndoStep(created))
+	assert.Equal(t, Step{Action: models.EventCreated, Snapshot: after}, RedoStep(created))
+
+	amended := models.EventLogEntry{Action: models.EventAmended, Before: before, After: after}
+	assert.Equal(t, Step{Action: models.EventAmended, Snapshot: before}, UndoStep(amended))
+	assert.Equal(t, Step{Action: models.EventAmended, Snapshot: after}, RedoStep(amended))
+
+	voided := models.EventLogEntry{Action: models.EventVoided, Before: before}
+	assert.Equal(t, Step{Action: models.EventCreated, Snapshot: before}, UndoStep(voided))
+	assert.Equal(t, Step{Action: models.EventVoided}, RedoStep(voided))
+}
@@ -0,0 +1,225 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// MaxPeriod allows a single overtime period after regulation
+const MaxPeriod = models.RegulationPeriods + 1
+
+// maxOnIce allows for five skaters plus an extra attacker when the goalie is pulled
+const maxOnIce = 6
+
+// FieldError is a problem with one field of a posted event
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors are all the problems found with an event. A nil Errors means the event is valid.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Field+": "+err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *Errors) add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Game is what an event is checked against. Game is nil when the event's game doesn't exist.
+type Game struct {
+	Game       *models.Game
+	HomeLineup []uint // Players dressed for each team
+	AwayLineup []uint
+	Suspended  []uint // Players suspended for the game
+}
+
+func (g Game) lineup(teamId uint) (map[uint]bool, bool) {
+	switch teamId {
+	case g.Game.HomeTeamID:
+		return set(g.HomeLineup), true
+	case g.Game.AwayTeamID:
+		return set(g.AwayLineup), true
+	}
+	return nil, false
+}
+
+// ValidateGoal checks a goal's game, team, scorer, assisters, time and on ice players
+func ValidateGoal(goal *models.Goal, game Game) Errors {
+	var errs Errors
+	if !checkGame(&errs, goal.GameId, goal.TeamId, game) {
+		return errs
+	}
+
+	lineup, _ := game.lineup(goal.TeamId)
+	suspended := set(game.Suspended)
+	checkPlayer(&errs, "user_id", goal.UserId, goal.TeamId, lineup, suspended, true)
+	checkPlayer(&errs, "assist1_id", goal.Assist1Id, goal.TeamId, lineup, suspended, false)
+	checkPlayer(&errs, "assist2_id", goal.Assist2Id, goal.TeamId, lineup, suspended, false)
+
+	if goal.Assist1Id != 0 && goal.Assist1Id == goal.UserId {
+		errs.add("assist1_id", "the scorer can't assist their own goal")
+	}
+	if goal.Assist2Id != 0 && goal.Assist2Id == goal.UserId {
+		errs.add("assist2_id", "the scorer can't assist their own goal")
+	}
+	if goal.Assist2Id != 0 && goal.Assist1Id == 0 {
+		errs.add("assist2_id", "a secondary assist needs a primary assist")
+	}
+	if goal.Assist2Id != 0 && goal.Assist2Id == goal.Assist1Id {
+		errs.add("assist2_id", "the same player can't be credited with both assists")
+	}
+
+	checkClock(&errs, "period", "duration", goal.Period, goal.Duration)
+	checkOnIce(&errs, goal, game, suspended)
+	return errs
+}
+
+// ValidatePenalty checks a penalty's game, team, player, type and time. penaltyType is nil when
+// the penalty's type doesn't exist.
+func ValidatePenalty(penalty *models.Penalty, penaltyType *models.PenaltyType, game Game) Errors {
+	var errs Errors
+	if penaltyType == nil {
+		errs.add("penalty_type_id", "penalty type %v does not exist", penalty.PenaltyTypeID)
+	}
+	if !checkGame(&errs, penalty.GameID, penalty.TeamID, game) {
+		return errs
+	}
+
+	lineup, _ := game.lineup(penalty.TeamID)
+	checkPlayer(&errs, "player_id", penalty.PlayerID, penalty.TeamID, lineup, set(game.Suspended), true)
+	checkClock(&errs, "period", "game_time", penalty.Period, penalty.GameTime)
+	return errs
+}
+
+// ValidateShot checks a shot's game, team and time
+func ValidateShot(shot *models.ShotOnGoal, game Game) Errors {
+	var errs Errors
+	if !checkGame(&errs, shot.GameId, shot.TeamId, game) {
+		return errs
+	}
+
+	checkClock(&errs, "period", "shot_time", shot.Period, shot.ShotTime)
+	return errs
+}
+
+// ValidateGoalieChange checks a goalie change's game, team, goalie and time. A goalie of 0 empties
+// the net.
+func ValidateGoalieChange(change *models.GoalieChange, game Game) Errors {
+	var errs Errors
+	if !checkGame(&errs, change.GameID, change.TeamID, game) {
+		return errs
+	}
+
+	lineup, _ := game.lineup(change.TeamID)
+	checkPlayer(&errs, "goalie_id", change.GoalieID, change.TeamID, lineup, set(game.Suspended), false)
+	checkClock(&errs, "period", "game_time", change.Period, change.GameTime)
+	return errs
+}
+
+// checkGame reports whether the game exists, is still open for changes and the team is playing
+// in it. Nothing else about an event can be checked without them.
+func checkGame(errs *Errors, gameId, teamId uint, game Game) bool {
+	if gameId == 0 {
+		errs.add("game_id", "is required")
+		return false
+	}
+	if game.Game == nil {
+		errs.add("game_id", "game %v does not exist", gameId)
+		return false
+	}
+	if game.Game.SignedOffAt != nil {
+		errs.add("game_id", "the sheet of game %v has been signed off and is locked", gameId)
+		return false
+	}
+	if _, ok := game.lineup(teamId); !ok {
+		errs.add("team_id", "team %v is not playing in game %v", teamId, gameId)
+		return false
+	}
+	return true
+}
+
+func checkPlayer(errs *Errors, field string, playerId, teamId uint, lineup, suspended map[uint]bool, required bool) {
+	switch {
+	case playerId == 0:
+		if required {
+			errs.add(field, "is required")
+		}
+	case suspended[playerId]:
+		errs.add(field, "player %v is suspended for this game", playerId)
+	case !lineup[playerId]:
+		errs.add(field, "player %v is not in the lineup of team %v", playerId, teamId)
+	}
+}
+
+func checkClock(errs *Errors, periodField, timeField string, period, elapsed uint) {
+	if period < 1 || period > MaxPeriod {
+		errs.add(periodField, "must be between 1 and %v", MaxPeriod)
+	}
+	if elapsed > models.PeriodLength {
+		errs.add(timeField, "must be at most %v seconds into the period", models.PeriodLength)
+	}
+}
+
+// checkOnIce checks the on ice players of a goal against the game's lineups. When the scoring
+// team's players are listed, the scorer and assisters must be among them.
+func checkOnIce(errs *Errors, goal *models.Goal, game Game, suspended map[uint]bool) {
+	onIce := make(map[uint]map[uint]bool)
+	for i, player := range goal.OnIce {
+		field := fmt.Sprintf("on_ice[%v]", i)
+		lineup, ok := game.lineup(player.TeamID)
+		if !ok {
+			errs.add(field, "team %v is not playing in game %v", player.TeamID, game.Game.ID)
+			continue
+		}
+		if suspended[player.PlayerID] {
+			errs.add(field, "player %v is suspended for this game", player.PlayerID)
+			continue
+		}
+		if !lineup[player.PlayerID] {
+			errs.add(field, "player %v is not in the lineup of team %v", player.PlayerID, player.TeamID)
+			continue
+		}
+
+		if onIce[player.TeamID] == nil {
+			onIce[player.TeamID] = make(map[uint]bool)
+		}
+		if onIce[player.TeamID][player.PlayerID] {
+			errs.add(field, "player %v is listed on the ice twice", player.PlayerID)
+			continue
+		}
+		onIce[player.TeamID][player.PlayerID] = true
+	}
+
+	for _, teamId := range []uint{game.Game.HomeTeamID, game.Game.AwayTeamID} {
+		if len(onIce[teamId]) > maxOnIce {
+			errs.add("on_ice", "team %v has more than %v players on the ice", teamId, maxOnIce)
+		}
+	}
+
+	scoring := onIce[goal.TeamId]
+	if len(scoring) == 0 {
+		return
+	}
+	for _, id := range []uint{goal.UserId, goal.Assist1Id, goal.Assist2Id} {
+		if id != 0 && !scoring[id] {
+			errs.add("on_ice", "player %v was credited with the goal but is not listed on the ice", id)
+		}
+	}
+}
+
+func set(ids []uint) map[uint]bool {
+	s := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		s[id] = true
+	}
+	return s
+}
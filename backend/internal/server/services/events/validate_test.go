@@ -0,0 +1,134 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testGame = Game{
+	Game:       &models.Game{DbModel: models.DbModel{ID: 5}, HomeTeamID: 1, AwayTeamID: 2},
+	HomeLineup: []uint{10, 11, 12, 13, 14, 15, 16},
+	AwayLineup: []uint{20, 21},
+	Suspended:  []uint{16},
+}
+
+func fields(errs Errors) []string {
+	result := make([]string, 0, len(errs))
+	for _, err := range errs {
+		result = append(result, err.Field)
+	}
+	return result
+}
+
+func TestValidateGoal(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input models.Goal
+		want  []string
+	}{
+		{"Valid goal", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Assist1Id: 11, Assist2Id: 12, Period: 2, Duration: 600}, []string{}},
+		{"Overtime goal", models.Goal{GameId: 5, TeamId: 2, UserId: 20, Period: 4, Duration: 30}, []string{}},
+		{"Missing game", models.Goal{TeamId: 1, UserId: 10, Period: 1}, []string{"game_id"}},
+		{"Team not in game", models.Goal{GameId: 5, TeamId: 3, UserId: 10, Period: 1}, []string{"team_id"}},
+		{"Missing scorer", models.Goal{GameId: 5, TeamId: 1, Period: 1}, []string{"user_id"}},
+		{"Scorer on the other team", models.Goal{GameId: 5, TeamId: 1, UserId: 20, Period: 1}, []string{"user_id"}},
+		{"Suspended scorer", models.Goal{GameId: 5, TeamId: 1, UserId: 16, Period: 1}, []string{"user_id"}},
+		{"Scorer assists own goal", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Assist1Id: 10, Period: 1}, []string{"assist1_id"}},
+		{"Same player on both assists", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Assist1Id: 11, Assist2Id: 11, Period: 1}, []string{"assist2_id"}},
+		{"Secondary without primary", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Assist2Id: 11, Period: 1}, []string{"assist2_id"}},
+		{"Bad clock", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Period: 0, Duration: models.PeriodLength + 1}, []string{"period", "duration"}},
+		{"Period after overtime", models.Goal{GameId: 5, TeamId: 1, UserId: 10, Period: MaxPeriod + 1}, []string{"period"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := testGame
+			if tt.input.GameId != 5 {
+				game.Game = nil
+			}
+			assert.Equal(t, tt.want, fields(ValidateGoal(&tt.input, game)))
+		})
+	}
+
+	errs := ValidateGoal(&models.Goal{GameId: 9, TeamId: 1, UserId: 10, Period: 1}, Game{})
+	assert.Equal(t, "game_id: game 9 does not exist", errs.Error())
+}
+
+func TestValidateGoalOnIce(t *testing.T) {
+	onIce := func(players ...models.GoalOnIce) models.Goal {
+		return models.Goal{GameId: 5, TeamId: 1, UserId: 10, Assist1Id: 11, Period: 1, OnIce: players}
+	}
+
+	var tests = []struct {
+		name  string
+		input models.Goal
+		want  string
+	}{
+		{"Scorer and assisters on ice is valid", onIce(models.GoalOnIce{TeamID: 1, PlayerID: 10}, models.GoalOnIce{TeamID: 1, PlayerID: 11}, models.GoalOnIce{TeamID: 2, PlayerID: 20}), ""},
+		{"Only the defending team listed is valid", onIce(models.GoalOnIce{TeamID: 2, PlayerID: 21}), ""},
+		{"Team not in game", onIce(models.GoalOnIce{TeamID: 3, PlayerID: 10}), "on_ice[0]: team 3 is not playing in game 5"},
+		{"Player not on roster", onIce(models.GoalOnIce{TeamID: 2, PlayerID: 10}), "on_ice[0]: player 10 is not in the lineup of team 2"},
+		{"Suspended player", onIce(models.GoalOnIce{TeamID: 1, PlayerID: 16}), "on_ice[0]: player 16 is suspended for this game"},
+		{"Player listed twice", onIce(models.GoalOnIce{TeamID: 2, PlayerID: 20}, models.GoalOnIce{TeamID: 2, PlayerID: 20}), "on_ice[1]: player 20 is listed on the ice twice"},
+		{"Assister not on ice", onIce(models.GoalOnIce{TeamID: 1, PlayerID: 10}), "on_ice: player 11 was credited with the goal but is not listed on the ice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateGoal(&tt.input, testGame)
+			if tt.want == "" {
+				assert.Nil(t, errs)
+			} else {
+				assert.Equal(t, tt.want, errs.Error())
+			}
+		})
+	}
+
+	crowded := onIce()
+	for _, id := range []uint{10, 11, 12, 13, 14, 15} {
+		crowded.OnIce = append(crowded.OnIce, models.GoalOnIce{TeamID: 1, PlayerID: id})
+	}
+	assert.Nil(t, ValidateGoal(&crowded, testGame))
+	crowded.OnIce = append(crowded.OnIce, models.GoalOnIce{TeamID: 2, PlayerID: 20})
+	assert.Nil(t, ValidateGoal(&crowded, Game{Game: testGame.Game, HomeLineup: testGame.HomeLineup, AwayLineup: testGame.AwayLineup}))
+	crowded.OnIce = append(crowded.OnIce, models.GoalOnIce{TeamID: 1, PlayerID: 16})
+	assert.Equal(t, "on_ice: team 1 has more than 6 players on the ice", ValidateGoal(&crowded, Game{Game: testGame.Game, HomeLineup: testGame.HomeLineup, AwayLineup: testGame.AwayLineup}).Error())
+}
+
+func TestValidatePenalty(t *testing.T) {
+	penaltyType := &models.PenaltyType{Severity: "minor", Duration: 2}
+
+	assert.Nil(t, ValidatePenalty(&models.Penalty{GameID: 5, TeamID: 2, PlayerID: 21, Period: 3, GameTime: 1199}, penaltyType, testGame))
+	assert.Equal(t, []string{"penalty_type_id"}, fields(ValidatePenalty(&models.Penalty{GameID: 5, TeamID: 2, PlayerID: 21, Period: 1}, nil, testGame)))
+	assert.Equal(t, []string{"player_id"}, fields(ValidatePenalty(&models.Penalty{GameID: 5, TeamID: 2, PlayerID: 10, Period: 1}, penaltyType, testGame)))
+	assert.Equal(t, []string{"player_id"}, fields(ValidatePenalty(&models.Penalty{GameID: 5, TeamID: 1, PlayerID: 16, Period: 1}, penaltyType, testGame)))
+	assert.Equal(t, []string{"game_time"}, fields(ValidatePenalty(&models.Penalty{GameID: 5, TeamID: 1, PlayerID: 10, Period: 1, GameTime: 5000}, penaltyType, testGame)))
+}
+
+func TestValidateShot(t *testing.T) {
+	assert.Nil(t, ValidateShot(&models.ShotOnGoal{GameId: 5, TeamId: 1, Period: 3, ShotTime: 600}, testGame))
+	assert.Equal(t, []string{"team_id"}, fields(ValidateShot(&models.ShotOnGoal{GameId: 5, TeamId: 4}, testGame)))
+	assert.Equal(t, []string{"shot_time"}, fields(ValidateShot(&models.ShotOnGoal{GameId: 5, TeamId: 2, Period: 1, ShotTime: models.PeriodLength + 1}, testGame)))
+	assert.Equal(t, []string{"period"}, fields(ValidateShot(&models.ShotOnGoal{GameId: 5, TeamId: 2, Period: MaxPeriod + 1}, testGame)))
+}
+
+func TestValidateGoalieChange(t *testing.T) {
+	assert.Nil(t, ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 2, GoalieID: 20, Period: 1}, testGame))
+	assert.Nil(t, ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 2, Period: 3, GameTime: 1140}, testGame), "a goalie of 0 empties the net")
+	assert.Equal(t, []string{"team_id"}, fields(ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 4, GoalieID: 20, Period: 1}, testGame)))
+	assert.Equal(t, []string{"goalie_id"}, fields(ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 2, GoalieID: 10, Period: 1}, testGame)))
+	assert.Equal(t, []string{"goalie_id"}, fields(ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 1, GoalieID: 16, Period: 1}, testGame)))
+	assert.Equal(t, []string{"period", "game_time"}, fields(ValidateGoalieChange(&models.GoalieChange{GameID: 5, TeamID: 1, GoalieID: 10, GameTime: 5000}, testGame)))
+}
+
+func TestValidateLockedGame(t *testing.T) {
+	signedOff := time.Now()
+	locked := testGame
+	locked.Game = &models.Game{DbModel: models.DbModel{ID: 5}, HomeTeamID: 1, AwayTeamID: 2, SignedOffAt: &signedOff}
+
+	assert.Equal(t, []string{"game_id"}, fields(ValidateShot(&models.ShotOnGoal{GameId: 5, TeamId: 1, Period: 1, ShotTime: 30}, locked)))
+	assert.Equal(t, []string{"game_id"}, fields(ValidateGoal(&models.Goal{GameId: 5, TeamId: 1, UserId: 10, Period: 1}, locked)))
+}
@@ -0,0 +1,265 @@
+package export
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+)
+
+// startLayout is how game start times are written. Spreadsheets recognize it as a date and time.
+const startLayout = "2006-01-02 15:04"
+
+var ScheduleColumns = []Column{
+	{Name: "Game ID", Numeric: true},
+	{Name: "Start"},
+	{Name: "Status"},
+	{Name: "League"},
+	{Name: "Home"},
+	{Name: "Away"},
+	{Name: "Venue"},
+	{Name: "Home Score", Numeric: true},
+	{Name: "Away Score", Numeric: true},
+}
+
+func ScheduleRecord(row models.ScheduleExportRow) []string {
+	return []string{
+		itoa(row.GameID),
+		start(row.Start),
+		row.Status,
+		row.League,
+		row.HomeTeam,
+		row.AwayTeam,
+		row.Venue,
+		strconv.Itoa(row.HomeScore),
+		strconv.Itoa(row.AwayScore),
+	}
+}
+
+var RosterColumns = []Column{
+	{Name: "League"},
+	{Name: "Team ID", Numeric: true},
+	{Name: "Team"},
+	{Name: "Player ID", Numeric: true},
+	{Name: "First Name"},
+	{Name: "Last Name"},
+	{Name: "Email"},
+	{Name: "Phone"},
+	{Name: "Jersey", Numeric: true},
+	{Name: "Position"},
+	{Name: "Captain"},
+}
+
+func RosterRecord(row models.RosterExportRow) []string {
+	return []string{
+		row.League,
+		itoa(row.TeamID),
+		row.Team,
+		itoa(row.PlayerID),
+		row.FirstName,
+		row.LastName,
+		row.Email,
+		row.Phone,
+		jersey(row.Jersey),
+		string(row.Position),
+		yesNo(row.Captain),
+	}
+}
+
+var GoalColumns = []Column{
+	{Name: "Goal ID", Numeric: true},
+	{Name: "Game ID", Numeric: true},
+	{Name: "Start"},
+	{Name: "Team"},
+	{Name: "Period", Numeric: true},
+	{Name: "Time"},
+	{Name: "Scorer"},
+	{Name: "Assist"},
+	{Name: "Second Assist"},
+	{Name: "Strength"},
+	{Name: "Empty Net"},
+	{Name: "Penalty Shot"},
+}
+
+func GoalRecord(row models.GoalExportRow) []string {
+	return []string{
+		itoa(row.GoalID),
+		itoa(row.GameID),
+		start(row.Start),
+		row.Team,
+		itoa(row.Period),
+		models.ScoreboardClock(row.Elapsed),
+		row.Scorer,
+		row.Assist1,
+		row.Assist2,
+		row.Strength,
+		yesNo(row.EmptyNet),
+		yesNo(row.IsPenaltyShot),
+	}
+}
+
+var PenaltyColumns = []Column{
+	{Name: "Penalty ID", Numeric: true},
+	{Name: "Game ID", Numeric: true},
+	{Name: "Start"},
+	{Name: "Team"},
+	{Name: "Period", Numeric: true},
+	{Name: "Time"},
+	{Name: "Player"},
+	{Name: "Infraction"},
+	{Name: "Severity"},
+	{Name: "Minutes", Numeric: true},
+}
+
+func PenaltyRecord(row models.PenaltyExportRow) []string {
+	return []string{
+		itoa(row.PenaltyID),
+		itoa(row.GameID),
+		start(row.Start),
+		row.Team,
+		itoa(row.Period),
+		models.ScoreboardClock(row.Elapsed),
+		row.Player,
+		row.Infraction,
+		row.Severity,
+		itoa(row.Minutes),
+	}
+}
+
+var ShotColumns = []Column{
+	{Name: "Shot ID", Numeric: true},
+	{Name: "Game ID", Numeric: true},
+	{Name: "Start"},
+	{Name: "Team"},
+	{Name: "Period", Numeric: true},
+	{Name: "Time"},
+	{Name: "Goalie"},
+}
+
+func ShotRecord(row models.ShotExportRow) []string {
+	return []string{
+		itoa(row.ShotID),
+		itoa(row.GameID),
+		start(row.Start),
+		row.Team,
+		itoa(row.Period),
+		models.ScoreboardClock(row.ShotTime),
+		row.Goalie,
+	}
+}
+
+var StandingsColumns = []Column{
+	{Name: "Rank", Numeric: true},
+	{Name: "Team ID", Numeric: true},
+	{Name: "Team"},
+	{Name: "GP", Numeric: true},
+	{Name: "W", Numeric: true},
+	{Name: "OTW", Numeric: true},
+	{Name: "L", Numeric: true},
+	{Name: "OTL", Numeric: true},
+	{Name: "T", Numeric: true},
+	{Name: "GF", Numeric: true},
+	{Name: "GA", Numeric: true},
+	{Name: "DIFF", Numeric: true},
+	{Name: "PTS", Numeric: true},
+}
+
+func StandingsRecord(row standings.Row) []string {
+	return []string{
+		strconv.Itoa(row.Rank),
+		itoa(row.TeamID),
+		row.TeamName,
+		strconv.Itoa(row.GamesPlayed),
+		strconv.Itoa(row.Wins),
+		strconv.Itoa(row.OvertimeWins),
+		strconv.Itoa(row.Losses),
+		strconv.Itoa(row.OvertimeLosses),
+		strconv.Itoa(row.Ties),
+		strconv.Itoa(row.GoalsFor),
+		strconv.Itoa(row.GoalsAgainst),
+		strconv.Itoa(row.GoalDifferential),
+		strconv.Itoa(row.Points),
+	}
+}
+
+var registrationColumns = []Column{
+	{Name: "Registration ID", Numeric: true},
+	{Name: "Registered"},
+	{Name: "League ID", Numeric: true},
+	{Name: "Player ID", Numeric: true},
+	{Name: "First Name"},
+	{Name: "Last Name"},
+	{Name: "Email"},
+	{Name: "Phone"},
+	{Name: "Skill Level", Numeric: true},
+	{Name: "Position"},
+	{Name: "Status"},
+}
+
+// RegistrationColumns are the columns of a season's registrations: the registrant, then a column
+// for each question on the season's form
+func RegistrationColumns(form []models.FormQuestion) []Column {
+	columns := slices.Clone(registrationColumns)
+	for _, question := range form {
+		columns = append(columns, Column{Name: question.Text})
+	}
+	return columns
+}
+
+// RegistrationRecord is a registration with its answers in the order of the form's questions.
+// Questions the registrant wasn't asked or left blank are empty.
+func RegistrationRecord(r models.Registration, form []models.FormQuestion) []string {
+	record := []string{
+		itoa(r.ID),
+		start(r.CreatedAt),
+		itoa(r.LeagueID),
+		itoa(r.UserID),
+		r.User.FirstName,
+		r.User.LastName,
+		r.User.Email,
+		r.User.Phone,
+		strconv.Itoa(r.User.SkillLevel),
+		string(r.Position),
+		string(r.Status),
+	}
+	for _, question := range form {
+		answer := ""
+		for _, given := range r.Questions {
+			if given.Key == question.Key {
+				answer = given.Answer
+			}
+		}
+		if checked, err := strconv.ParseBool(answer); err == nil && (question.Type == models.CheckboxQuestion || question.Type == models.WaiverQuestion) {
+			answer = yesNo(checked)
+		}
+		record = append(record, answer)
+	}
+	return record
+}
+
+func itoa(n uint) string {
+	return strconv.FormatUint(uint64(n), 10)
+}
+
+func start(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(startLayout)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func jersey(number *int) string {
+	if number == nil {
+		return ""
+	}
+	return strconv.Itoa(*number)
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format is a spreadsheet format rows can be exported in
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat reads a format from a request, defaulting to CSV
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	}
+	return "", fmt.Errorf("format must be %v or %v", CSV, XLSX)
+}
+
+// ContentType is the MIME type of a format
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Column is a column of an export. Numeric columns are stored as numbers in XLSX so they can be
+// summed and sorted; CSV has no types.
+type Column struct {
+	Name    string
+	Numeric bool
+}
+
+// Writer writes the rows of an export one at a time. Close must be called to finish the file.
+type Writer interface {
+	Write(values []string) error
+	Close() error
+}
+
+// NewWriter starts an export in the given format and writes its header row. sheet names the
+// worksheet of an XLSX file.
+func NewWriter(w io.Writer, format Format, sheet string, columns []Column) (Writer, error) {
+	var writer Writer
+	switch format {
+	case CSV:
+		writer = &csvWriter{csv.NewWriter(w), columns}
+	case XLSX:
+		x, err := newXLSXWriter(w, sheet, columns)
+		if err != nil {
+			return nil, err
+		}
+		writer = x
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// formulaPrefixes are the characters that make a spreadsheet program read a cell as a formula
+const formulaPrefixes = "=+-@"
+
+// isNumber reports whether a value is stored as a number: a number in a numeric column
+func isNumber(columns []Column, i int, value string) bool {
+	if i >= len(columns) || !columns[i].Numeric {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// escapeFormula quotes a text value that would otherwise be run as a formula when the export is
+// opened, such as a player who named themselves =HYPERLINK(...)
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type csvWriter struct {
+	*csv.Writer
+	columns []Column
+}
+
+// Write adds a row. Numbers in numeric columns are written as they are, so negative values stay
+// numbers; every other value has formulas escaped.
+func (w *csvWriter) Write(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = value
+		if !isNumber(w.columns, i, value) {
+			escaped[i] = escapeFormula(value)
+		}
+	}
+	return w.Writer.Write(escaped)
+}
+
+func (w *csvWriter) Close() error {
+	w.Flush()
+	return w.Error()
+}
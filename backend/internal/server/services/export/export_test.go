@@ -0,0 +1,159 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{{Name: "Player"}, {Name: "Goals", Numeric: true}}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	assert.Nil(t, err)
+	assert.Equal(t, CSV, format)
+
+	format, err = ParseFormat("xlsx")
+	assert.Nil(t, err)
+	assert.Equal(t, XLSX, format)
+
+	_, err = ParseFormat("pdf")
+	assert.NotNil(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, CSV, "Scorers", testColumns)
+	require.Nil(t, err)
+	require.Nil(t, w.Write([]string{"Ann, Jr.", "3"}))
+	require.Nil(t, w.Close())
+
+	assert.Equal(t, "Player,Goals\n\"Ann, Jr.\",3\n", out.String())
+}
+
+func TestEscapeFormulas(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"Equals", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"Plus", "+1+1", "'+1+1"},
+		{"Minus", "-2+3", "'-2+3"},
+		{"At", "@SUM(A1)", "'@SUM(A1)"},
+		{"Plain text", "Ann", "Ann"},
+		{"Empty", "", ""},
+		{"Inside the value", "Ann=Bo", "Ann=Bo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, escapeFormula(tt.value))
+		})
+	}
+
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, CSV, "Scorers", testColumns)
+	require.Nil(t, err)
+	require.Nil(t, w.Write([]string{"=1+2", "-3"}))
+	require.Nil(t, w.Write([]string{"-Bo", "=4"}))
+	require.Nil(t, w.Close())
+	assert.Equal(t, "Player,Goals\n'=1+2,-3\n'-Bo,'=4\n", out.String())
+
+	out.Reset()
+	w, err = NewWriter(out, XLSX, "Scorers", testColumns)
+	require.Nil(t, err)
+	require.Nil(t, w.Write([]string{"@Ann", "-3"}))
+	require.Nil(t, w.Close())
+	sheet := readSheet(t, out.Bytes())["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="2"><c t="inlineStr"><is><t xml:space="preserve">&#39;@Ann</t></is></c><c><v>-3</v></c></row>`)
+}
+
+func readSheet(t *testing.T, data []byte) map[string]string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.Nil(t, err)
+
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.Nil(t, err)
+		content, err := io.ReadAll(r)
+		require.Nil(t, err)
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+func TestWriteXLSX(t *testing.T) {
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, XLSX, "Scorers: 2024/25 [Winter] League Season", testColumns)
+	require.Nil(t, err)
+	require.Nil(t, w.Write([]string{"Ann <Zed> & Co", "3"}))
+	require.Nil(t, w.Write([]string{"Bo", ""}))
+	require.Nil(t, w.Close())
+
+	parts := readSheet(t, out.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `name="Scorers 202425 Winter League Se"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">Player</t></is></c><c t="inlineStr"><is><t xml:space="preserve">Goals</t></is></c></row>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">Ann &lt;Zed&gt; &amp; Co</t>`)
+	assert.Contains(t, sheet, `<c><v>3</v></c>`)
+	assert.Contains(t, sheet, `<row r="3"><c t="inlineStr"><is><t xml:space="preserve">Bo</t></is></c><c t="inlineStr"><is><t xml:space="preserve"></t></is></c></row>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
+
+func TestRecords(t *testing.T) {
+	start := time.Date(2024, 10, 12, 20, 30, 0, 0, time.UTC)
+
+	goal := GoalRecord(models.GoalExportRow{GoalID: 1, GameID: 9, Start: start, Team: "Otters", Period: 2, Elapsed: 300, Scorer: "Ann Zed", Strength: "PP", EmptyNet: true})
+	assert.Equal(t, []string{"1", "9", "2024-10-12 20:30", "Otters", "2", "15:00", "Ann Zed", "", "", "PP", "Yes", "No"}, goal)
+	assert.Len(t, goal, len(GoalColumns))
+
+	shot := ShotRecord(models.ShotExportRow{ShotID: 4, GameID: 9, Start: start, Team: "Ravens", Period: 2, ShotTime: 300, Goalie: "Cy Dee"})
+	assert.Equal(t, []string{"4", "9", "2024-10-12 20:30", "Ravens", "2", "15:00", "Cy Dee"}, shot)
+	assert.Len(t, shot, len(ShotColumns))
+
+	roster := RosterRecord(models.RosterExportRow{League: "A", TeamID: 1, Team: "Otters", PlayerID: 10, FirstName: "Ann", LastName: "Zed", Captain: true})
+	assert.Equal(t, "Yes", roster[len(roster)-1])
+	assert.Len(t, roster, len(RosterColumns))
+
+	assert.Len(t, ScheduleRecord(models.ScheduleExportRow{}), len(ScheduleColumns))
+	assert.Equal(t, "", ScheduleRecord(models.ScheduleExportRow{})[1])
+	assert.Len(t, PenaltyRecord(models.PenaltyExportRow{}), len(PenaltyColumns))
+}
+
+func TestRegistrationRecord(t *testing.T) {
+	form := []models.FormQuestion{
+		{Key: "shirt", Text: "Shirt size", Type: models.ChoiceQuestion},
+		{Key: "returning", Text: "Returning?", Type: models.CheckboxQuestion},
+		{Key: "last_team", Text: "Which team?", Type: models.TextQuestion},
+	}
+	columns := RegistrationColumns(form)
+	assert.Equal(t, "Shirt size", columns[len(columns)-3].Name)
+
+	r := models.Registration{
+		LeagueID: 2,
+		UserID:   10,
+		User:     models.User{FirstName: "Ann", LastName: "Zed", SkillLevel: 3},
+		Position: models.Goalie,
+		Status:   models.RegistrationWaitlisted,
+		Questions: []models.Question{
+			{Key: "returning", Answer: "false"},
+			{Key: "shirt", Answer: "M"},
+		},
+	}
+	record := RegistrationRecord(r, form)
+	assert.Len(t, record, len(columns))
+	assert.Equal(t, []string{"3", "goalie", "waitlisted", "M", "No", ""}, record[len(record)-6:])
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An XLSX file is a zip of XML parts. The fixed parts are written up front and the worksheet
+// is written last, a row at a time, so an export never has to be held in memory.
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	sheetStartXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetEndXML = `</sheetData></worksheet>`
+)
+
+// maxSheetName is the longest worksheet name spreadsheet programs accept
+const maxSheetName = 31
+
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	columns []Column
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []Column) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, escapeXML(sheetName(sheet)))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(f, sheetStartXML); err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{archive: archive, sheet: f, columns: columns}, nil
+}
+
+// Write adds a row to the worksheet. Values in numeric columns are stored as numbers, except
+// in the header row; everything else is stored as text with formulas escaped.
+func (w *xlsxWriter) Write(values []string) error {
+	w.rows++
+	row := &strings.Builder{}
+	fmt.Fprintf(row, `<row r="%d">`, w.rows)
+	for i, value := range values {
+		if w.rows > 1 && isNumber(w.columns, i, value) {
+			fmt.Fprintf(row, `<c><v>%s</v></c>`, value)
+		} else {
+			fmt.Fprintf(row, `<c t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, escapeXML(escapeFormula(value)))
+		}
+	}
+	row.WriteString(`</row>`)
+
+	_, err := io.WriteString(w.sheet, row.String())
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := io.WriteString(w.sheet, sheetEndXML); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+func escapeXML(s string) string {
+	escaped := &strings.Builder{}
+	_ = xml.EscapeText(escaped, []byte(s))
+	return escaped.String()
+}
+
+// sheetName drops the characters worksheet names can't contain and trims the name to length
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxSheetName {
+		name = string(runes[:maxSheetName])
+	}
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package gamesheet
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// Role is the capacity someone signs a game sheet in
+type Role string
+
+const (
+	PrimaryReferee   Role = "primary_referee"
+	SecondaryReferee Role = "secondary_referee"
+	HomeCaptain      Role = "home_captain"
+	AwayCaptain      Role = "away_captain"
+)
+
+// Signer is someone whose signature a game sheet needs. UserID is 0 when nobody fills the role,
+// such as a roster without a captain, and the sheet can't be signed off until someone does.
+type Signer struct {
+	Role   Role `json:"role"`
+	UserID uint `json:"user_id"`
+}
+
+// Sheet is the sign-off state of a game. Digest identifies the current version of the game's
+// events, goalie changes and lineups; signers have to sign the version they reviewed.
+type Sheet struct {
+	Game       *models.Game           `json:"game"`
+	Digest     string                 `json:"digest"`
+	Required   []Signer               `json:"required"`
+	Signatures []models.GameSignature `json:"signatures"`
+	Missing    []Signer               `json:"missing"`
+}
+
+// Contents is what signers review on a game sheet besides the game's totals
+type Contents struct {
+	LastEntry     uint // ID of the latest entry in the game's event log, so any change to its events changes the digest
+	GoalieChanges []models.GoalieChange
+	Lineups       []models.Lineup // Both teams' lineups with their players, saved or seeded
+}
+
+// Build works out who has to sign a game's sheet and who still hasn't
+func Build(game *models.Game, homeCaptainId, awayCaptainId uint, signatures []models.GameSignature, contents Contents) Sheet {
+	sheet := Sheet{
+		Game:       game,
+		Digest:     Digest(game, contents),
+		Required:   Required(game, homeCaptainId, awayCaptainId),
+		Signatures: signatures,
+	}
+	sheet.Missing = Missing(sheet.Required, signatures, sheet.Digest)
+	return sheet
+}
+
+// Required lists the signers of a game sheet: each assigned referee and both captains
+func Required(game *models.Game, homeCaptainId, awayCaptainId uint) []Signer {
+	signers := make([]Signer, 0, 4)
+	if game.PrimaryRefereeID != nil {
+		signers = append(signers, Signer{Role: PrimaryReferee, UserID: *game.PrimaryRefereeID})
+	}
+	if game.SecondaryRefereeID != nil {
+		signers = append(signers, Signer{Role: SecondaryReferee, UserID: *game.SecondaryRefereeID})
+	}
+	return append(signers, Signer{Role: HomeCaptain, UserID: homeCaptainId}, Signer{Role: AwayCaptain, UserID: awayCaptainId})
+}
+
+// Digest fingerprints the version of a game sheet from its totals, the latest change to its events,
+// its goalie changes and who dressed for each team
+func Digest(game *models.Game, contents Contents) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d|%d-%d|%d-%d|%d",
+		game.ID,
+		game.HomeTeamScore, game.AwayTeamScore,
+		game.HomeTeamShotsOnGoal, game.AwayTeamShotsOnGoal,
+		contents.LastEntry)
+
+	for _, change := range contents.GoalieChanges {
+		fmt.Fprintf(&b, "|g%d:%d:%d:%d:%d", change.ID, change.TeamID, change.GoalieID, change.Period, change.GameTime)
+	}
+
+	for _, lineup := range contents.Lineups {
+		fmt.Fprintf(&b, "|l%d", lineup.TeamID)
+		players := slices.Clone(lineup.Players)
+		slices.SortFunc(players, func(a, b models.LineupMember) int { return cmp.Compare(a.UserID, b.UserID) })
+		for _, player := range players {
+			jersey := "-"
+			if player.JerseyNumber != nil {
+				jersey = strconv.Itoa(*player.JerseyNumber)
+			}
+			fmt.Fprintf(&b, ":%d/%s/%s/%t", player.UserID, jersey, player.Position, player.Sub)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Roles are the roles a user signs a game sheet in. A user can hold more than one, such as a
+// captain who also referees.
+func Roles(required []Signer, userId uint) []Role {
+	roles := make([]Role, 0)
+	if userId == 0 {
+		return roles
+	}
+	for _, signer := range required {
+		if signer.UserID == userId {
+			roles = append(roles, signer.Role)
+		}
+	}
+	return roles
+}
+
+// Missing returns the required signers without a current signature. A signature doesn't count
+// when it was made on another version of the sheet or by someone who no longer holds the role.
+func Missing(required []Signer, signatures []models.GameSignature, digest string) []Signer {
+	missing := make([]Signer, 0)
+	for _, signer := range required {
+		signed := false
+		for _, signature := range signatures {
+			if Role(signature.Role) == signer.Role && signature.UserID == signer.UserID && signature.Digest == digest {
+				signed = true
+				break
+			}
+		}
+		if !signed || signer.UserID == 0 {
+			missing = append(missing, signer)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,91 @@
+package gamesheet
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func refereed(primary uint) *models.Game {
+	return &models.Game{DbModel: models.DbModel{ID: 7}, PrimaryRefereeID: &primary, HomeTeamScore: 3, AwayTeamScore: 2}
+}
+
+func TestRequired(t *testing.T) {
+	assert.Equal(t, []Signer{{PrimaryReferee, 1}, {HomeCaptain, 10}, {AwayCaptain, 20}}, Required(refereed(1), 10, 20))
+	assert.Equal(t, []Signer{{HomeCaptain, 10}, {AwayCaptain, 0}}, Required(&models.Game{}, 10, 0))
+}
+
+func TestDigestChangesWithEvents(t *testing.T) {
+	game := refereed(1)
+	digest := Digest(game, Contents{LastEntry: 4})
+	assert.Equal(t, digest, Digest(game, Contents{LastEntry: 4}))
+	assert.NotEqual(t, digest, Digest(game, Contents{LastEntry: 5}), "a new event log entry changes the sheet")
+
+	game.AwayTeamScore = 3
+	assert.NotEqual(t, digest, Digest(game, Contents{LastEntry: 4}))
+}
+
+func TestDigestChangesWithGoaliesAndLineups(t *testing.T) {
+	game := refereed(1)
+	jersey := 30
+	contents := func() Contents {
+		return Contents{
+			LastEntry:     4,
+			GoalieChanges: []models.GoalieChange{{TeamID: 10, GoalieID: 100, Period: 1}},
+			Lineups: []models.Lineup{
+				{TeamID: 10, Players: []models.LineupMember{
+					{RosterMember: models.RosterMember{UserID: 100, JerseyNumber: &jersey, Position: models.Goalie}},
+					{RosterMember: models.RosterMember{UserID: 101, Position: models.Skater}},
+				}},
+				{TeamID: 20},
+			},
+		}
+	}
+	digest := Digest(game, contents())
+
+	reordered := contents()
+	players := reordered.Lineups[0].Players
+	players[0], players[1] = players[1], players[0]
+	assert.Equal(t, digest, Digest(game, reordered), "the order players are listed in doesn't matter")
+
+	changed := contents()
+	changed.GoalieChanges[0].GameTime = 60
+	assert.NotEqual(t, digest, Digest(game, changed), "a goalie change changes the sheet")
+
+	changed = contents()
+	changed.Lineups[1].Players = []models.LineupMember{{RosterMember: models.RosterMember{UserID: 200}, Sub: true}}
+	assert.NotEqual(t, digest, Digest(game, changed), "a player added to a lineup changes the sheet")
+
+	changed = contents()
+	changed.Lineups[0].Players[1].Position = models.Goalie
+	assert.NotEqual(t, digest, Digest(game, changed), "a position change changes the sheet")
+}
+
+func TestRoles(t *testing.T) {
+	required := Required(refereed(10), 10, 20)
+	assert.Equal(t, []Role{PrimaryReferee, HomeCaptain}, Roles(required, 10))
+	assert.Empty(t, Roles(required, 30))
+	assert.Empty(t, Roles(Required(refereed(1), 0, 0), 0), "nobody signs for an empty role")
+}
+
+func TestMissing(t *testing.T) {
+	game := refereed(1)
+	digest := Digest(game, Contents{LastEntry: 4})
+	required := Required(game, 10, 20)
+
+	signatures := []models.GameSignature{
+		{GameID: 7, Role: string(PrimaryReferee), UserID: 1, Digest: digest},
+		{GameID: 7, Role: string(HomeCaptain), UserID: 10, Digest: Digest(game, Contents{LastEntry: 3})},
+		{GameID: 7, Role: string(AwayCaptain), UserID: 21, Digest: digest},
+	}
+	missing := Missing(required, signatures, digest)
+	assert.Equal(t, []Signer{{HomeCaptain, 10}, {AwayCaptain, 20}}, missing, "stale signatures and former captains don't count")
+
+	signatures[1].Digest = digest
+	signatures[2].UserID = 20
+	assert.Empty(t, Missing(required, signatures, digest))
+
+	sheet := Build(game, 10, 0, signatures, Contents{LastEntry: 4})
+	assert.Equal(t, []Signer{{AwayCaptain, 0}}, sheet.Missing)
+}
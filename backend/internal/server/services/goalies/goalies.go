@@ -0,0 +1,220 @@
+package goalies
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// regulationLength is the game length goals against average is measured over
+const regulationLength = models.RegulationPeriods * models.PeriodLength
+
+// GameLines works out the line of every goalie who played in a game. Time in net comes from
+// the goalie changes; a team with no recorded changes and a single goalie credited with
+// events is assumed to have played that goalie for the whole game. Shots on goal include
+// the shots that were scored on, so saves are shots against less goals against.
+func GameLines(game models.GoalieGame, changes []models.GoalieChange, counts []models.GoalieEventCount) []models.GoalieStatLine {
+	lines := make([]models.GoalieStatLine, 0)
+	for _, teamId := range []uint{game.HomeTeamID, game.AwayTeamID} {
+		lines = append(lines, teamLines(game, teamId, changes, counts)...)
+	}
+	return lines
+}
+
+func teamLines(game models.GoalieGame, teamId uint, changes []models.GoalieChange, counts []models.GoalieEventCount) []models.GoalieStatLine {
+	teamChanges := make([]models.GoalieChange, 0)
+	for _, change := range changes {
+		if change.GameID == game.GameID && change.TeamID == teamId {
+			teamChanges = append(teamChanges, change)
+		}
+	}
+	sort.SliceStable(teamChanges, func(i, j int) bool {
+		return models.GameSeconds(teamChanges[i].Period, teamChanges[i].GameTime) < models.GameSeconds(teamChanges[j].Period, teamChanges[j].GameTime)
+	})
+
+	seconds := make(map[uint]uint)
+	order := make([]uint, 0)
+	credit := func(goalieId uint) {
+		if _, ok := seconds[goalieId]; !ok {
+			seconds[goalieId] = 0
+			order = append(order, goalieId)
+		}
+	}
+
+	for i, change := range teamChanges {
+		start := min(models.GameSeconds(change.Period, change.GameTime), game.Length)
+		end := game.Length
+		if i+1 < len(teamChanges) {
+			end = min(models.GameSeconds(teamChanges[i+1].Period, teamChanges[i+1].GameTime), game.Length)
+		}
+		if change.GoalieID == 0 {
+			continue // Empty net
+		}
+		credit(change.GoalieID)
+		if end > start {
+			seconds[change.GoalieID] += end - start
+		}
+	}
+
+	against := make(map[uint]models.GoalieEventCount)
+	teamGoalsAgainst := 0
+	for _, count := range counts {
+		if count.GameID != game.GameID || count.TeamID != teamId {
+			continue
+		}
+		teamGoalsAgainst += count.Goals
+		if count.GoalieID == 0 {
+			continue
+		}
+		credit(count.GoalieID)
+		total := against[count.GoalieID]
+		total.Shots += count.Shots
+		total.Goals += count.Goals
+		against[count.GoalieID] = total
+	}
+
+	if len(teamChanges) == 0 && len(order) == 1 {
+		seconds[order[0]] = game.Length
+	}
+
+	lines := make([]models.GoalieStatLine, 0, len(order))
+	for _, goalieId := range order {
+		line := models.GoalieStatLine{
+			GoalieID:      goalieId,
+			TeamID:        teamId,
+			GamesPlayed:   1,
+			SecondsPlayed: int(seconds[goalieId]),
+			ShotsAgainst:  against[goalieId].Shots,
+			GoalsAgainst:  against[goalieId].Goals,
+		}
+		if game.Final && teamGoalsAgainst == 0 && seconds[goalieId] >= game.Length {
+			line.Shutouts = 1
+		}
+		finish(&line)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// SeasonLines adds up the game lines of each goalie over several games, keeping only goalies who
+// played at least minGames of them
+func SeasonLines(games []models.GoalieGame, changes []models.GoalieChange, counts []models.GoalieEventCount, minGames int) []models.GoalieStatLine {
+	totals := make(map[uint]*models.GoalieStatLine)
+	order := make([]uint, 0)
+	for _, game := range games {
+		for _, line := range GameLines(game, changes, counts) {
+			total, ok := totals[line.GoalieID]
+			if !ok {
+				total = &models.GoalieStatLine{GoalieID: line.GoalieID}
+				totals[line.GoalieID] = total
+				order = append(order, line.GoalieID)
+			}
+			total.GamesPlayed += line.GamesPlayed
+			total.SecondsPlayed += line.SecondsPlayed
+			total.ShotsAgainst += line.ShotsAgainst
+			total.GoalsAgainst += line.GoalsAgainst
+			total.Shutouts += line.Shutouts
+		}
+	}
+
+	lines := make([]models.GoalieStatLine, 0, len(order))
+	for _, goalieId := range order {
+		line := totals[goalieId]
+		if line.GamesPlayed < minGames {
+			continue
+		}
+		finish(line)
+		lines = append(lines, *line)
+	}
+	return lines
+}
+
+func finish(line *models.GoalieStatLine) {
+	line.Saves = max(line.ShotsAgainst-line.GoalsAgainst, 0)
+	line.SavePercentage = 0
+	if line.ShotsAgainst > 0 {
+		line.SavePercentage = float64(line.Saves) / float64(line.ShotsAgainst)
+	}
+	line.GoalsAgainstAverage = 0
+	if line.SecondsPlayed > 0 {
+		line.GoalsAgainstAverage = float64(line.GoalsAgainst) * regulationLength / float64(line.SecondsPlayed)
+	}
+}
+
+// Sorts are the leaderboard orders accepted by Sort. Goals against average ranks lowest first.
+var Sorts = map[string]func(a, b models.GoalieStatLine) bool{
+	"save_percentage":       func(a, b models.GoalieStatLine) bool { return a.SavePercentage > b.SavePercentage },
+	"goals_against_average": func(a, b models.GoalieStatLine) bool { return a.GoalsAgainstAverage < b.GoalsAgainstAverage },
+	"shutouts":              func(a, b models.GoalieStatLine) bool { return a.Shutouts > b.Shutouts },
+	"saves":                 func(a, b models.GoalieStatLine) bool { return a.Saves > b.Saves },
+	"games_played":          func(a, b models.GoalieStatLine) bool { return a.GamesPlayed > b.GamesPlayed },
+}
+
+// Sort orders a leaderboard by one of Sorts, falling back to goalie ID for equal lines
+func Sort(lines []models.GoalieStatLine, key string) error {
+	less, ok := Sorts[key]
+	if !ok {
+		return fmt.Errorf("unknown sort %q", key)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if less(lines[i], lines[j]) {
+			return true
+		}
+		if less(lines[j], lines[i]) {
+			return false
+		}
+		return lines[i].GoalieID < lines[j].GoalieID
+	})
+	return nil
+}
+
+// InNet returns the goalie a team had in net at a point in a game, and whether any of the team's
+// goalie changes had been made by then. A zero goalie means the net was empty. Changes at the same
+// time are taken in the order given.
+func InNet(changes []models.GoalieChange, teamId, gameSeconds uint) (uint, bool) {
+	var goalieId, at uint
+	found := false
+	for _, change := range changes {
+		changed := models.GameSeconds(change.Period, change.GameTime)
+		if change.TeamID != teamId || changed > gameSeconds || (found && changed < at) {
+			continue
+		}
+		goalieId, at, found = change.GoalieID, changed, true
+	}
+	return goalieId, found
+}
+
+// Attribute credits a game's goals and shots to the goalie the other team had in net, going by
+// the game's goalie changes. Events before a team's first change are left alone, since nothing
+// records who was in net then. The goals and shots whose goalie changed are returned.
+func Attribute(homeTeamId, awayTeamId uint, changes []models.GoalieChange, goals []models.Goal, shots []models.ShotOnGoal) ([]models.Goal, []models.ShotOnGoal) {
+	defending := func(shootingTeamId uint) uint {
+		if shootingTeamId == homeTeamId {
+			return awayTeamId
+		}
+		return homeTeamId
+	}
+
+	changedGoals := make([]models.Goal, 0)
+	for _, goal := range goals {
+		goalieId, ok := InNet(changes, defending(goal.TeamId), models.GameSeconds(goal.Period, goal.Duration))
+		if !ok || (goal.GoalieID == goalieId && goal.EmptyNet == (goalieId == 0)) {
+			continue
+		}
+		goal.GoalieID, goal.EmptyNet = goalieId, goalieId == 0
+		changedGoals = append(changedGoals, goal)
+	}
+
+	changedShots := make([]models.ShotOnGoal, 0)
+	for _, shot := range shots {
+		goalieId, ok := InNet(changes, defending(shot.TeamId), models.GameSeconds(shot.Period, shot.ShotTime))
+		if !ok || shot.GoalieID == goalieId {
+			continue
+		}
+		shot.GoalieID = goalieId
+		changedShots = append(changedShots, shot)
+	}
+
+	return changedGoals, changedShots
+}
@@ -0,0 +1,175 @@
+package goalies
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fullGame = models.RegulationPeriods * models.PeriodLength
+
+func TestGameLinesSplitsTimeAtGoalieChange(t *testing.T) {
+	game := models.GoalieGame{GameID: 1, HomeTeamID: 10, AwayTeamID: 20, Length: fullGame, Final: true}
+	changes := []models.GoalieChange{
+		{GameID: 1, TeamID: 10, GoalieID: 100, Period: 1, GameTime: 0},
+		{GameID: 1, TeamID: 10, GoalieID: 101, Period: 2, GameTime: 0},
+		{GameID: 1, TeamID: 20, GoalieID: 200, Period: 1, GameTime: 0},
+	}
+	counts := []models.GoalieEventCount{
+		{GameID: 1, TeamID: 10, GoalieID: 100, Shots: 10, Goals: 3},
+		{GameID: 1, TeamID: 10, GoalieID: 101, Shots: 20, Goals: 0},
+		{GameID: 1, TeamID: 20, GoalieID: 200, Shots: 25, Goals: 0},
+	}
+
+	lines := GameLines(game, changes, counts)
+	require.Len(t, lines, 3)
+
+	starter := lines[0]
+	assert.Equal(t, uint(100), starter.GoalieID)
+	assert.Equal(t, models.PeriodLength, starter.SecondsPlayed)
+	assert.Equal(t, 7, starter.Saves)
+	assert.InDelta(t, 0.7, starter.SavePercentage, 0.001)
+	assert.InDelta(t, 9.0, starter.GoalsAgainstAverage, 0.001)
+	assert.Equal(t, 0, starter.Shutouts)
+
+	backup := lines[1]
+	assert.Equal(t, 2*models.PeriodLength, backup.SecondsPlayed)
+	assert.Equal(t, 0, backup.Shutouts, "no shutout for a goalie who didn't play the whole game")
+
+	away := lines[2]
+	assert.Equal(t, fullGame, away.SecondsPlayed)
+	assert.Equal(t, 1, away.Shutouts)
+}
+
+func TestGameLinesEmptyNetGoalBreaksShutout(t *testing.T) {
+	game := models.GoalieGame{GameID: 1, HomeTeamID: 10, AwayTeamID: 20, Length: fullGame, Final: true}
+	changes := []models.GoalieChange{
+		{GameID: 1, TeamID: 10, GoalieID: 100, Period: 1, GameTime: 0},
+		{GameID: 1, TeamID: 10, GoalieID: 0, Period: 3, GameTime: 1100},
+		{GameID: 1, TeamID: 10, GoalieID: 100, Period: 3, GameTime: 1150},
+	}
+	counts := []models.GoalieEventCount{
+		{GameID: 1, TeamID: 10, GoalieID: 100, Shots: 30, Goals: 0},
+		{GameID: 1, TeamID: 10, GoalieID: 0, Shots: 1, Goals: 1},
+	}
+
+	lines := GameLines(game, changes, counts)
+	require.Len(t, lines, 1)
+	assert.Equal(t, fullGame-50, lines[0].SecondsPlayed)
+	assert.Equal(t, 0, lines[0].GoalsAgainst)
+	assert.Equal(t, 0, lines[0].Shutouts)
+}
+
+func TestGameLinesWithoutChangesAssumesSoleGoaliePlayedWholeGame(t *testing.T) {
+	game := models.GoalieGame{GameID: 1, HomeTeamID: 10, AwayTeamID: 20, Length: fullGame, Final: true}
+	counts := []models.GoalieEventCount{{GameID: 1, TeamID: 20, GoalieID: 200, Shots: 12, Goals: 0}}
+
+	lines := GameLines(game, nil, counts)
+	require.Len(t, lines, 1)
+	assert.Equal(t, fullGame, lines[0].SecondsPlayed)
+	assert.Equal(t, 1, lines[0].Shutouts)
+}
+
+func TestSeasonLinesQualification(t *testing.T) {
+	games := []models.GoalieGame{
+		{GameID: 1, HomeTeamID: 10, AwayTeamID: 20, Length: fullGame, Final: true},
+		{GameID: 2, HomeTeamID: 20, AwayTeamID: 10, Length: fullGame, Final: true},
+	}
+	counts := []models.GoalieEventCount{
+		{GameID: 1, TeamID: 10, GoalieID: 100, Shots: 20, Goals: 2},
+		{GameID: 2, TeamID: 10, GoalieID: 100, Shots: 20, Goals: 0},
+		{GameID: 1, TeamID: 20, GoalieID: 200, Shots: 20, Goals: 1},
+		{GameID: 2, TeamID: 20, GoalieID: 201, Shots: 20, Goals: 4},
+	}
+
+	lines := SeasonLines(games, nil, counts, 2)
+	require.Len(t, lines, 1)
+	assert.Equal(t, uint(100), lines[0].GoalieID)
+	assert.Equal(t, 2, lines[0].GamesPlayed)
+	assert.Equal(t, 38, lines[0].Saves)
+	assert.Equal(t, 1, lines[0].Shutouts)
+	assert.InDelta(t, 1.0, lines[0].GoalsAgainstAverage, 0.001)
+
+	lines = SeasonLines(games, nil, counts, 1)
+	require.Nil(t, Sort(lines, "goals_against_average"))
+	assert.Equal(t, []uint{100, 200, 201}, []uint{lines[0].GoalieID, lines[1].GoalieID, lines[2].GoalieID})
+}
+
+func TestInNet(t *testing.T) {
+	changes := []models.GoalieChange{
+		{TeamID: 10, GoalieID: 100, Period: 1, GameTime: 0},
+		{TeamID: 20, GoalieID: 200, Period: 1, GameTime: 0},
+		{TeamID: 10, GoalieID: 0, Period: 3, GameTime: 1100},
+		{TeamID: 10, GoalieID: 101, Period: 3, GameTime: 1100},
+	}
+
+	tests := []struct {
+		name    string
+		changes []models.GoalieChange
+		team    uint
+		seconds uint
+		goalie  uint
+		found   bool
+	}{
+		{"Starter", changes, 10, 600, 100, true},
+		{"Other team", changes, 20, 3000, 200, true},
+		{"Later change at the same time wins", changes, 10, models.GameSeconds(3, 1100), 101, true},
+		{"Just before a change", changes, 10, models.GameSeconds(3, 1099), 100, true},
+		{"Before any change", changes[2:], 10, 600, 0, false},
+		{"No changes", nil, 10, 600, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			goalie, found := InNet(test.changes, test.team, test.seconds)
+			assert.Equal(t, test.goalie, goalie)
+			assert.Equal(t, test.found, found)
+		})
+	}
+}
+
+func TestAttributeLateGoalieChange(t *testing.T) {
+	goal := func(id, teamId, period, at uint) models.Goal {
+		g := models.Goal{TeamId: teamId, Period: period, Duration: at}
+		g.ID = id
+		return g
+	}
+	shot := func(id, teamId, period, at, goalieId uint) models.ShotOnGoal {
+		s := models.ShotOnGoal{TeamId: teamId, Period: period, ShotTime: at, GoalieID: goalieId}
+		s.ID = id
+		return s
+	}
+
+	// The events were scored before any goalie was recorded, then home's starter and a pulled
+	// net were entered after the game
+	changes := []models.GoalieChange{
+		{TeamID: 10, GoalieID: 100, Period: 1, GameTime: 0},
+		{TeamID: 10, GoalieID: 0, Period: 3, GameTime: 1100},
+	}
+	goals := []models.Goal{goal(1, 20, 1, 300), goal(2, 20, 3, 1150), goal(3, 10, 2, 60)}
+	shots := []models.ShotOnGoal{shot(1, 20, 1, 300, 0), shot(2, 20, 2, 30, 100), shot(3, 10, 2, 60, 0)}
+
+	changedGoals, changedShots := Attribute(10, 20, changes, goals, shots)
+
+	require.Len(t, changedGoals, 2, "away's goal isn't covered by any of its goalie changes")
+	assert.Equal(t, uint(1), changedGoals[0].ID)
+	assert.Equal(t, uint(100), changedGoals[0].GoalieID)
+	assert.False(t, changedGoals[0].EmptyNet)
+	assert.Equal(t, uint(2), changedGoals[1].ID)
+	assert.Equal(t, uint(0), changedGoals[1].GoalieID)
+	assert.True(t, changedGoals[1].EmptyNet)
+
+	require.Len(t, changedShots, 1, "a shot already credited to the goalie in net is unchanged")
+	assert.Equal(t, uint(1), changedShots[0].ID)
+	assert.Equal(t, uint(100), changedShots[0].GoalieID)
+
+	// A backup entered late takes over the events after they came in
+	changes = append(changes, models.GoalieChange{TeamID: 10, GoalieID: 101, Period: 2, GameTime: 0})
+	shots[0] = changedShots[0]
+	changedGoals, changedShots = Attribute(10, 20, changes, changedGoals, shots)
+	assert.Empty(t, changedGoals)
+	require.Len(t, changedShots, 1)
+	assert.Equal(t, uint(2), changedShots[0].ID)
+	assert.Equal(t, uint(101), changedShots[0].GoalieID)
+}
@@ -0,0 +1,387 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is what a CSV file imports
+type Kind string
+
+const (
+	Users   Kind = "users"
+	Rosters Kind = "rosters" // Roster memberships of existing users
+	Games   Kind = "games"
+)
+
+// ParseKind reads the kind of an import, such as from a request path or CLI flag
+func ParseKind(s string) (Kind, error) {
+	switch kind := Kind(strings.ToLower(s)); kind {
+	case Users, Rosters, Games:
+		return kind, nil
+	}
+	return "", fmt.Errorf("an import must be %v, %v or %v", Users, Rosters, Games)
+}
+
+// MaxRows is the most data rows a single file can import
+const MaxRows = 5000
+
+// dateLayouts are the date and time formats accepted in a file, as spreadsheets tend to write them
+var (
+	dateLayouts     = []string{"2006-01-02", "1/2/2006", "01/02/2006"}
+	dateTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "1/2/2006 15:04", "1/2/2006 3:04 PM"}
+)
+
+// RowError is a problem with one row of a file. Row counts from 1 at the header, matching the row
+// numbers a spreadsheet shows, and Column names the offending column when there is one.
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, %s: %s", e.Row, e.Column, e.Message)
+}
+
+// Result reports what an import did, or for a dry run what it would do. Nothing is saved unless
+// every row is valid.
+type Result struct {
+	Kind    Kind       `json:"kind"`
+	DryRun  bool       `json:"dry_run"`
+	Rows    int        `json:"rows"`
+	Created int        `json:"created"`
+	Matched int        `json:"matched"` // Rows already in the database, such as users matched by email
+	Errors  []RowError `json:"errors"`
+	Saved   bool       `json:"saved"`
+}
+
+// Fail records a problem with a row
+func (r *Result) Fail(row int, column, message string, args ...any) {
+	r.Errors = append(r.Errors, RowError{Row: row, Column: column, Message: fmt.Sprintf(message, args...)})
+}
+
+// UserRow is a player or official to create, unless a user with the same email already exists
+type UserRow struct {
+	Row         int
+	FirstName   string
+	LastName    string
+	Email       string
+	Phone       string
+	SkillLevel  int
+	DateOfBirth time.Time
+}
+
+// RosterRow puts an existing user, found by email, on the roster of a team in a league
+type RosterRow struct {
+	Row      int
+	LeagueID uint
+	Team     string
+	Email    string
+	Captain  bool
+}
+
+// GameRow schedules a game between two teams of a league at a venue, found by name
+type GameRow struct {
+	Row            int
+	LeagueID       uint
+	Start          time.Time
+	HomeTeam       string
+	AwayTeam       string
+	Venue          string
+	HomeLockerRoom string
+	AwayLockerRoom string
+}
+
+// file reads a CSV file with a header row, looking columns up by name so they can be in any order
+type file struct {
+	reader  *csv.Reader
+	columns map[string]int
+	record  []string
+	row     int
+	result  *Result
+}
+
+// newFile reads the header of a file. It returns nil after adding row errors to result when
+// required columns are missing or there are columns it doesn't know.
+func newFile(r io.Reader, result *Result, required []string, optional ...string) *file {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	f := &file{reader: reader, columns: map[string]int{}, result: result, row: 1}
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		result.Fail(1, "", "the file is empty")
+		return nil
+	}
+	if err != nil {
+		result.Fail(1, "", "the header can't be read: %v", err)
+		return nil
+	}
+	known := slices.Concat(required, optional)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		switch _, ok := f.columns[name]; {
+		case name == "":
+			continue // Spreadsheets often save unused columns with blank headers
+		case ok:
+			result.Fail(1, name, "the column appears more than once")
+		case !slices.Contains(known, name):
+			result.Fail(1, name, "the column isn't recognized, expected %v", strings.Join(known, ", "))
+		}
+		f.columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := f.columns[name]; !ok {
+			result.Fail(1, name, "the column is missing")
+		}
+	}
+	if len(result.Errors) > 0 {
+		return nil
+	}
+	return f
+}
+
+// next moves to the next data row. It reports false at the end of the file or when the file
+// can't be read any further.
+func (f *file) next() bool {
+	for {
+		record, err := f.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		f.row++
+		if err != nil {
+			f.result.Fail(f.row, "", "the row can't be read: %v", err)
+			return false
+		}
+		if blank(record) {
+			continue
+		}
+		f.result.Rows++
+		if f.result.Rows > MaxRows {
+			f.result.Fail(f.row, "", "a file can import at most %d rows", MaxRows)
+			return false
+		}
+		f.record = record
+		return true
+	}
+}
+
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *file) value(column string) string {
+	i, ok := f.columns[column]
+	if !ok || i >= len(f.record) {
+		return ""
+	}
+	return strings.TrimSpace(f.record[i])
+}
+
+func (f *file) required(column string) string {
+	value := f.value(column)
+	if value == "" {
+		f.result.Fail(f.row, column, "a value is required")
+	}
+	return value
+}
+
+func (f *file) id(column string) uint {
+	value := f.required(column)
+	if value == "" {
+		return 0
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || id == 0 {
+		f.result.Fail(f.row, column, "%q is not a valid id", value)
+		return 0
+	}
+	return uint(id)
+}
+
+func (f *file) boolean(column string) bool {
+	switch strings.ToLower(f.value(column)) {
+	case "", "no", "n", "false", "0":
+		return false
+	case "yes", "y", "true", "1", "x":
+		return true
+	}
+	f.result.Fail(f.row, column, "%q is not yes or no", f.value(column))
+	return false
+}
+
+func (f *file) time(column string, layouts []string, location *time.Location) time.Time {
+	value := f.value(column)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t
+		}
+	}
+	f.result.Fail(f.row, column, "%q is not a recognized date", value)
+	return time.Time{}
+}
+
+// ParseUsers reads users from a CSV file with the columns first_name, last_name, email and
+// optionally phone, skill_level and date_of_birth. Problems are added to result as row errors.
+func ParseUsers(r io.Reader, result *Result) []UserRow {
+	f := newFile(r, result, []string{"first_name", "last_name", "email"}, "phone", "skill_level", "date_of_birth")
+	if f == nil {
+		return nil
+	}
+
+	rows := make([]UserRow, 0)
+	emails := map[string]int{}
+	for f.next() {
+		row := UserRow{
+			Row:         f.row,
+			FirstName:   f.required("first_name"),
+			LastName:    f.required("last_name"),
+			Email:       f.required("email"),
+			Phone:       f.value("phone"),
+			DateOfBirth: f.time("date_of_birth", dateLayouts, time.UTC),
+		}
+
+		if row.Email != "" {
+			if address, err := mail.ParseAddress(row.Email); err != nil || address.Address != row.Email {
+				f.result.Fail(f.row, "email", "%q is not a valid email address", row.Email)
+			}
+			row.Email = NormalizeEmail(row.Email)
+			if first, ok := emails[row.Email]; ok {
+				f.result.Fail(f.row, "email", "%v is also on row %d", row.Email, first)
+			} else {
+				emails[row.Email] = f.row
+			}
+		}
+
+		if skill := f.value("skill_level"); skill != "" {
+			level, err := strconv.Atoi(skill)
+			if err != nil || level < 0 {
+				f.result.Fail(f.row, "skill_level", "%q is not a valid skill level", skill)
+			}
+			row.SkillLevel = level
+		}
+
+		if !row.DateOfBirth.IsZero() && row.DateOfBirth.After(time.Now()) {
+			f.result.Fail(f.row, "date_of_birth", "the date of birth is in the future")
+		}
+
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ParseRosters reads roster memberships from a CSV file with the columns league_id, team and
+// email and optionally captain. Each team can have one captain.
+func ParseRosters(r io.Reader, result *Result) []RosterRow {
+	f := newFile(r, result, []string{"league_id", "team", "email"}, "captain")
+	if f == nil {
+		return nil
+	}
+
+	rows := make([]RosterRow, 0)
+	members := map[string]int{}
+	captains := map[string]int{}
+	for f.next() {
+		row := RosterRow{
+			Row:      f.row,
+			LeagueID: f.id("league_id"),
+			Team:     f.required("team"),
+			Email:    NormalizeEmail(f.required("email")),
+			Captain:  f.boolean("captain"),
+		}
+
+		team := fmt.Sprintf("%d/%s", row.LeagueID, NormalizeName(row.Team))
+		if row.Email != "" {
+			member := team + "/" + row.Email
+			if first, ok := members[member]; ok {
+				f.result.Fail(f.row, "email", "%v is already added to %v on row %d", row.Email, row.Team, first)
+			} else {
+				members[member] = f.row
+			}
+		}
+		if row.Captain {
+			if first, ok := captains[team]; ok {
+				f.result.Fail(f.row, "captain", "%v already has a captain on row %d", row.Team, first)
+			} else {
+				captains[team] = f.row
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ParseGames reads a schedule from a CSV file with the columns league_id, start, home_team,
+// away_team and venue and optionally home_locker_room and away_locker_room. Start times without
+// a zone are read in location.
+func ParseGames(r io.Reader, result *Result, location *time.Location) []GameRow {
+	f := newFile(r, result, []string{"league_id", "start", "home_team", "away_team", "venue"}, "home_locker_room", "away_locker_room")
+	if f == nil {
+		return nil
+	}
+
+	rows := make([]GameRow, 0)
+	games := map[string]int{}
+	for f.next() {
+		row := GameRow{
+			Row:            f.row,
+			LeagueID:       f.id("league_id"),
+			HomeTeam:       f.required("home_team"),
+			AwayTeam:       f.required("away_team"),
+			Venue:          f.required("venue"),
+			HomeLockerRoom: f.value("home_locker_room"),
+			AwayLockerRoom: f.value("away_locker_room"),
+		}
+		if f.required("start") != "" {
+			row.Start = f.time("start", dateTimeLayouts, location)
+		}
+
+		if row.HomeTeam != "" && NormalizeName(row.HomeTeam) == NormalizeName(row.AwayTeam) {
+			f.result.Fail(f.row, "away_team", "a team can't play itself")
+		}
+		if !row.Start.IsZero() {
+			game := fmt.Sprintf("%d/%s/%s/%s", row.LeagueID, NormalizeName(row.HomeTeam), NormalizeName(row.AwayTeam), row.Start.UTC())
+			if first, ok := games[game]; ok {
+				f.result.Fail(f.row, "", "the game is also on row %d", first)
+			} else {
+				games[game] = f.row
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// NormalizeEmail is the form emails are compared in, so users aren't duplicated by case
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeName is the form team and venue names are compared in
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKind(t *testing.T) {
+	kind, err := ParseKind("Games")
+	assert.Nil(t, err)
+	assert.Equal(t, Games, kind)
+
+	_, err = ParseKind("venues")
+	assert.NotNil(t, err)
+}
+
+func TestParseUsers(t *testing.T) {
+	file := "\ufeffFirst Name,Last Name,Email,Phone,Skill Level,Date of Birth,\n" +
+		"Ann,Zed,Ann@Example.com,555-0100,3,1990-04-05,\n" +
+		",,,,,,\n" +
+		"Bo,Able,bo@example.com,,,4/5/1991,\n"
+	result := &Result{}
+	rows := ParseUsers(strings.NewReader(file), result)
+
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 2, result.Rows)
+	require.Len(t, rows, 2)
+	assert.Equal(t, UserRow{Row: 2, FirstName: "Ann", LastName: "Zed", Email: "ann@example.com", Phone: "555-0100", SkillLevel: 3, DateOfBirth: time.Date(1990, 4, 5, 0, 0, 0, 0, time.UTC)}, rows[0])
+	assert.Equal(t, 4, rows[1].Row, "blank rows keep the spreadsheet's numbering")
+	assert.Equal(t, time.Date(1991, 4, 5, 0, 0, 0, 0, time.UTC), rows[1].DateOfBirth)
+}
+
+func TestParseUsersRowErrors(t *testing.T) {
+	file := "first_name,last_name,email,skill_level\n" +
+		"Ann,Zed,ann@example.com,3\n" +
+		",Able,not an email,-1\n" +
+		"Cy,Dee,ANN@example.com,\n"
+	result := &Result{}
+	ParseUsers(strings.NewReader(file), result)
+
+	assert.Equal(t, []RowError{
+		{Row: 3, Column: "first_name", Message: "a value is required"},
+		{Row: 3, Column: "email", Message: `"not an email" is not a valid email address`},
+		{Row: 3, Column: "skill_level", Message: `"-1" is not a valid skill level`},
+		{Row: 4, Column: "email", Message: "ann@example.com is also on row 2"},
+	}, result.Errors)
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	result := &Result{}
+	rows := ParseUsers(strings.NewReader("first_name,surname,email\nAnn,Zed,ann@example.com\n"), result)
+
+	assert.Nil(t, rows)
+	assert.Equal(t, 0, result.Rows)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, RowError{Row: 1, Column: "surname", Message: "the column isn't recognized, expected first_name, last_name, email, phone, skill_level, date_of_birth"}, result.Errors[0])
+	assert.Equal(t, RowError{Row: 1, Column: "last_name", Message: "the column is missing"}, result.Errors[1])
+
+	result = &Result{}
+	ParseGames(strings.NewReader(""), result, time.UTC)
+	assert.Equal(t, []RowError{{Row: 1, Message: "the file is empty"}}, result.Errors)
+}
+
+func TestParseRosters(t *testing.T) {
+	file := "league_id,team,email,captain\n" +
+		"1,Otters,ann@example.com,yes\n" +
+		"1, otters ,bo@example.com,Y\n" +
+		"1,Otters,ANN@example.com,\n" +
+		"x,Ravens,cy@example.com,maybe\n"
+	result := &Result{}
+	rows := ParseRosters(strings.NewReader(file), result)
+
+	require.Len(t, rows, 4)
+	assert.Equal(t, RosterRow{Row: 2, LeagueID: 1, Team: "Otters", Email: "ann@example.com", Captain: true}, rows[0])
+	assert.Equal(t, []RowError{
+		{Row: 3, Column: "captain", Message: "otters already has a captain on row 2"},
+		{Row: 4, Column: "email", Message: "ann@example.com is already added to Otters on row 2"},
+		{Row: 5, Column: "league_id", Message: `"x" is not a valid id`},
+		{Row: 5, Column: "captain", Message: `"maybe" is not yes or no`},
+	}, result.Errors)
+}
+
+func TestParseGames(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	require.Nil(t, err)
+
+	file := "league_id,start,home_team,away_team,venue,home_locker_room\n" +
+		"1,2024-10-12 20:30,Otters,Ravens,Rec Center,A\n" +
+		"1,2024-10-12T20:30:00Z,Otters,Ravens,Rec Center,\n" +
+		"1,10/12/2024 8:30 PM,Otters,otters,Rec Center,\n" +
+		"1,someday,Otters,Ravens,,\n" +
+		"1,2024-10-12 20:30,Otters,Ravens,Rec Center,\n"
+	result := &Result{}
+	rows := ParseGames(strings.NewReader(file), result, denver)
+
+	require.Len(t, rows, 5)
+	assert.Equal(t, time.Date(2024, 10, 12, 20, 30, 0, 0, denver), rows[0].Start)
+	assert.Equal(t, "A", rows[0].HomeLockerRoom)
+	assert.True(t, rows[1].Start.Equal(time.Date(2024, 10, 12, 20, 30, 0, 0, time.UTC)))
+	assert.Equal(t, []RowError{
+		{Row: 4, Column: "away_team", Message: "a team can't play itself"},
+		{Row: 5, Column: "venue", Message: "a value is required"},
+		{Row: 5, Column: "start", Message: `"someday" is not a recognized date`},
+		{Row: 6, Message: "the game is also on row 2"},
+	}, result.Errors)
+}
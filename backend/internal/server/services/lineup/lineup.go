@@ -0,0 +1,105 @@
+package lineup
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+)
+
+// MaxPlayers is the most players who can dress for a team: eighteen skaters and two goalies
+const MaxPlayers = 20
+
+var (
+	ErrTeamNotInGame   = errors.New("the team isn't playing in the game")
+	ErrLocked          = errors.New("the lineup is locked once the game has started")
+	ErrNotCaptain      = errors.New("only the team's captain or a manager can change its lineup")
+	ErrUnknownPlayer   = errors.New("the player doesn't exist")
+	ErrAlreadyInLineup = errors.New("the player is already in the lineup")
+	ErrNotInLineup     = errors.New("the player isn't in the lineup")
+	ErrInOtherLineup   = errors.New("the player is in the other team's lineup")
+	ErrSuspended       = errors.New("the player is suspended for the game")
+	ErrLineupFull      = fmt.Errorf("the lineup is full, at most %d players can dress", MaxPlayers)
+	ErrJerseyTaken     = errors.New("the jersey number is taken")
+)
+
+// Locked reports whether a game's lineups can no longer change: once the puck has dropped, the
+// game has moved on from scheduled or its sheet has been signed off
+func Locked(game models.Game, now time.Time) bool {
+	return game.SignedOffAt != nil || game.Status != models.SCHEDULED || !now.Before(game.Start)
+}
+
+// Seed is the lineup a team starts with: its roster, less players who declined the game and
+// players who are suspended for it
+func Seed(members []models.RosterMember, declined, suspended []uint) []models.LineupMember {
+	seeded := make([]models.LineupMember, 0, len(members))
+	for _, member := range members {
+		if slices.Contains(declined, member.UserID) || slices.Contains(suspended, member.UserID) {
+			continue
+		}
+		seeded = append(seeded, models.LineupMember{RosterMember: member})
+	}
+	return seeded
+}
+
+// CheckAdd checks a player can join a lineup. Suspended players are blocked.
+func CheckAdd(players []models.LineupPlayer, player models.LineupPlayer, suspended bool) error {
+	for _, p := range players {
+		if p.UserID == player.UserID {
+			return ErrAlreadyInLineup
+		}
+	}
+	if suspended {
+		return ErrSuspended
+	}
+	if len(players) >= MaxPlayers {
+		return ErrLineupFull
+	}
+	return check(players, player)
+}
+
+// CheckUpdate checks a player's new jersey number and position for the game
+func CheckUpdate(players []models.LineupPlayer, player models.LineupPlayer) error {
+	for _, p := range players {
+		if p.UserID == player.UserID {
+			return check(players, player)
+		}
+	}
+	return ErrNotInLineup
+}
+
+func check(players []models.LineupPlayer, player models.LineupPlayer) error {
+	if player.Position != models.Skater && player.Position != models.Goalie {
+		return roster.ErrInvalidPosition
+	}
+	if player.JerseyNumber == nil {
+		return nil
+	}
+
+	number := *player.JerseyNumber
+	if number < 0 || number > roster.MaxJerseyNumber {
+		return roster.ErrInvalidJersey
+	}
+	for _, p := range players {
+		if p.UserID != player.UserID && p.JerseyNumber != nil && *p.JerseyNumber == number {
+			return fmt.Errorf("%w, #%d is already worn in this game", ErrJerseyTaken, number)
+		}
+	}
+	return nil
+}
+
+// IsRuleViolation reports whether err is one of the lineup rules rather than a failure.
+// Permission errors aren't rule violations.
+func IsRuleViolation(err error) bool {
+	rules := []error{ErrTeamNotInGame, ErrLocked, ErrUnknownPlayer, ErrAlreadyInLineup, ErrNotInLineup,
+		ErrInOtherLineup, ErrSuspended, ErrLineupFull, ErrJerseyTaken, roster.ErrInvalidJersey, roster.ErrInvalidPosition}
+	for _, rule := range rules {
+		if errors.Is(err, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package lineup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/roster"
+	"github.com/stretchr/testify/assert"
+)
+
+var puckDrop = time.Date(2024, 10, 12, 20, 30, 0, 0, time.UTC)
+
+func jersey(n int) *int {
+	return &n
+}
+
+func testLineup() []models.LineupPlayer {
+	return []models.LineupPlayer{
+		{LineupID: 1, UserID: 10, JerseyNumber: jersey(9), Position: models.Skater},
+		{LineupID: 1, UserID: 11, JerseyNumber: jersey(30), Position: models.Goalie},
+	}
+}
+
+func TestLocked(t *testing.T) {
+	game := models.Game{Start: puckDrop, Status: models.SCHEDULED}
+	assert.False(t, Locked(game, puckDrop.Add(-time.Minute)))
+	assert.True(t, Locked(game, puckDrop), "lineups lock at puck drop")
+
+	game.Status = models.IN_PROGRESS
+	assert.True(t, Locked(game, puckDrop.Add(-time.Minute)), "a game started early is locked")
+
+	signed := puckDrop
+	assert.True(t, Locked(models.Game{Start: puckDrop, Status: models.SCHEDULED, SignedOffAt: &signed}, puckDrop.Add(-time.Hour)))
+}
+
+func TestSeed(t *testing.T) {
+	members := []models.RosterMember{
+		{UserID: 10, FirstName: "Ann", JerseyNumber: jersey(9), Captain: true},
+		{UserID: 11, FirstName: "Bo"},
+		{UserID: 12, FirstName: "Cy"},
+	}
+
+	seeded := Seed(members, []uint{11, 99}, []uint{12})
+	assert.Equal(t, []models.LineupMember{{RosterMember: members[0]}}, seeded, "declined and suspended players are left out")
+	assert.Len(t, Seed(members, nil, nil), 3)
+}
+
+func TestCheckAdd(t *testing.T) {
+	players := testLineup()
+
+	assert.NoError(t, CheckAdd(players, models.LineupPlayer{UserID: 20, JerseyNumber: jersey(99), Position: models.Skater, Sub: true}, false))
+	assert.ErrorIs(t, CheckAdd(players, models.LineupPlayer{UserID: 10, Position: models.Skater}, false), ErrAlreadyInLineup)
+	assert.ErrorIs(t, CheckAdd(players, models.LineupPlayer{UserID: 20, Position: models.Skater}, true), ErrSuspended)
+	assert.ErrorIs(t, CheckAdd(players, models.LineupPlayer{UserID: 20, JerseyNumber: jersey(30), Position: models.Skater}, false), ErrJerseyTaken)
+	assert.ErrorIs(t, CheckAdd(players, models.LineupPlayer{UserID: 20, JerseyNumber: jersey(100), Position: models.Skater}, false), roster.ErrInvalidJersey)
+	assert.ErrorIs(t, CheckAdd(players, models.LineupPlayer{UserID: 20, Position: "wing"}, false), roster.ErrInvalidPosition)
+
+	full := make([]models.LineupPlayer, 0, MaxPlayers)
+	for i := range MaxPlayers {
+		full = append(full, models.LineupPlayer{UserID: uint(100 + i), Position: models.Skater})
+	}
+	assert.ErrorIs(t, CheckAdd(full, models.LineupPlayer{UserID: 20, Position: models.Skater}, false), ErrLineupFull)
+}
+
+func TestCheckUpdate(t *testing.T) {
+	players := testLineup()
+
+	assert.NoError(t, CheckUpdate(players, models.LineupPlayer{UserID: 10, JerseyNumber: jersey(9), Position: models.Goalie}))
+	assert.ErrorIs(t, CheckUpdate(players, models.LineupPlayer{UserID: 10, JerseyNumber: jersey(30), Position: models.Skater}), ErrJerseyTaken)
+	assert.ErrorIs(t, CheckUpdate(players, models.LineupPlayer{UserID: 20, Position: models.Skater}), ErrNotInLineup)
+}
+
+func TestIsRuleViolation(t *testing.T) {
+	assert.True(t, IsRuleViolation(CheckAdd(testLineup(), models.LineupPlayer{UserID: 20, JerseyNumber: jersey(9), Position: models.Skater}, false)))
+	assert.True(t, IsRuleViolation(roster.ErrInvalidPosition))
+	assert.False(t, IsRuleViolation(ErrNotCaptain), "permission errors are reported separately")
+	assert.False(t, IsRuleViolation(nil))
+}
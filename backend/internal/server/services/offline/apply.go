@@ -0,0 +1,122 @@
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jak103/powerplay/internal/server/services/events"
+)
+
+// EventKind is how ApplyEvent loads, checks and stores one kind of game event
+type EventKind[T any] struct {
+	Get         func(id uint) (*T, error)
+	GetByClient func(gameId uint, clientId string) (*T, error)
+	Validate    func(event *T, game events.Game) (events.Errors, error)
+	Create      func(event *T) error
+	Update      func(event *T) (*T, error)
+	Delete      func(id uint) (*T, error)
+	// Identify sets the fields of an event the device isn't allowed to change
+	Identify func(event *T, id, gameId uint, clientId string)
+	Describe func(event *T) (id, gameId uint, clientId string, updatedAt time.Time)
+}
+
+// ApplyEvent creates, updates or deletes one goal, penalty or shot. Creates are matched on the
+// device's client ID within the game so retries aren't recorded twice. Updates and deletes are
+// refused as a conflict when the event changed after the device last saw it.
+func ApplyEvent[T any](op Operation, game events.Game, kind EventKind[T]) (Result, error) {
+	result := Result{OpID: op.OpID, Kind: op.Kind, Action: op.Action, ClientID: op.ClientID}
+
+	if op.Action == Create {
+		existing, err := kind.GetByClient(game.Game.ID, op.ClientID)
+		if err != nil {
+			return result, err
+		}
+		if existing != nil {
+			result.EventID, _, _, _ = kind.Describe(existing)
+			result.Outcome = Duplicate
+			return result, nil
+		}
+
+		event := new(T)
+		if err := json.Unmarshal(op.Data, event); err != nil {
+			result.Outcome = Invalid
+			result.Message = "Failed to parse data"
+			return result, nil
+		}
+		kind.Identify(event, 0, game.Game.ID, op.ClientID)
+
+		return store(result, event, game, kind, kind.Create)
+	}
+
+	var existing *T
+	var err error
+	if op.EventID != 0 {
+		existing, err = kind.Get(op.EventID)
+	} else {
+		existing, err = kind.GetByClient(game.Game.ID, op.ClientID)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	var id, gameId uint
+	var clientId string
+	var updatedAt time.Time
+	if existing != nil {
+		id, gameId, clientId, updatedAt = kind.Describe(existing)
+	}
+	if existing == nil || gameId != game.Game.ID {
+		result.Outcome = NotFound
+		result.Message = fmt.Sprintf("The %v isn't recorded for this game", op.Kind)
+		return result, nil
+	}
+	result.EventID, result.ClientID = id, clientId
+
+	if Stale(updatedAt, op.LastSeen) {
+		result.Outcome = Conflict
+		result.Message = fmt.Sprintf("The %v was changed at %v, after this device last saw it", op.Kind, updatedAt.Format(time.RFC3339))
+		result.Current = existing
+		return result, nil
+	}
+
+	if op.Action == Delete {
+		if _, err := kind.Delete(id); err != nil {
+			return result, err
+		}
+		result.Outcome = Applied
+		return result, nil
+	}
+
+	if err := json.Unmarshal(op.Data, existing); err != nil {
+		result.Outcome = Invalid
+		result.Message = "Failed to parse data"
+		return result, nil
+	}
+	kind.Identify(existing, id, gameId, clientId)
+
+	return store(result, existing, game, kind, func(event *T) error {
+		_, err := kind.Update(event)
+		return err
+	})
+}
+
+// store validates an event and saves it when it's valid
+func store[T any](result Result, event *T, game events.Game, kind EventKind[T], save func(*T) error) (Result, error) {
+	errs, err := kind.Validate(event, game)
+	if err != nil {
+		return result, err
+	}
+	if errs != nil {
+		result.Outcome = Invalid
+		result.Errors = errs
+		return result, nil
+	}
+
+	if err := save(event); err != nil {
+		return result, err
+	}
+	result.EventID, _, _, _ = kind.Describe(event)
+	result.Outcome = Applied
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/events"
+)
+
+// Kind is what an operation changes
+type Kind string
+
+const (
+	Goal    Kind = "goal"
+	Penalty Kind = "penalty"
+	Shot    Kind = "shot"
+	Status  Kind = "status" // The game's status
+)
+
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Outcome is what happened to an operation
+type Outcome string
+
+const (
+	Applied   Outcome = "applied"
+	Duplicate Outcome = "duplicate" // Already applied by an earlier upload, nothing changed
+	Conflict  Outcome = "conflict"  // Another official changed it since the device last synced
+	Invalid   Outcome = "invalid"
+	NotFound  Outcome = "not_found"
+)
+
+// MaxBatch is the most operations a device can upload at once
+const MaxBatch = 500
+
+// Operation is a change queued on a scorekeeper's device while it was offline. OpID and, for
+// created events, ClientID are generated on the device so retried uploads are only applied once.
+// Updates and deletes name the event by EventID or ClientID and carry LastSeen, the updated_at
+// of the event when the device last synced it.
+type Operation struct {
+	OpID     string          `json:"op_id"`
+	Kind     Kind            `json:"kind"`
+	Action   Action          `json:"action"`
+	ClientID string          `json:"client_id"`
+	EventID  uint            `json:"event_id"`
+	LastSeen *time.Time      `json:"last_seen"`
+	Data     json.RawMessage `json:"data"`   // The goal, penalty or shot; fields left out of an update keep their values
+	Reason   string          `json:"reason"` // Why an event was amended or voided, kept in the event log
+
+	Status         models.Status `json:"status"`
+	ExpectedStatus models.Status `json:"expected_status"` // The status the device saw before changing it
+}
+
+// Result reports what happened to one operation
+type Result struct {
+	OpID     string        `json:"op_id"`
+	Kind     Kind          `json:"kind"`
+	Action   Action        `json:"action"`
+	ClientID string        `json:"client_id,omitempty"`
+	EventID  uint          `json:"event_id,omitempty"`
+	Outcome  Outcome       `json:"outcome"`
+	Message  string        `json:"message,omitempty"`
+	Errors   events.Errors `json:"errors,omitempty"`
+	Current  any           `json:"current,omitempty"` // The server's copy when there's a conflict
+}
+
+// State is the authoritative state of a game returned after every sync
+type State struct {
+	Game      *models.Game        `json:"game"`
+	Goals     []models.Goal       `json:"goals"`
+	Penalties []models.Penalty    `json:"penalties"`
+	Shots     []models.ShotOnGoal `json:"shots"`
+}
+
+// Check makes sure an operation is complete enough to be applied
+func Check(op Operation) error {
+	if op.OpID == "" {
+		return fmt.Errorf("op_id is required")
+	}
+
+	switch op.Kind {
+	case Status:
+		if op.Status == "" {
+			return fmt.Errorf("status is required")
+		}
+		if !CanTransition(op.ExpectedStatus, op.Status) {
+			return fmt.Errorf("a game can't go from %q to %q", op.ExpectedStatus, op.Status)
+		}
+		return nil
+	case Goal, Penalty, Shot:
+	default:
+		return fmt.Errorf("unknown kind %q", op.Kind)
+	}
+
+	switch op.Action {
+	case Create:
+		if op.ClientID == "" {
+			return fmt.Errorf("client_id is required to create a %v", op.Kind)
+		}
+		if len(op.Data) == 0 {
+			return fmt.Errorf("data is required to create a %v", op.Kind)
+		}
+	case Update, Delete:
+		if op.EventID == 0 && op.ClientID == "" {
+			return fmt.Errorf("event_id or client_id is required to %v a %v", op.Action, op.Kind)
+		}
+		if op.LastSeen == nil {
+			return fmt.Errorf("last_seen is required to %v a %v", op.Action, op.Kind)
+		}
+		if op.Action == Update && len(op.Data) == 0 {
+			return fmt.Errorf("data is required to update a %v", op.Kind)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", op.Action)
+	}
+	return nil
+}
+
+// Stale reports whether an event was changed after the device last saw it. The database keeps
+// microseconds, so the device's copy is compared at that precision.
+func Stale(updatedAt time.Time, lastSeen *time.Time) bool {
+	if lastSeen == nil {
+		return true
+	}
+	return updatedAt.Truncate(time.Microsecond).After(lastSeen.Truncate(time.Microsecond))
+}
+
+// CanTransition reports whether a scorekeeper can move a game from one status to another.
+// Games only move forward; reopening a final game is left to managers.
+func CanTransition(from, to models.Status) bool {
+	switch from {
+	case models.SCHEDULED:
+		return to == models.IN_PROGRESS || to == models.FINAL
+	case models.IN_PROGRESS:
+		return to == models.FINAL
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package offline
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	seen := time.Now()
+	data := json.RawMessage(`{"team_id": 1}`)
+
+	var tests = []struct {
+		name  string
+		input Operation
+		valid bool
+	}{
+		{"Create", Operation{OpID: "a", Kind: Goal, Action: Create, ClientID: "g1", Data: data}, true},
+		{"Create without client id", Operation{OpID: "a", Kind: Goal, Action: Create, Data: data}, false},
+		{"Create without data", Operation{OpID: "a", Kind: Shot, Action: Create, ClientID: "s1"}, false},
+		{"Missing op id", Operation{Kind: Goal, Action: Create, ClientID: "g1", Data: data}, false},
+		{"Unknown kind", Operation{OpID: "a", Kind: "assist", Action: Create, ClientID: "g1", Data: data}, false},
+		{"Unknown action", Operation{OpID: "a", Kind: Goal, Action: "upsert", ClientID: "g1", Data: data}, false},
+		{"Update by event id", Operation{OpID: "a", Kind: Penalty, Action: Update, EventID: 3, LastSeen: &seen, Data: data}, true},
+		{"Update without last seen", Operation{OpID: "a", Kind: Penalty, Action: Update, EventID: 3, Data: data}, false},
+		{"Delete by client id", Operation{OpID: "a", Kind: Shot, Action: Delete, ClientID: "s1", LastSeen: &seen}, true},
+		{"Delete without an event", Operation{OpID: "a", Kind: Shot, Action: Delete, LastSeen: &seen}, false},
+		{"Start game", Operation{OpID: "a", Kind: Status, Status: models.IN_PROGRESS, ExpectedStatus: models.SCHEDULED}, true},
+		{"Reopen game", Operation{OpID: "a", Kind: Status, Status: models.IN_PROGRESS, ExpectedStatus: models.FINAL}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.input)
+			assert.Equal(t, tt.valid, err == nil, "Check(%+v) = %v", tt.input, err)
+		})
+	}
+}
+
+func TestStale(t *testing.T) {
+	updated := time.Date(2024, 10, 1, 19, 30, 0, 123456000, time.UTC)
+	echoed := updated.Add(789) // Nanoseconds the database doesn't keep
+	earlier := updated.Add(-time.Second)
+
+	assert.False(t, Stale(updated, &updated))
+	assert.False(t, Stale(updated, &echoed))
+	assert.True(t, Stale(updated, &earlier))
+	assert.True(t, Stale(updated, nil))
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(models.SCHEDULED, models.IN_PROGRESS))
+	assert.True(t, CanTransition(models.IN_PROGRESS, models.FINAL))
+	assert.False(t, CanTransition(models.FINAL, models.IN_PROGRESS))
+	assert.False(t, CanTransition(models.IN_PROGRESS, models.SCHEDULED))
+}
+
+// shots is an in-memory store of shots for ApplyEvent
+type shots map[uint]*models.ShotOnGoal
+
+func (s shots) kind() EventKind[models.ShotOnGoal] {
+	get := func(id uint) (*models.ShotOnGoal, error) {
+		if shot, ok := s[id]; ok {
+			copied := *shot
+			return &copied, nil
+		}
+		return nil, nil
+	}
+	return EventKind[models.ShotOnGoal]{
+		Get: get,
+		GetByClient: func(gameId uint, clientId string) (*models.ShotOnGoal, error) {
+			for id, shot := range s {
+				if shot.GameId == gameId && shot.ClientID == clientId {
+					return get(id)
+				}
+			}
+			return nil, nil
+		},
+		Validate: func(shot *models.ShotOnGoal, game events.Game) (events.Errors, error) {
+			return events.ValidateShot(shot, game), nil
+		},
+		Create: func(shot *models.ShotOnGoal) error {
+			shot.ID = uint(len(s) + 1)
+			s[shot.ID] = shot
+			return nil
+		},
+		Update: func(shot *models.ShotOnGoal) (*models.ShotOnGoal, error) {
+			s[shot.ID] = shot
+			return shot, nil
+		},
+		Delete: func(id uint) (*models.ShotOnGoal, error) {
+			shot := s[id]
+			delete(s, id)
+			return shot, nil
+		},
+		Identify: func(shot *models.ShotOnGoal, id, gameId uint, clientId string) {
+			shot.ID, shot.GameId, shot.ClientID = id, gameId, clientId
+		},
+		Describe: func(shot *models.ShotOnGoal) (uint, uint, string, time.Time) {
+			return shot.ID, shot.GameId, shot.ClientID, shot.UpdatedAt
+		},
+	}
+}
+
+func TestApplyEvent(t *testing.T) {
+	game := events.Game{Game: &models.Game{HomeTeamID: 1, AwayTeamID: 2}}
+	game.Game.ID = 7
+	seen := time.Date(2024, 10, 1, 19, 30, 0, 0, time.UTC)
+
+	recorded := shots{}
+	other := &models.ShotOnGoal{GameId: 8, TeamId: 1, Period: 1, ClientID: "s1"}
+	other.ID = 1
+	recorded[1] = other
+	kind := recorded.kind()
+
+	// The same client ID in another game is a different shot
+	create := Operation{OpID: "a", Kind: Shot, Action: Create, ClientID: "s1", Data: json.RawMessage(`{"team_id": 1, "period": 1, "shot_time": 30}`)}
+	result, err := ApplyEvent(create, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Applied, result.Outcome)
+	assert.Equal(t, uint(2), result.EventID)
+	assert.Equal(t, uint(7), recorded[2].GameId)
+
+	result, err = ApplyEvent(create, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Duplicate, result.Outcome)
+	assert.Equal(t, uint(2), result.EventID)
+	assert.Len(t, recorded, 2)
+
+	invalid := Operation{OpID: "b", Kind: Shot, Action: Create, ClientID: "s2", Data: json.RawMessage(`{"team_id": 3, "period": 1}`)}
+	result, err = ApplyEvent(invalid, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Invalid, result.Outcome)
+	assert.NotEmpty(t, result.Errors)
+
+	recorded[2].UpdatedAt = seen
+	update := Operation{OpID: "c", Kind: Shot, Action: Update, ClientID: "s1", LastSeen: &seen, Data: json.RawMessage(`{"shot_time": 45}`)}
+	result, err = ApplyEvent(update, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Applied, result.Outcome)
+	assert.Equal(t, uint(45), recorded[2].ShotTime)
+	assert.Equal(t, "s1", recorded[2].ClientID)
+
+	recorded[2].UpdatedAt = seen.Add(time.Minute)
+	result, err = ApplyEvent(update, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Conflict, result.Outcome)
+	assert.NotNil(t, result.Current)
+
+	remove := Operation{OpID: "d", Kind: Shot, Action: Delete, EventID: 1, LastSeen: &seen}
+	result, err = ApplyEvent(remove, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, NotFound, result.Outcome, "a shot in another game can't be deleted through this one")
+	assert.Contains(t, recorded, uint(1))
+
+	remove.EventID, remove.LastSeen = 2, &recorded[2].UpdatedAt
+	result, err = ApplyEvent(remove, game, kind)
+	require.NoError(t, err)
+	assert.Equal(t, Applied, result.Outcome)
+	assert.NotContains(t, recorded, uint(2))
+}
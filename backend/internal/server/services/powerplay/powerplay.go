@@ -0,0 +1,315 @@
+package powerplay
+
+import (
+	"sort"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// Strength is the manpower situation of the scoring team when a goal was scored
+type Strength string
+
+const (
+	PowerPlay    Strength = "PP"
+	ShortHanded  Strength = "SH"
+	EvenStrength Strength = "EV"
+)
+
+const (
+	fullStrength = 5 // Skaters on the ice without penalties
+	minStrength  = 3 // A team can never be reduced below three skaters
+)
+
+// GoalStrength is how a goal was classified
+type GoalStrength struct {
+	GoalID             uint     `json:"goal_id"`
+	Strength           Strength `json:"strength"`
+	PlayerDifferential int      `json:"player_differential"` // Scoring team's skaters less the opponent's
+}
+
+// Window is a stretch of a game in which a team had a man advantage
+type Window struct {
+	TeamID uint `json:"team_id"` // The team on the power play
+	Start  uint `json:"start"`   // Seconds since puck drop
+	End    uint `json:"end"`
+}
+
+// TeamLine is a team's special teams record
+type TeamLine struct {
+	TeamID                  uint    `json:"team_id"`
+	TeamName                string  `json:"team_name,omitempty"`
+	PowerPlayOpportunities  int     `json:"power_play_opportunities"`
+	PowerPlayGoals          int     `json:"power_play_goals"`
+	PowerPlayPercentage     float64 `json:"power_play_percentage"`
+	TimesShortHanded        int     `json:"times_short_handed"`
+	PowerPlayGoalsAgainst   int     `json:"power_play_goals_against"`
+	PenaltyKillPercentage   float64 `json:"penalty_kill_percentage"`
+	ShortHandedGoals        int     `json:"short_handed_goals"`
+	ShortHandedGoalsAgainst int     `json:"short_handed_goals_against"`
+}
+
+// Served is when a penalty that left its team short handed started and ended, in seconds since
+// puck drop. Minors end early when the other team scores on the power play.
+type Served struct {
+	PenaltyID uint `json:"penalty_id"`
+	TeamID    uint `json:"team_id"`
+	Start     uint `json:"start"`
+	End       uint `json:"end"`
+}
+
+// Analysis is the special teams breakdown of a single game
+type Analysis struct {
+	GameID    uint           `json:"game_id"`
+	Goals     []GoalStrength `json:"goals"`
+	Windows   []Window       `json:"windows"`
+	Teams     []TeamLine     `json:"teams"`
+	Penalties []Served       `json:"penalties"`
+}
+
+// Game is what the analysis needs to know about a game. Penalties must have their type loaded.
+type Game struct {
+	GameID     uint
+	HomeTeamID uint
+	AwayTeamID uint
+	Penalties  []models.Penalty
+	Goals      []models.Goal
+}
+
+type served struct {
+	penaltyId uint
+	teamId    uint
+	start     uint
+	end       uint
+	expirable bool // Minors end early when the other team scores
+}
+
+// Length is how long a penalty keeps a team short handed, in seconds. Misconducts and game
+// misconducts are served without affecting strength, so they have no length here.
+func Length(penalty models.Penalty) (uint, bool) {
+	switch penalty.PenaltyType.Severity {
+	case "minor", "major", "match":
+	default:
+		return 0, false
+	}
+
+	if penalty.Duration > 0 {
+		return penalty.Duration, penalty.PenaltyType.Severity == "minor"
+	}
+	return penalty.PenaltyType.Duration * 60, penalty.PenaltyType.Severity == "minor"
+}
+
+// Analyze works out the man advantage windows of a game from its penalties, classifies every goal
+// as power play, short handed or even strength, and counts each team's special teams record.
+// Penalties called at the same time against both teams with the same length cancel out.
+func Analyze(game Game) Analysis {
+	penalties := servedPenalties(game.Penalties)
+
+	goals := make([]models.Goal, len(game.Goals))
+	copy(goals, game.Goals)
+	sort.SliceStable(goals, func(i, j int) bool {
+		return models.GameSeconds(goals[i].Period, goals[i].Duration) < models.GameSeconds(goals[j].Period, goals[j].Duration)
+	})
+
+	analysis := Analysis{
+		GameID: game.GameID,
+		Goals:  make([]GoalStrength, 0, len(goals)),
+	}
+	lines := map[uint]*TeamLine{
+		game.HomeTeamID: {TeamID: game.HomeTeamID},
+		game.AwayTeamID: {TeamID: game.AwayTeamID},
+	}
+
+	for _, goal := range goals {
+		at := models.GameSeconds(goal.Period, goal.Duration)
+		opponent := game.HomeTeamID
+		if goal.TeamId == game.HomeTeamID {
+			opponent = game.AwayTeamID
+		}
+
+		differential := skaters(penalties, goal.TeamId, at) - skaters(penalties, opponent, at)
+		strength := EvenStrength
+		switch {
+		case differential > 0:
+			strength = PowerPlay
+			expireEarliestMinor(penalties, opponent, at)
+		case differential < 0:
+			strength = ShortHanded
+		}
+
+		analysis.Goals = append(analysis.Goals, GoalStrength{
+			GoalID:             goal.ID,
+			Strength:           strength,
+			PlayerDifferential: differential,
+		})
+
+		if scorer, ok := lines[goal.TeamId]; ok {
+			switch strength {
+			case PowerPlay:
+				scorer.PowerPlayGoals++
+				lines[opponent].PowerPlayGoalsAgainst++
+			case ShortHanded:
+				scorer.ShortHandedGoals++
+				lines[opponent].ShortHandedGoalsAgainst++
+			}
+		}
+	}
+
+	analysis.Windows = windows(penalties, game.HomeTeamID, game.AwayTeamID)
+	for _, window := range analysis.Windows {
+		lines[window.TeamID].PowerPlayOpportunities++
+		if window.TeamID == game.HomeTeamID {
+			lines[game.AwayTeamID].TimesShortHanded++
+		} else {
+			lines[game.HomeTeamID].TimesShortHanded++
+		}
+	}
+
+	analysis.Penalties = make([]Served, 0, len(penalties))
+	for _, p := range penalties {
+		analysis.Penalties = append(analysis.Penalties, Served{PenaltyID: p.penaltyId, TeamID: p.teamId, Start: p.start, End: p.end})
+	}
+
+	analysis.Teams = []TeamLine{*lines[game.HomeTeamID], *lines[game.AwayTeamID]}
+	for i := range analysis.Teams {
+		Finish(&analysis.Teams[i])
+	}
+	return analysis
+}
+
+// Finish fills in the percentages of a team line from its counts
+func Finish(line *TeamLine) {
+	line.PowerPlayPercentage = 0
+	if line.PowerPlayOpportunities > 0 {
+		line.PowerPlayPercentage = float64(line.PowerPlayGoals) / float64(line.PowerPlayOpportunities)
+	}
+	line.PenaltyKillPercentage = 0
+	if line.TimesShortHanded > 0 {
+		line.PenaltyKillPercentage = float64(line.TimesShortHanded-line.PowerPlayGoalsAgainst) / float64(line.TimesShortHanded)
+	}
+}
+
+// Season adds up the special teams records of several games per team
+func Season(games []Game) []TeamLine {
+	totals := make(map[uint]*TeamLine)
+	order := make([]uint, 0)
+	for _, game := range games {
+		for _, line := range Analyze(game).Teams {
+			total, ok := totals[line.TeamID]
+			if !ok {
+				total = &TeamLine{TeamID: line.TeamID}
+				totals[line.TeamID] = total
+				order = append(order, line.TeamID)
+			}
+			total.PowerPlayOpportunities += line.PowerPlayOpportunities
+			total.PowerPlayGoals += line.PowerPlayGoals
+			total.TimesShortHanded += line.TimesShortHanded
+			total.PowerPlayGoalsAgainst += line.PowerPlayGoalsAgainst
+			total.ShortHandedGoals += line.ShortHandedGoals
+			total.ShortHandedGoalsAgainst += line.ShortHandedGoalsAgainst
+		}
+	}
+
+	lines := make([]TeamLine, 0, len(order))
+	for _, teamId := range order {
+		Finish(totals[teamId])
+		lines = append(lines, *totals[teamId])
+	}
+	return lines
+}
+
+func servedPenalties(penalties []models.Penalty) []*served {
+	result := make([]*served, 0, len(penalties))
+	for _, penalty := range penalties {
+		length, expirable := Length(penalty)
+		if length == 0 {
+			continue
+		}
+		start := models.GameSeconds(penalty.Period, penalty.GameTime)
+		result = append(result, &served{penaltyId: penalty.ID, teamId: penalty.TeamID, start: start, end: start + length, expirable: expirable})
+	}
+
+	// Coincidental penalties: pair off equal penalties called at the same time against opposing teams
+	cancelled := make(map[*served]bool)
+	for _, a := range result {
+		if cancelled[a] {
+			continue
+		}
+		for _, b := range result {
+			if cancelled[b] || a.teamId == b.teamId || a.start != b.start || a.end != b.end {
+				continue
+			}
+			cancelled[a], cancelled[b] = true, true
+			break
+		}
+	}
+
+	kept := make([]*served, 0, len(result))
+	for _, p := range result {
+		if !cancelled[p] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func skaters(penalties []*served, teamId, at uint) int {
+	inBox := 0
+	for _, p := range penalties {
+		if p.teamId == teamId && p.start <= at && at < p.end {
+			inBox++
+		}
+	}
+	return max(fullStrength-inBox, minStrength)
+}
+
+// expireEarliestMinor releases the minor closest to expiring when a power play goal is scored
+func expireEarliestMinor(penalties []*served, teamId, at uint) {
+	var earliest *served
+	for _, p := range penalties {
+		if p.teamId != teamId || !p.expirable || p.start > at || at >= p.end {
+			continue
+		}
+		if earliest == nil || p.end < earliest.end {
+			earliest = p
+		}
+	}
+	if earliest != nil {
+		earliest.end = at
+	}
+}
+
+// windows finds each continuous stretch where one team had more skaters than the other
+func windows(penalties []*served, homeTeamId, awayTeamId uint) []Window {
+	boundaries := make([]uint, 0, len(penalties)*2)
+	for _, p := range penalties {
+		boundaries = append(boundaries, p.start, p.end)
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+
+	result := make([]Window, 0)
+	var current *Window
+	for i, at := range boundaries {
+		if i > 0 && at == boundaries[i-1] {
+			continue
+		}
+
+		var advantage uint
+		home, away := skaters(penalties, homeTeamId, at), skaters(penalties, awayTeamId, at)
+		switch {
+		case home > away:
+			advantage = homeTeamId
+		case away > home:
+			advantage = awayTeamId
+		}
+
+		if current != nil && current.TeamID != advantage {
+			current.End = at
+			result = append(result, *current)
+			current = nil
+		}
+		if current == nil && advantage != 0 {
+			current = &Window{TeamID: advantage, Start: at}
+		}
+	}
+	return result
+}
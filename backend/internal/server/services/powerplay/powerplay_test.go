@@ -0,0 +1,123 @@
+package powerplay
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	home uint = 1
+	away uint = 2
+)
+
+var (
+	minor      = models.PenaltyType{Name: "Tripping", Duration: 2, Severity: "minor"}
+	major      = models.PenaltyType{Name: "Fighting", Duration: 5, Severity: "major"}
+	misconduct = models.PenaltyType{Name: "Unsportsmanlike Conduct", Duration: 10, Severity: "misconduct"}
+)
+
+func penalty(teamId uint, period, gameTime uint, penaltyType models.PenaltyType) models.Penalty {
+	return models.Penalty{TeamID: teamId, Period: period, GameTime: gameTime, PenaltyType: penaltyType}
+}
+
+func goal(id, teamId uint, period, duration uint) models.Goal {
+	g := models.Goal{TeamId: teamId, Period: period, Duration: duration}
+	g.ID = id
+	return g
+}
+
+func TestPowerPlayGoalEndsMinor(t *testing.T) {
+	game := Game{
+		GameID:     1,
+		HomeTeamID: home,
+		AwayTeamID: away,
+		Penalties:  []models.Penalty{penalty(away, 1, 100, minor)},
+		Goals: []models.Goal{
+			goal(1, home, 1, 130), // Power play goal, ends the minor
+			goal(2, home, 1, 160), // Back to even strength
+		},
+	}
+
+	analysis := Analyze(game)
+	require.Len(t, analysis.Goals, 2)
+	assert.Equal(t, PowerPlay, analysis.Goals[0].Strength)
+	assert.Equal(t, 1, analysis.Goals[0].PlayerDifferential)
+	assert.Equal(t, EvenStrength, analysis.Goals[1].Strength)
+
+	require.Len(t, analysis.Windows, 1)
+	assert.Equal(t, Window{TeamID: home, Start: 100, End: 130}, analysis.Windows[0])
+	assert.Equal(t, []Served{{TeamID: away, Start: 100, End: 130}}, analysis.Penalties)
+
+	assert.Equal(t, 1, analysis.Teams[0].PowerPlayOpportunities)
+	assert.Equal(t, 1, analysis.Teams[0].PowerPlayGoals)
+	assert.Equal(t, 1.0, analysis.Teams[0].PowerPlayPercentage)
+	assert.Equal(t, 1, analysis.Teams[1].TimesShortHanded)
+	assert.Equal(t, 0.0, analysis.Teams[1].PenaltyKillPercentage)
+}
+
+func TestMajorDoesNotEndOnGoal(t *testing.T) {
+	game := Game{
+		HomeTeamID: home,
+		AwayTeamID: away,
+		Penalties:  []models.Penalty{penalty(away, 1, 0, major)},
+		Goals:      []models.Goal{goal(1, home, 1, 30), goal(2, home, 1, 60), goal(3, away, 1, 90)},
+	}
+
+	analysis := Analyze(game)
+	assert.Equal(t, PowerPlay, analysis.Goals[0].Strength)
+	assert.Equal(t, PowerPlay, analysis.Goals[1].Strength)
+	assert.Equal(t, ShortHanded, analysis.Goals[2].Strength)
+	assert.Equal(t, -1, analysis.Goals[2].PlayerDifferential)
+	assert.Equal(t, 2, analysis.Teams[0].PowerPlayGoals)
+	assert.Equal(t, 1, analysis.Teams[1].ShortHandedGoals)
+	assert.Equal(t, []Window{{TeamID: home, Start: 0, End: 300}}, analysis.Windows)
+}
+
+func TestFiveOnThreeOnlyReleasesOneMinor(t *testing.T) {
+	game := Game{
+		HomeTeamID: home,
+		AwayTeamID: away,
+		Penalties:  []models.Penalty{penalty(away, 2, 0, minor), penalty(away, 2, 30, minor)},
+		Goals:      []models.Goal{goal(1, home, 2, 60), goal(2, home, 2, 90)},
+	}
+
+	analysis := Analyze(game)
+	assert.Equal(t, 2, analysis.Goals[0].PlayerDifferential)
+	assert.Equal(t, PowerPlay, analysis.Goals[1].Strength)
+	assert.Equal(t, 1, analysis.Goals[1].PlayerDifferential)
+
+	// The whole two-man then one-man advantage is a single window
+	require.Len(t, analysis.Windows, 1)
+	assert.Equal(t, uint(models.PeriodLength+90), analysis.Windows[0].End)
+}
+
+func TestCoincidentalPenaltiesCancel(t *testing.T) {
+	game := Game{
+		HomeTeamID: home,
+		AwayTeamID: away,
+		Penalties:  []models.Penalty{penalty(home, 1, 50, minor), penalty(away, 1, 50, minor), penalty(home, 1, 50, misconduct)},
+		Goals:      []models.Goal{goal(1, home, 1, 60)},
+	}
+
+	analysis := Analyze(game)
+	assert.Equal(t, EvenStrength, analysis.Goals[0].Strength)
+	assert.Empty(t, analysis.Windows)
+}
+
+func TestSeasonTotals(t *testing.T) {
+	games := []Game{
+		{HomeTeamID: home, AwayTeamID: away, Penalties: []models.Penalty{penalty(away, 1, 0, minor)}, Goals: []models.Goal{goal(1, home, 1, 10)}},
+		{HomeTeamID: away, AwayTeamID: home, Penalties: []models.Penalty{penalty(away, 1, 0, minor)}},
+	}
+
+	lines := Season(games)
+	require.Len(t, lines, 2)
+	assert.Equal(t, home, lines[0].TeamID)
+	assert.Equal(t, 2, lines[0].PowerPlayOpportunities)
+	assert.Equal(t, 0.5, lines[0].PowerPlayPercentage)
+	assert.Equal(t, 2, lines[1].TimesShortHanded)
+	assert.Equal(t, 0.5, lines[1].PenaltyKillPercentage)
+}
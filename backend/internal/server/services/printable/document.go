@@ -0,0 +1,72 @@
+package printable
+
+import (
+	"fmt"
+	"io"
+)
+
+// Format is an output format a document can be rendered in
+type Format string
+
+const (
+	HTML Format = "html"
+	PDF  Format = "pdf"
+)
+
+// ParseFormat reads a format from a request, defaulting to HTML
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", HTML:
+		return HTML, nil
+	case PDF:
+		return PDF, nil
+	}
+	return "", fmt.Errorf("format must be %v or %v", HTML, PDF)
+}
+
+// ContentType is the MIME type of a format
+func (f Format) ContentType() string {
+	if f == PDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// Document is a printable page layout: a title followed by sections of labelled fields and tables
+type Document struct {
+	Title    string
+	Subtitle string
+	Sections []Section
+}
+
+// Section is a headed part of a document
+type Section struct {
+	Heading string
+	Fields  []Field
+	Tables  []Table
+}
+
+// Field is a labelled value, such as the venue of a game
+type Field struct {
+	Label string
+	Value string
+}
+
+// Table is a grid of cells. BlankRows empty rows are added after Rows to be filled in by hand.
+type Table struct {
+	Title     string
+	Columns   []string
+	Rows      [][]string
+	BlankRows int
+}
+
+// Render writes a document in the given format
+func Render(w io.Writer, doc Document, format Format) error {
+	switch format {
+	case HTML:
+		return renderHTML(w, doc)
+	case PDF:
+		return renderPDF(w, doc)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,60 @@
+package printable
+
+import (
+	"html/template"
+	"io"
+)
+
+// blank gives the template something to range over for each row left empty
+func blank(n int) []struct{} {
+	return make([]struct{}, n)
+}
+
+var page = template.Must(template.New("page").Funcs(template.FuncMap{"blank": blank}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 10pt; margin: 2em; }
+h1 { font-size: 16pt; margin-bottom: 0; }
+h2 { font-size: 12pt; border-bottom: 1px solid #000; margin-top: 1.5em; }
+h3 { font-size: 10pt; margin-bottom: 0.3em; }
+.subtitle { color: #444; margin-top: 0.2em; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.2em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #000; padding: 0.2em 0.4em; text-align: left; height: 1.3em; }
+th { background: #e6e6e6; }
+@media print {
+	body { margin: 0; }
+	section { break-inside: avoid-page; }
+	tr { break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Subtitle}}<p class="subtitle">{{.Subtitle}}</p>{{end}}
+{{range .Sections}}<section>
+{{if .Heading}}<h2>{{.Heading}}</h2>{{end}}
+{{if .Fields}}<dl>
+{{range .Fields}}<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{end}}</dl>{{end}}
+{{range .Tables}}{{if .Title}}<h3>{{.Title}}</h3>{{end}}
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}{{$columns := .Columns}}{{range blank .BlankRows}}<tr>{{range $columns}}<td></td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, doc Document) error {
+	return page.Execute(w, doc)
+}
@@ -0,0 +1,280 @@
+package printable
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Pages are US Letter, measured in points from the top left corner. PDF measures from the
+// bottom left, so positions are flipped when they're written out.
+const (
+	pageWidth  = 612.0
+	pageHeight = 792.0
+	margin     = 40.0
+	rowHeight  = 14.0
+	cellPad    = 3.0
+	tableSize  = 9.0
+	minColumn  = 24.0
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in the standard Helvetica
+// font, in thousandths of the font size, starting at the space
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdf lays a document out on pages using the standard Helvetica fonts, which every PDF reader
+// has, so nothing needs to be embedded
+type pdf struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // Top of the next line
+}
+
+func renderPDF(w io.Writer, doc Document) error {
+	p := &pdf{}
+	p.newPage()
+
+	p.text(margin, p.y+16, 16, true, doc.Title)
+	p.y += 22
+	if doc.Subtitle != "" {
+		p.text(margin, p.y+10, 10, false, doc.Subtitle)
+		p.y += 14
+	}
+
+	for _, section := range doc.Sections {
+		if section.Heading != "" {
+			p.ensure(36 + rowHeight*2)
+			p.y += 12
+			p.text(margin, p.y+12, 12, true, section.Heading)
+			p.y += 16
+			fmt.Fprintf(p.page, "%.2f %.2f m %.2f %.2f l S\n", margin, pageHeight-p.y, pageWidth-margin, pageHeight-p.y)
+			p.y += 6
+		}
+		p.fields(section.Fields)
+		for _, table := range section.Tables {
+			p.table(table)
+		}
+	}
+
+	return p.write(w)
+}
+
+func (p *pdf) newPage() {
+	p.page = &bytes.Buffer{}
+	p.page.WriteString("0.5 w\n")
+	p.pages = append(p.pages, p.page)
+	p.y = margin
+}
+
+// ensure starts a new page when the next height points don't fit on this one
+func (p *pdf) ensure(height float64) {
+	if p.y+height > pageHeight-margin {
+		p.newPage()
+	}
+}
+
+// text writes a line of text with its baseline at y
+func (p *pdf) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT /%s %.1f Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj ET\n", font, size, x, pageHeight-y, escape(encode(s)))
+}
+
+func (p *pdf) fields(fields []Field) {
+	labelWidth := 0.0
+	for _, field := range fields {
+		labelWidth = max(labelWidth, width(field.Label, 10))
+	}
+	for _, field := range fields {
+		p.ensure(rowHeight)
+		p.text(margin, p.y+10, 10, true, field.Label)
+		x := margin + labelWidth + 12
+		p.text(x, p.y+10, 10, false, fit(field.Value, pageWidth-margin-x, 10))
+		p.y += rowHeight
+	}
+}
+
+func (p *pdf) table(table Table) {
+	widths := columnWidths(table, pageWidth-2*margin)
+
+	header := func() {
+		x := margin
+		fmt.Fprintf(p.page, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", margin, pageHeight-p.y-rowHeight, pageWidth-2*margin, rowHeight)
+		for i, column := range table.Columns {
+			p.cell(x, widths[i], column, true)
+			x += widths[i]
+		}
+		p.y += rowHeight
+	}
+
+	if table.Title != "" {
+		p.ensure(16 + rowHeight*2)
+		p.y += 4
+		p.text(margin, p.y+10, 10, true, table.Title)
+		p.y += 14
+	} else {
+		p.ensure(rowHeight * 2)
+	}
+	header()
+
+	blank := make([]string, len(table.Columns))
+	rows := table.Rows
+	for i := 0; i < table.BlankRows; i++ {
+		rows = append(rows, blank)
+	}
+	for _, row := range rows {
+		if p.y+rowHeight > pageHeight-margin {
+			p.newPage()
+			header()
+		}
+		x := margin
+		for i := range table.Columns {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			p.cell(x, widths[i], value, false)
+			x += widths[i]
+		}
+		p.y += rowHeight
+	}
+	p.y += 8
+}
+
+// cell draws a bordered cell on the current line with its text cut down to fit
+func (p *pdf) cell(x, w float64, value string, bold bool) {
+	fmt.Fprintf(p.page, "%.2f %.2f %.2f %.2f re S\n", x, pageHeight-p.y-rowHeight, w, rowHeight)
+	if value != "" {
+		p.text(x+cellPad, p.y+rowHeight-4, tableSize, bold, fit(value, w-2*cellPad, tableSize))
+	}
+}
+
+// write numbers the pages and writes out the PDF objects: the catalog, the page tree, the two
+// fonts, then each page and its content stream
+func (p *pdf) write(w io.Writer) error {
+	out := &bytes.Buffer{}
+	offsets := make([]int, 0, 4+2*len(p.pages))
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	kids := make([]string, 0, len(p.pages))
+	for i := range p.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range p.pages {
+		p.page = page
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(p.pages))
+		p.text(pageWidth-margin-width(footer, 8), pageHeight-margin/2, 8, false, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// columnWidths sizes each column to its widest cell, then scales them all to fill the page
+func columnWidths(table Table, available float64) []float64 {
+	widths := make([]float64, len(table.Columns))
+	for i, column := range table.Columns {
+		widths[i] = max(width(column, tableSize)+2*cellPad, minColumn)
+	}
+	for _, row := range table.Rows {
+		for i := range widths {
+			if i < len(row) {
+				widths[i] = max(widths[i], width(row[i], tableSize)+2*cellPad)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	for i := range widths {
+		widths[i] *= available / total
+	}
+	return widths
+}
+
+// encode converts text to the WinAnsi bytes of the standard fonts. It matches Latin-1 for the
+// characters a roster is likely to have; anything else prints as a question mark.
+func encode(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n':
+			out = append(out, ' ')
+		case r >= 32 && r < 127, r >= 160 && r < 256:
+			out = append(out, byte(r))
+		case r == '‘' || r == '’':
+			out = append(out, '\'')
+		case r == '“' || r == '”':
+			out = append(out, '"')
+		case r == '–' || r == '—':
+			out = append(out, '-')
+		default:
+			out = append(out, '?')
+		}
+	}
+	return string(out)
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// width measures text in points. Bold is a little wider than regular, which the cell padding absorbs.
+func width(s string, size float64) float64 {
+	total := 0
+	for _, b := range []byte(encode(s)) {
+		if b >= 32 && int(b-32) < len(helveticaWidths) {
+			total += helveticaWidths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// fit cuts text down to the given width, ending it with dots when anything was cut
+func fit(s string, available, size float64) string {
+	if width(s, size) <= available {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && width(string(runes)+"...", size) > available {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes) + "..."
+}
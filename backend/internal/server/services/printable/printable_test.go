@@ -0,0 +1,128 @@
+package printable
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSheet(blank bool) GameSheetData {
+	referee := &models.User{FirstName: "Rita", LastName: "Ref"}
+	game := &models.Game{
+		DbModel:        models.DbModel{ID: 9},
+		Start:          time.Date(2024, 10, 12, 20, 30, 0, 0, time.UTC),
+		Status:         models.FINAL,
+		Venue:          models.Venue{Name: "Rec Center"},
+		HomeTeam:       models.Team{Name: "Otters"},
+		HomeTeamID:     1,
+		AwayTeam:       models.Team{Name: "Ravens"},
+		AwayTeamID:     2,
+		HomeTeamScore:  1,
+		PrimaryReferee: referee,
+	}
+	number := 7
+	home := []models.RosterMember{{UserID: 10, FirstName: "Ann", LastName: "Zed", JerseyNumber: &number, Position: models.Skater, Captain: true}, {UserID: 11, FirstName: "Bo", LastName: "Able", Position: models.Goalie}}
+	away := []models.RosterMember{{UserID: 20, FirstName: "Cy", LastName: "Dee", Position: models.Skater}}
+
+	return GameSheetData{
+		Game:        game,
+		HomePlayers: home,
+		AwayPlayers: away,
+		Goals:       []models.Goal{{TeamId: 1, UserId: 10, Assist1Id: 11, Period: 2, Duration: 300, Strength: "PP"}},
+		Penalties:   []models.Penalty{{TeamID: 2, PlayerID: 20, Period: 2, GameTime: 200, PenaltyType: models.PenaltyType{Name: "Tripping", Duration: 2}}},
+		Shots:       []models.ShotOnGoal{{TeamId: 1, Period: 1, ShotTime: 100}, {TeamId: 1, Period: 2, ShotTime: 300}, {TeamId: 2, Period: 4, ShotTime: 100}},
+		Blank:       blank,
+	}
+}
+
+func table(doc Document, title string) Table {
+	for _, section := range doc.Sections {
+		for _, t := range section.Tables {
+			if t.Title == title {
+				return t
+			}
+		}
+	}
+	return Table{}
+}
+
+func TestGameSheetFilled(t *testing.T) {
+	doc := GameSheet(testSheet(false))
+
+	scoring := table(doc, "Scoring")
+	require.Len(t, scoring.Rows, 1)
+	assert.Equal(t, []string{"2", "15:00", "Otters", "Ann Zed", "Bo Able", "", "PP"}, scoring.Rows[0])
+
+	penalties := table(doc, "Penalties")
+	assert.Equal(t, [][]string{{"2", "16:40", "Ravens", "Cy Dee", "Tripping", "2"}}, penalties.Rows)
+
+	shots := table(doc, "Shots on goal")
+	assert.Equal(t, [][]string{{"Otters", "1", "1", "0", "0", "2"}, {"Ravens", "0", "0", "0", "1", "1"}}, shots.Rows)
+
+	roster := table(doc, "Otters (home)")
+	assert.Equal(t, [][]string{{"", "Bo Able (G)"}, {"7", "Ann Zed (C)"}}, roster.Rows, "rosters are sorted by last name")
+}
+
+func TestGameSheetBlank(t *testing.T) {
+	doc := GameSheet(testSheet(true))
+
+	assert.Empty(t, table(doc, "Scoring").Rows)
+	assert.Equal(t, blankEventRows, table(doc, "Scoring").BlankRows)
+	assert.Equal(t, blankEventRows, table(doc, "Penalties").BlankRows)
+	assert.Equal(t, [][]string{{"Otters"}, {"Ravens"}}, table(doc, "Shots on goal").Rows)
+	assert.Len(t, table(doc, "Ravens (away)").Rows, 1, "rosters are printed on blank sheets too")
+}
+
+func TestRenderHTML(t *testing.T) {
+	doc := GameSheet(testSheet(true))
+	doc.Subtitle = "<script>"
+
+	out := &bytes.Buffer{}
+	require.Nil(t, Render(out, doc, HTML))
+	html := out.String()
+	assert.Contains(t, html, "<h1>Game Sheet: Otters vs Ravens</h1>")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.Equal(t, blankEventRows, strings.Count(html, "<tr>"+strings.Repeat("<td></td>", 7)+"</tr>"), "blank scoring lines")
+	assert.Equal(t, blankEventRows, strings.Count(html, "<tr>"+strings.Repeat("<td></td>", 6)+"</tr>"), "blank penalty lines")
+}
+
+func TestRenderPDF(t *testing.T) {
+	doc := SeasonReport(models.Season{Name: "Fall (2024)"}, []LeagueReport{{League: models.League{Name: "Rec"}}})
+	for i := 0; i < 80; i++ {
+		doc.Sections[0].Tables[0].Rows = append(doc.Sections[0].Tables[0].Rows, []string{fmt.Sprint(i + 1), "Team"})
+	}
+
+	out := &bytes.Buffer{}
+	require.Nil(t, Render(out, doc, PDF))
+	pdf := out.Bytes()
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), `(Fall \(2024\) Season Report) Tj`)
+	assert.Contains(t, string(pdf), "(Page 2 of 2) Tj", "long tables continue on another page")
+
+	// Every cross reference entry must point at the start of its object
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(string(pdf))[1])
+	require.Nil(t, err)
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(string(pdf[start:]), -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+}
+
+func TestFit(t *testing.T) {
+	assert.Equal(t, "Otters", fit("Otters", 100, tableSize))
+	cut := fit("A very long team name that does not fit", 60, tableSize)
+	assert.True(t, strings.HasSuffix(cut, "..."))
+	assert.LessOrEqual(t, width(cut, tableSize), 60.0)
+	assert.Equal(t, "Zo\xeb ?", encode("Zoë 李"), "Latin-1 letters are kept as WinAnsi bytes")
+}
@@ -0,0 +1,259 @@
+package printable
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/standings"
+)
+
+const (
+	blankEventRows  = 12 // Scoring and penalty lines on a blank game sheet
+	extraRosterRows = 3  // Lines under each roster for players added at the rink
+)
+
+// GameSheetData is everything printed on a game sheet. The game must have its teams, venue and
+// officials loaded, and the penalties their type.
+type GameSheetData struct {
+	Game        *models.Game
+	HomePlayers []models.RosterMember
+	AwayPlayers []models.RosterMember
+	Goals       []models.Goal
+	Penalties   []models.Penalty
+	Shots       []models.ShotOnGoal
+	Blank       bool // Leave the scoring, penalty and shot tables empty to be filled in by hand
+}
+
+// LeagueReport is one league's part of a season report
+type LeagueReport struct {
+	League    models.League
+	Standings []standings.Row
+	Scorers   []models.PlayerStatLine
+	Goalies   []models.GoalieStatLine
+}
+
+// GameSheet lays out the official sheet of a game: the officials and venue, both rosters, the
+// scoring and penalty summaries, shots by period and lines for the signatures
+func GameSheet(data GameSheetData) Document {
+	game := data.Game
+	names := make(map[uint]string)
+	for _, player := range append(append([]models.RosterMember{}, data.HomePlayers...), data.AwayPlayers...) {
+		names[player.UserID] = fullName(&models.User{FirstName: player.FirstName, LastName: player.LastName})
+	}
+	player := func(id uint) string {
+		if id == 0 {
+			return ""
+		}
+		if name, ok := names[id]; ok {
+			return name
+		}
+		return fmt.Sprintf("Player %d", id)
+	}
+	team := func(id uint) string {
+		switch id {
+		case game.HomeTeamID:
+			return game.HomeTeam.Name
+		case game.AwayTeamID:
+			return game.AwayTeam.Name
+		}
+		return ""
+	}
+
+	details := Section{Fields: []Field{
+		{"Date", game.Start.Format("Monday, January 2, 2006 3:04 PM")},
+		{"Venue", strings.TrimSuffix(game.Venue.Name+", "+game.Venue.Address, ", ")},
+		{"Home", withLockerRoom(game.HomeTeam.Name, game.HomeTeamLockerRoom)},
+		{"Away", withLockerRoom(game.AwayTeam.Name, game.AwayTeamLockerRoom)},
+		{"Referees", strings.Join(nonEmpty(fullName(game.PrimaryReferee), fullName(game.SecondaryReferee)), ", ")},
+		{"Scorekeeper", fullName(game.ScoreKeeper)},
+	}}
+	if !data.Blank {
+		details.Fields = append(details.Fields,
+			Field{"Status", string(game.Status)},
+			Field{"Score", fmt.Sprintf("%s %d, %s %d", game.HomeTeam.Name, game.HomeTeamScore, game.AwayTeam.Name, game.AwayTeamScore)})
+	}
+
+	rosters := Section{Heading: "Rosters", Tables: []Table{
+		rosterTable(game.HomeTeam.Name+" (home)", data.HomePlayers),
+		rosterTable(game.AwayTeam.Name+" (away)", data.AwayPlayers),
+	}}
+
+	scoring := Table{Title: "Scoring", Columns: []string{"Period", "Time", "Team", "Goal", "Assist", "Assist", "Type"}}
+	penalties := Table{Title: "Penalties", Columns: []string{"Period", "Time", "Team", "Player", "Infraction", "Min"}}
+	shots := Table{Title: "Shots on goal", Columns: []string{"Team", "1", "2", "3", "OT", "Total"}}
+
+	if data.Blank {
+		scoring.BlankRows, penalties.BlankRows = blankEventRows, blankEventRows
+		shots.Rows = [][]string{{game.HomeTeam.Name}, {game.AwayTeam.Name}}
+	} else {
+		goals := append([]models.Goal{}, data.Goals...)
+		sort.SliceStable(goals, func(i, j int) bool {
+			return models.GameSeconds(goals[i].Period, goals[i].Duration) < models.GameSeconds(goals[j].Period, goals[j].Duration)
+		})
+		for _, goal := range goals {
+			scoring.Rows = append(scoring.Rows, []string{
+				periodName(goal.Period), models.ScoreboardClock(goal.Duration), team(goal.TeamId),
+				player(goal.UserId), player(goal.Assist1Id), player(goal.Assist2Id), goalType(goal),
+			})
+		}
+
+		called := append([]models.Penalty{}, data.Penalties...)
+		sort.SliceStable(called, func(i, j int) bool {
+			return models.GameSeconds(called[i].Period, called[i].GameTime) < models.GameSeconds(called[j].Period, called[j].GameTime)
+		})
+		for _, penalty := range called {
+			minutes := penalty.PenaltyType.Duration
+			if penalty.Duration > 0 {
+				minutes = penalty.Duration / 60
+			}
+			penalties.Rows = append(penalties.Rows, []string{
+				periodName(penalty.Period), models.ScoreboardClock(penalty.GameTime), team(penalty.TeamID),
+				player(penalty.PlayerID), penalty.PenaltyType.Name, fmt.Sprint(minutes),
+			})
+		}
+
+		for _, teamId := range []uint{game.HomeTeamID, game.AwayTeamID} {
+			byPeriod := make([]int, models.RegulationPeriods+1)
+			for _, shot := range data.Shots {
+				if shot.TeamId != teamId {
+					continue
+				}
+				byPeriod[min(max(shot.Period, 1), models.RegulationPeriods+1)-1]++
+			}
+			row, total := []string{team(teamId)}, 0
+			for _, count := range byPeriod {
+				row = append(row, fmt.Sprint(count))
+				total += count
+			}
+			shots.Rows = append(shots.Rows, append(row, fmt.Sprint(total)))
+		}
+	}
+
+	signatures := Table{Title: "Signatures", Columns: []string{"Role", "Name", "Signature"}, Rows: [][]string{
+		{"Referee", fullName(game.PrimaryReferee)},
+		{"Referee", fullName(game.SecondaryReferee)},
+		{"Home captain", ""},
+		{"Away captain", ""},
+	}}
+
+	return Document{
+		Title:    fmt.Sprintf("Game Sheet: %s vs %s", game.HomeTeam.Name, game.AwayTeam.Name),
+		Subtitle: fmt.Sprintf("Game %d", game.ID),
+		Sections: []Section{
+			details,
+			rosters,
+			{Heading: "Game summary", Tables: []Table{scoring, penalties, shots}},
+			{Heading: "Sign-off", Tables: []Table{signatures}},
+		},
+	}
+}
+
+// SeasonReport lays out the end of season summary: each league's final table and its scoring
+// and goaltending leaders
+func SeasonReport(season models.Season, leagues []LeagueReport) Document {
+	doc := Document{
+		Title:    season.Name + " Season Report",
+		Subtitle: fmt.Sprintf("%s to %s", season.Start.Format("January 2, 2006"), season.End.Format("January 2, 2006")),
+	}
+
+	for _, league := range leagues {
+		table := Table{Title: "Standings", Columns: []string{"Rank", "Team", "GP", "W", "L", "OTL", "T", "GF", "GA", "Diff", "Pts"}}
+		for _, row := range league.Standings {
+			table.Rows = append(table.Rows, []string{
+				fmt.Sprint(row.Rank), row.TeamName, fmt.Sprint(row.GamesPlayed), fmt.Sprint(row.Wins), fmt.Sprint(row.Losses),
+				fmt.Sprint(row.OvertimeLosses), fmt.Sprint(row.Ties), fmt.Sprint(row.GoalsFor), fmt.Sprint(row.GoalsAgainst),
+				fmt.Sprintf("%+d", row.GoalDifferential), fmt.Sprint(row.Points),
+			})
+		}
+
+		scorers := Table{Title: "Scoring leaders", Columns: []string{"Player", "GP", "G", "A", "Pts", "PIM", "+/-"}}
+		for _, line := range league.Scorers {
+			scorers.Rows = append(scorers.Rows, []string{
+				strings.TrimSpace(line.FirstName + " " + line.LastName), fmt.Sprint(line.GamesPlayed), fmt.Sprint(line.Goals),
+				fmt.Sprint(line.Assists), fmt.Sprint(line.Points), fmt.Sprint(line.PenaltyMins), fmt.Sprintf("%+d", line.PlusMinus),
+			})
+		}
+
+		goalies := Table{Title: "Goaltending leaders", Columns: []string{"Goalie", "GP", "SA", "SV", "SV%", "GAA", "SO"}}
+		for _, line := range league.Goalies {
+			goalies.Rows = append(goalies.Rows, []string{
+				strings.TrimSpace(line.FirstName + " " + line.LastName), fmt.Sprint(line.GamesPlayed), fmt.Sprint(line.ShotsAgainst),
+				fmt.Sprint(line.Saves), fmt.Sprintf("%.3f", line.SavePercentage), fmt.Sprintf("%.2f", line.GoalsAgainstAverage), fmt.Sprint(line.Shutouts),
+			})
+		}
+
+		doc.Sections = append(doc.Sections, Section{Heading: league.League.Name, Tables: []Table{table, scorers, goalies}})
+	}
+	return doc
+}
+
+// rosterTable lists a team's players by name with their jersey numbers, marking the captain and
+// goalies the way a scorekeeper expects
+func rosterTable(title string, players []models.RosterMember) Table {
+	sorted := append([]models.RosterMember{}, players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastName+" "+sorted[i].FirstName < sorted[j].LastName+" "+sorted[j].FirstName
+	})
+
+	table := Table{Title: title, Columns: []string{"#", "Player"}, BlankRows: extraRosterRows}
+	for _, player := range sorted {
+		number := ""
+		if player.JerseyNumber != nil {
+			number = strconv.Itoa(*player.JerseyNumber)
+		}
+		name := fullName(&models.User{FirstName: player.FirstName, LastName: player.LastName})
+		if player.Position == models.Goalie {
+			name += " (G)"
+		}
+		if player.Captain {
+			name += " (C)"
+		}
+		table.Rows = append(table.Rows, []string{number, name})
+	}
+	return table
+}
+
+func fullName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func withLockerRoom(team, lockerRoom string) string {
+	if lockerRoom == "" {
+		return team
+	}
+	return fmt.Sprintf("%s (locker room %s)", team, lockerRoom)
+}
+
+func periodName(period uint) string {
+	if period > models.RegulationPeriods {
+		return "OT"
+	}
+	return fmt.Sprint(period)
+}
+
+func goalType(goal models.Goal) string {
+	kinds := nonEmpty(goal.Strength)
+	if goal.EmptyNet {
+		kinds = append(kinds, "EN")
+	}
+	if goal.IsPenaltyShot {
+		kinds = append(kinds, "PS")
+	}
+	return strings.Join(kinds, " ")
+}
@@ -0,0 +1,122 @@
+package registration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+var (
+	ErrNotOpen     = errors.New("registration for the season hasn't opened yet")
+	ErrClosed      = errors.New("registration for the season has closed")
+	ErrBadWindow   = errors.New("registration must open before it closes")
+	ErrBadCapacity = errors.New("a league's capacity can't be negative")
+	ErrNotPending  = errors.New("the registration isn't waiting for approval")
+	ErrFinished    = errors.New("the registration has already been rejected or withdrawn")
+	ErrNotAllowed  = errors.New("only the registrant or a manager can withdraw a registration")
+)
+
+// Window returns when registration for a season opens and closes. Registration is open from the
+// moment the season is created and closes when it starts, unless the season sets other dates. A
+// zero time means there's no limit on that side.
+func Window(season models.Season) (opens, closes time.Time) {
+	closes = season.Start
+	if season.RegistrationOpens != nil {
+		opens = *season.RegistrationOpens
+	}
+	if season.RegistrationCloses != nil {
+		closes = *season.RegistrationCloses
+	}
+	return opens, closes
+}
+
+// CheckWindow checks registration for a season is open at the given time
+func CheckWindow(season models.Season, at time.Time) error {
+	opens, closes := Window(season)
+	switch {
+	case !opens.IsZero() && at.Before(opens):
+		return ErrNotOpen
+	case !closes.IsZero() && !at.Before(closes):
+		return ErrClosed
+	}
+	return nil
+}
+
+// CheckWindowDates checks the dates a manager gives for a season's registration window
+func CheckWindowDates(opens, closes *time.Time) error {
+	if opens != nil && closes != nil && !opens.Before(*closes) {
+		return ErrBadWindow
+	}
+	return nil
+}
+
+// HoldsSpot reports whether a registration with the status counts against its league's capacity
+func HoldsSpot(status models.RegistrationStatus) bool {
+	return status == models.RegistrationPending || status == models.RegistrationApproved
+}
+
+// Admit is the status of a new registration for a league whose registrations hold the given number
+// of spots. It waits for a manager when there's a spot for it, and on the waitlist otherwise.
+func Admit(capacity, held int) models.RegistrationStatus {
+	if Openings(capacity, held, 1) == 0 {
+		return models.RegistrationWaitlisted
+	}
+	return models.RegistrationPending
+}
+
+// Openings is how many of the waiting registrations can be promoted off the waitlist of a league
+// whose registrations hold the given number of spots
+func Openings(capacity, held, waiting int) int {
+	if capacity == 0 {
+		return waiting
+	}
+	return max(0, min(capacity-held, waiting))
+}
+
+// CheckReview checks a manager can approve or reject a registration
+func CheckReview(r models.Registration) error {
+	if r.Status != models.RegistrationPending {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// CheckWithdraw checks a registration can be withdrawn by the user. Registrants can withdraw their
+// own registrations, and managers anyone's.
+func CheckWithdraw(r models.Registration, userId uint, manager bool) error {
+	switch {
+	case r.UserID != userId && !manager:
+		return ErrNotAllowed
+	case r.Status == models.RegistrationRejected || r.Status == models.RegistrationWithdrawn:
+		return ErrFinished
+	}
+	return nil
+}
+
+// Notice is the message telling a registrant their registration for a league has moved to a status
+func Notice(league string, from, to models.RegistrationStatus) string {
+	switch {
+	case to == models.RegistrationPending && from == models.RegistrationWaitlisted:
+		return fmt.Sprintf("A spot opened in %v. Your registration is off the waitlist and waiting for a manager's approval.", league)
+	case to == models.RegistrationPending:
+		return fmt.Sprintf("Your registration for %v is waiting for a manager's approval.", league)
+	case to == models.RegistrationWaitlisted:
+		return fmt.Sprintf("%v is full, so your registration is on the waitlist. You'll move up when a spot opens.", league)
+	case to == models.RegistrationApproved:
+		return fmt.Sprintf("Your registration for %v has been approved.", league)
+	case to == models.RegistrationRejected:
+		return fmt.Sprintf("Your registration for %v was not approved.", league)
+	default:
+		return fmt.Sprintf("Your registration for %v has been withdrawn.", league)
+	}
+}
+
+// CheckCapacity checks the capacity a manager gives a league
+func CheckCapacity(capacity int) error {
+	if capacity < 0 {
+		return ErrBadCapacity
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package registration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+func TestCheckWindow(t *testing.T) {
+	season := models.Season{Start: start}
+	assert.NoError(t, CheckWindow(season, start.AddDate(0, -2, 0)))
+	assert.ErrorIs(t, CheckWindow(season, start), ErrClosed, "registration closes when the season starts")
+
+	opens, closes := start.AddDate(0, -1, 0), start.AddDate(0, 0, 14)
+	season.RegistrationOpens, season.RegistrationCloses = &opens, &closes
+	assert.ErrorIs(t, CheckWindow(season, start.AddDate(0, -2, 0)), ErrNotOpen)
+	assert.NoError(t, CheckWindow(season, opens))
+	assert.NoError(t, CheckWindow(season, start.AddDate(0, 0, 7)), "late registration runs past the start")
+	assert.ErrorIs(t, CheckWindow(season, closes), ErrClosed)
+
+	assert.NoError(t, CheckWindow(models.Season{}, start), "a season without dates is always open")
+}
+
+func TestCheckWindowDates(t *testing.T) {
+	opens, closes := start.AddDate(0, -1, 0), start
+	assert.NoError(t, CheckWindowDates(&opens, &closes))
+	assert.NoError(t, CheckWindowDates(nil, &closes))
+	assert.NoError(t, CheckWindowDates(&opens, nil))
+	assert.ErrorIs(t, CheckWindowDates(&closes, &opens), ErrBadWindow)
+	assert.ErrorIs(t, CheckWindowDates(&closes, &closes), ErrBadWindow)
+}
+
+func TestAdmit(t *testing.T) {
+	assert.Equal(t, models.RegistrationPending, Admit(0, 500), "no capacity means no limit")
+	assert.Equal(t, models.RegistrationPending, Admit(20, 19))
+	assert.Equal(t, models.RegistrationWaitlisted, Admit(20, 20))
+	assert.Equal(t, models.RegistrationWaitlisted, Admit(20, 25), "a lowered capacity keeps the spots already held")
+}
+
+func TestOpenings(t *testing.T) {
+	assert.Equal(t, 4, Openings(0, 30, 4))
+	assert.Equal(t, 2, Openings(20, 18, 4))
+	assert.Equal(t, 1, Openings(20, 10, 1))
+	assert.Equal(t, 0, Openings(20, 22, 4))
+}
+
+func TestHoldsSpot(t *testing.T) {
+	assert.True(t, HoldsSpot(models.RegistrationPending))
+	assert.True(t, HoldsSpot(models.RegistrationApproved))
+	assert.False(t, HoldsSpot(models.RegistrationWaitlisted))
+	assert.False(t, HoldsSpot(models.RegistrationWithdrawn))
+}
+
+func TestCheckReview(t *testing.T) {
+	assert.NoError(t, CheckReview(models.Registration{Status: models.RegistrationPending}))
+	assert.ErrorIs(t, CheckReview(models.Registration{Status: models.RegistrationWaitlisted}), ErrNotPending)
+	assert.ErrorIs(t, CheckReview(models.Registration{Status: models.RegistrationApproved}), ErrNotPending)
+}
+
+func TestCheckWithdraw(t *testing.T) {
+	r := models.Registration{UserID: 7, Status: models.RegistrationApproved}
+	assert.NoError(t, CheckWithdraw(r, 7, false))
+	assert.NoError(t, CheckWithdraw(r, 1, true))
+	assert.ErrorIs(t, CheckWithdraw(r, 1, false), ErrNotAllowed)
+
+	r.Status = models.RegistrationWithdrawn
+	assert.ErrorIs(t, CheckWithdraw(r, 7, false), ErrFinished)
+}
+
+func TestNotice(t *testing.T) {
+	assert.Contains(t, Notice("A League", "", models.RegistrationWaitlisted), "A League is full")
+	assert.Contains(t, Notice("A League", models.RegistrationWaitlisted, models.RegistrationPending), "off the waitlist")
+	assert.Equal(t, "Your registration for A League has been approved.", Notice("A League", models.RegistrationPending, models.RegistrationApproved))
+}
+
+func TestCheckCapacity(t *testing.T) {
+	assert.NoError(t, CheckCapacity(0))
+	assert.NoError(t, CheckCapacity(40))
+	assert.ErrorIs(t, CheckCapacity(-1), ErrBadCapacity)
+}
@@ -0,0 +1,193 @@
+package registration
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// MaxAnswerLength is the longest a text answer can be
+const MaxAnswerLength = 2000
+
+var (
+	ErrInvalidForm    = errors.New("the registration form is invalid")
+	ErrInvalidAnswers = errors.New("the registration form wasn't filled in correctly")
+)
+
+// AnswersError lists what's wrong with the answers to a registration form. It matches
+// ErrInvalidAnswers.
+type AnswersError struct {
+	Problems []models.AnswerProblem
+}
+
+func (e *AnswersError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		messages = append(messages, fmt.Sprintf("%v %v", problem.Key, problem.Message))
+	}
+	return fmt.Sprintf("%v: %v", ErrInvalidAnswers, strings.Join(messages, "; "))
+}
+
+func (e *AnswersError) Unwrap() error {
+	return ErrInvalidAnswers
+}
+
+// Problems returns what an error from CheckAnswers lists, or nil for other errors
+func Problems(err error) []models.AnswerProblem {
+	var invalid *AnswersError
+	if errors.As(err, &invalid) {
+		return invalid.Problems
+	}
+	return nil
+}
+
+// CheckForm checks the questions of a registration form. Keys must be unique, choice questions
+// need choices, and conditions must depend on an earlier question and an answer it can be given.
+func CheckForm(questions []models.FormQuestion) error {
+	invalid := func(key, format string, args ...any) error {
+		return fmt.Errorf("%w, question %q %v", ErrInvalidForm, key, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[string]models.FormQuestion, len(questions))
+	for _, q := range questions {
+		switch {
+		case strings.TrimSpace(q.Key) == "":
+			return fmt.Errorf("%w, every question needs a key", ErrInvalidForm)
+		case seen[q.Key].Key != "":
+			return invalid(q.Key, "is on the form more than once")
+		case strings.TrimSpace(q.Text) == "":
+			return invalid(q.Key, "has no text")
+		}
+
+		switch q.Type {
+		case models.TextQuestion, models.CheckboxQuestion, models.DateQuestion, models.WaiverQuestion:
+		case models.ChoiceQuestion:
+			if len(q.Choices) == 0 {
+				return invalid(q.Key, "has no choices")
+			}
+		default:
+			return invalid(q.Key, "has an unknown type %q", q.Type)
+		}
+
+		if q.ShowIfKey != "" {
+			depends, ok := seen[q.ShowIfKey]
+			if !ok {
+				return invalid(q.Key, "depends on %q, which isn't an earlier question", q.ShowIfKey)
+			}
+			if _, err := normalize(depends, q.ShowIfAnswer); err != nil || strings.TrimSpace(q.ShowIfAnswer) == "" {
+				return invalid(q.Key, "depends on an answer %q can't be given", q.ShowIfKey)
+			}
+		}
+		seen[q.Key] = q
+	}
+	return nil
+}
+
+// CheckAnswers checks the answers given to a registration form, keyed by question, and returns
+// them ready to save. Answers to questions that aren't asked because of their conditions are
+// dropped.
+func CheckAnswers(questions []models.FormQuestion, answers map[string]string) ([]models.Question, error) {
+	problems := make([]models.AnswerProblem, 0)
+	given := make(map[string]string, len(questions))
+	saved := make([]models.Question, 0, len(questions))
+
+	for _, q := range questions {
+		if q.ShowIfKey != "" && !asked(questions, q, given) {
+			continue
+		}
+
+		answer := strings.TrimSpace(answers[q.Key])
+		if answer == "" {
+			switch {
+			case q.Type == models.WaiverQuestion:
+				problems = append(problems, models.AnswerProblem{Key: q.Key, Message: "must be acknowledged"})
+			case q.Required:
+				problems = append(problems, models.AnswerProblem{Key: q.Key, Message: "is required"})
+			}
+			continue
+		}
+
+		answer, err := normalize(q, answer)
+		if err != nil {
+			problems = append(problems, models.AnswerProblem{Key: q.Key, Message: err.Error()})
+			continue
+		}
+		given[q.Key] = answer
+		saved = append(saved, models.Question{Key: q.Key, Text: q.Text, Answer: answer, Render: string(q.Type)})
+	}
+
+	unknown := make([]string, 0)
+	for key := range answers {
+		if findQuestion(questions, key) == nil {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		problems = append(problems, models.AnswerProblem{Key: key, Message: "isn't on the form"})
+	}
+
+	if len(problems) > 0 {
+		return nil, &AnswersError{Problems: problems}
+	}
+	return saved, nil
+}
+
+// asked reports whether a conditional question is asked given the answers so far
+func asked(questions []models.FormQuestion, q models.FormQuestion, given map[string]string) bool {
+	dependsOn := findQuestion(questions, q.ShowIfKey)
+	if dependsOn == nil {
+		return false
+	}
+	condition, err := normalize(*dependsOn, q.ShowIfAnswer)
+	return err == nil && given[q.ShowIfKey] == condition
+}
+
+// normalize checks an answer fits its question and puts it in the form it's saved in
+func normalize(q models.FormQuestion, answer string) (string, error) {
+	answer = strings.TrimSpace(answer)
+	switch q.Type {
+	case models.ChoiceQuestion:
+		for _, choice := range q.Choices {
+			if strings.EqualFold(choice, answer) {
+				return choice, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of %v", strings.Join(q.Choices, ", "))
+	case models.CheckboxQuestion:
+		checked, err := strconv.ParseBool(answer)
+		if err != nil {
+			return "", errors.New("must be true or false")
+		}
+		return strconv.FormatBool(checked), nil
+	case models.WaiverQuestion:
+		if acknowledged, err := strconv.ParseBool(answer); err != nil || !acknowledged {
+			return "", errors.New("must be acknowledged")
+		}
+		return "true", nil
+	case models.DateQuestion:
+		date, err := time.Parse(time.DateOnly, answer)
+		if err != nil {
+			return "", errors.New("must be a date like 2024-09-01")
+		}
+		return date.Format(time.DateOnly), nil
+	}
+	if len(answer) > MaxAnswerLength {
+		return "", fmt.Errorf("can't be longer than %d characters", MaxAnswerLength)
+	}
+	return answer, nil
+}
+
+func findQuestion(questions []models.FormQuestion, key string) *models.FormQuestion {
+	for i := range questions {
+		if questions[i].Key == key {
+			return &questions[i]
+		}
+	}
+	return nil
+}
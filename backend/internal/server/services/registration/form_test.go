@@ -0,0 +1,86 @@
+package registration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testForm() []models.FormQuestion {
+	return []models.FormQuestion{
+		{Key: "shirt", Text: "Shirt size", Type: models.ChoiceQuestion, Choices: pq.StringArray{"S", "M", "L"}, Required: true},
+		{Key: "returning", Text: "Did you play last season?", Type: models.CheckboxQuestion},
+		{Key: "last_team", Text: "Which team?", Type: models.TextQuestion, Required: true, ShowIfKey: "returning", ShowIfAnswer: "true"},
+		{Key: "available", Text: "First night you can play", Type: models.DateQuestion},
+		{Key: "waiver", Text: "I accept the league's waiver", Type: models.WaiverQuestion},
+	}
+}
+
+func TestCheckForm(t *testing.T) {
+	assert.NoError(t, CheckForm(testForm()))
+	assert.NoError(t, CheckForm(nil))
+
+	broken := func(change func(form []models.FormQuestion)) error {
+		form := testForm()
+		change(form)
+		return CheckForm(form)
+	}
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[1].Key = "shirt" }), ErrInvalidForm)
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[0].Key = " " }), ErrInvalidForm)
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[0].Choices = nil }), ErrInvalidForm)
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[3].Type = "essay" }), ErrInvalidForm)
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[2].ShowIfAnswer = "maybe" }), ErrInvalidForm)
+	assert.ErrorIs(t, broken(func(form []models.FormQuestion) { form[1].ShowIfKey, form[1].ShowIfAnswer = "waiver", "true" }), ErrInvalidForm, "conditions must depend on earlier questions")
+}
+
+func TestCheckAnswers(t *testing.T) {
+	saved, err := CheckAnswers(testForm(), map[string]string{
+		"shirt":     "m",
+		"returning": "TRUE",
+		"last_team": " Otters ",
+		"available": "2024-09-14",
+		"waiver":    "true",
+	})
+	require.Nil(t, err)
+	require.Len(t, saved, 5)
+	assert.Equal(t, models.Question{Key: "shirt", Text: "Shirt size", Answer: "M", Render: "choice"}, saved[0])
+	assert.Equal(t, "true", saved[1].Answer)
+	assert.Equal(t, "Otters", saved[2].Answer)
+}
+
+func TestCheckAnswersSkipsHiddenQuestions(t *testing.T) {
+	saved, err := CheckAnswers(testForm(), map[string]string{"shirt": "S", "returning": "false", "last_team": "Otters", "waiver": "yes"})
+	assert.Nil(t, saved)
+	assert.Equal(t, []models.AnswerProblem{{Key: "waiver", Message: "must be acknowledged"}}, Problems(err))
+
+	saved, err = CheckAnswers(testForm(), map[string]string{"shirt": "S", "last_team": "Otters", "waiver": "1"})
+	require.Nil(t, err)
+	for _, answer := range saved {
+		assert.NotEqual(t, "last_team", answer.Key, "the team isn't asked for new players")
+	}
+}
+
+func TestCheckAnswersProblems(t *testing.T) {
+	_, err := CheckAnswers(testForm(), map[string]string{
+		"shirt":     "XXL",
+		"returning": "true",
+		"available": "14/09/2024",
+		"nickname":  "Ace",
+	})
+	assert.ErrorIs(t, err, ErrInvalidAnswers)
+	assert.True(t, IsRuleViolation(err))
+	assert.Equal(t, []models.AnswerProblem{
+		{Key: "shirt", Message: "must be one of S, M, L"},
+		{Key: "last_team", Message: "is required"},
+		{Key: "available", Message: "must be a date like 2024-09-01"},
+		{Key: "waiver", Message: "must be acknowledged"},
+		{Key: "nickname", Message: "isn't on the form"},
+	}, Problems(err))
+
+	_, err = CheckAnswers([]models.FormQuestion{{Key: "notes", Text: "Notes", Type: models.TextQuestion}}, map[string]string{"notes": strings.Repeat("a", MaxAnswerLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidAnswers)
+}
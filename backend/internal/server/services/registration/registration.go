@@ -0,0 +1,35 @@
+package registration
+
+import (
+	"errors"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+var (
+	ErrUnknownLeague     = errors.New("the league doesn't exist")
+	ErrWrongSeason       = errors.New("the league isn't part of the season")
+	ErrAlreadyRegistered = errors.New("the player has already registered for the league")
+)
+
+// CheckLeague checks a player can register for a league in a season
+func CheckLeague(seasonId uint, league *models.League) error {
+	switch {
+	case league == nil:
+		return ErrUnknownLeague
+	case league.SeasonID != seasonId:
+		return ErrWrongSeason
+	}
+	return nil
+}
+
+// IsRuleViolation reports whether err is one of the registration rules rather than a failure
+func IsRuleViolation(err error) bool {
+	for _, rule := range []error{ErrUnknownLeague, ErrWrongSeason, ErrAlreadyRegistered, ErrInvalidForm, ErrInvalidAnswers,
+		ErrNotOpen, ErrClosed, ErrBadWindow, ErrBadCapacity, ErrNotPending, ErrFinished, ErrNotAllowed} {
+		if errors.Is(err, rule) {
+			return true
+		}
+	}
+	return false
+}
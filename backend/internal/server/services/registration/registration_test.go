@@ -0,0 +1,22 @@
+package registration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLeague(t *testing.T) {
+	league := &models.League{SeasonID: 2}
+	assert.NoError(t, CheckLeague(2, league))
+	assert.ErrorIs(t, CheckLeague(3, league), ErrWrongSeason)
+	assert.ErrorIs(t, CheckLeague(2, nil), ErrUnknownLeague)
+}
+
+func TestIsRuleViolation(t *testing.T) {
+	assert.True(t, IsRuleViolation(ErrAlreadyRegistered))
+	assert.False(t, IsRuleViolation(errors.New("connection refused")))
+	assert.False(t, IsRuleViolation(nil))
+}
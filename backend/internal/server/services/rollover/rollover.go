@@ -0,0 +1,113 @@
+package rollover
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// ErrUnknownLeague is returned when the leagues to roll over aren't all in the season
+var ErrUnknownLeague = errors.New("the league isn't part of the season being rolled over")
+
+// Options describe the season to create
+type Options struct {
+	Name         string    `json:"name"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	CarryRosters bool      `json:"carry_rosters"` // Copy each team's players and captain
+	LeagueIDs    []uint    `json:"league_ids"`    // The leagues to copy; all of them when empty
+}
+
+// Validate checks the options make a usable season
+func (o Options) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("the new season needs a name")
+	}
+	if o.Start.IsZero() || o.End.IsZero() {
+		return errors.New("the new season needs a start and end")
+	}
+	if !o.End.After(o.Start) {
+		return errors.New("the new season has to end after it starts")
+	}
+	return nil
+}
+
+// Plan copies a season's leagues and teams into a new season. Correlation IDs are kept so
+// careers and franchise histories carry across; leagues and teams without one are given one.
+// Records aren't copied and archived teams are left behind. from needs its leagues, teams and,
+// to carry rosters, roster players.
+func Plan(from models.Season, options Options) (models.Rollover, error) {
+	for _, id := range options.LeagueIDs {
+		if !slices.ContainsFunc(from.Leagues, func(l models.League) bool { return l.ID == id }) {
+			return models.Rollover{}, fmt.Errorf("%w: league %v", ErrUnknownLeague, id)
+		}
+	}
+
+	plan := models.Rollover{
+		FromSeasonID: from.ID,
+		Season: models.Season{
+			Name:  strings.TrimSpace(options.Name),
+			Start: options.Start,
+			End:   options.End,
+		},
+		CarryRosters:               options.CarryRosters,
+		Leagues:                    make([]models.RolloverLeague, 0, len(from.Leagues)),
+		AssignedLeagueCorrelations: map[uint]string{},
+		AssignedTeamCorrelations:   map[uint]string{},
+	}
+
+	for _, league := range from.Leagues {
+		if len(options.LeagueIDs) > 0 && !slices.Contains(options.LeagueIDs, league.ID) {
+			continue
+		}
+
+		copied := models.RolloverLeague{
+			FromLeagueID:     league.ID,
+			Name:             league.Name,
+			CorrelationId:    league.CorrelationId,
+			PointSystem:      league.PointSystem,
+			Tiebreakers:      league.Tiebreakers,
+			RosterLimit:      league.RosterLimit,
+			Capacity:         league.Capacity,
+			EligibilityRules: league.EligibilityRules,
+			Teams:            make([]models.RolloverTeam, 0, len(league.Teams)),
+		}
+		if copied.CorrelationId == "" {
+			copied.CorrelationId = models.NewCorrelationId()
+			plan.AssignedLeagueCorrelations[league.ID] = copied.CorrelationId
+		}
+
+		for _, team := range league.Teams {
+			if team.ArchivedAt != nil {
+				continue // Folded teams don't come back
+			}
+			t := models.RolloverTeam{
+				FromTeamID:    team.ID,
+				Name:          team.Name,
+				CorrelationId: team.CorrelationId,
+				Color:         team.Color,
+				LogoId:        team.LogoId,
+			}
+			if t.CorrelationId == "" {
+				t.CorrelationId = models.NewCorrelationId()
+				plan.AssignedTeamCorrelations[team.ID] = t.CorrelationId
+			}
+			if options.CarryRosters && team.RosterID != 0 {
+				t.FromRosterID = team.RosterID
+				t.Players = len(team.Roster.Players)
+				if slices.ContainsFunc(team.Roster.Players, func(p *models.User) bool { return p.ID == team.Roster.CaptainID }) {
+					t.CaptainID = team.Roster.CaptainID
+				}
+			}
+			copied.Teams = append(copied.Teams, t)
+		}
+
+		plan.Leagues = append(plan.Leagues, copied)
+	}
+
+	return plan, nil
+}
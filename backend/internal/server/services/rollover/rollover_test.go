@@ -0,0 +1,99 @@
+package rollover
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fall   = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	winter = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+)
+
+func testSeason() models.Season {
+	ann := &models.User{DbModel: models.DbModel{ID: 10}}
+	bo := &models.User{DbModel: models.DbModel{ID: 11}}
+	return models.Season{
+		DbModel: models.DbModel{ID: 1},
+		Name:    "Fall 2024",
+		Leagues: []models.League{
+			{
+				DbModel:          models.DbModel{ID: 2},
+				CorrelationId:    "a-league",
+				Name:             "A League",
+				PointSystem:      "3-2-1-0",
+				RosterLimit:      18,
+				Capacity:         60,
+				EligibilityRules: models.EligibilityRules{MinAge: 18},
+				Teams: []models.Team{
+					{
+						DbModel:       models.DbModel{ID: 3},
+						CorrelationId: "otters",
+						Name:          "Otters",
+						Color:         "blue",
+						RosterID:      4,
+						Roster:        models.Roster{Players: []*models.User{ann, bo}, CaptainID: 11},
+						Wins:          9,
+						Points:        18,
+					},
+					{DbModel: models.DbModel{ID: 5}, Name: "Ravens", RosterID: 6, Roster: models.Roster{CaptainID: 99}},
+				},
+			},
+			{DbModel: models.DbModel{ID: 7}, Name: "B League", Teams: []models.Team{{DbModel: models.DbModel{ID: 8}, Name: "Folded", ArchivedAt: &fall}}},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Options{Name: "Winter", Start: winter, End: winter.AddDate(0, 3, 0)}.Validate())
+	assert.NotNil(t, Options{Name: " ", Start: winter, End: winter.AddDate(0, 3, 0)}.Validate())
+	assert.NotNil(t, Options{Name: "Winter", Start: winter}.Validate())
+	assert.NotNil(t, Options{Name: "Winter", Start: winter, End: fall}.Validate())
+}
+
+func TestPlan(t *testing.T) {
+	plan, err := Plan(testSeason(), Options{Name: " Winter 2025 ", Start: winter, End: winter.AddDate(0, 3, 0)})
+	require.Nil(t, err)
+
+	assert.Equal(t, uint(1), plan.FromSeasonID)
+	assert.Equal(t, "Winter 2025", plan.Season.Name)
+	require.Len(t, plan.Leagues, 2)
+
+	a := plan.Leagues[0]
+	assert.Equal(t, "a-league", a.CorrelationId)
+	assert.Equal(t, "3-2-1-0", a.PointSystem)
+	assert.Equal(t, 18, a.RosterLimit)
+	assert.Equal(t, 60, a.Capacity)
+	assert.Equal(t, 18, a.MinAge)
+	require.Len(t, a.Teams, 2)
+	assert.Equal(t, models.RolloverTeam{FromTeamID: 3, Name: "Otters", CorrelationId: "otters", Color: "blue"}, a.Teams[0], "records and rosters aren't copied")
+
+	ravens := a.Teams[1].CorrelationId
+	assert.Len(t, ravens, 36)
+	assert.Equal(t, map[uint]string{5: ravens}, plan.AssignedTeamCorrelations)
+	assert.Equal(t, map[uint]string{7: plan.Leagues[1].CorrelationId}, plan.AssignedLeagueCorrelations)
+	assert.NotEqual(t, ravens, plan.Leagues[1].CorrelationId)
+	assert.Empty(t, plan.Leagues[1].Teams, "archived teams are left behind")
+}
+
+func TestPlanCarryingRosters(t *testing.T) {
+	plan, err := Plan(testSeason(), Options{Name: "Winter", CarryRosters: true, LeagueIDs: []uint{2}})
+	require.Nil(t, err)
+
+	require.Len(t, plan.Leagues, 1)
+	otters, ravens := plan.Leagues[0].Teams[0], plan.Leagues[0].Teams[1]
+	assert.Equal(t, uint(4), otters.FromRosterID)
+	assert.Equal(t, 2, otters.Players)
+	assert.Equal(t, uint(11), otters.CaptainID)
+	assert.Equal(t, uint(0), ravens.CaptainID, "a captain who isn't on the roster isn't carried")
+}
+
+func TestPlanUnknownLeague(t *testing.T) {
+	_, err := Plan(testSeason(), Options{Name: "Winter", LeagueIDs: []uint{2, 8}})
+	assert.True(t, errors.Is(err, ErrUnknownLeague))
+}
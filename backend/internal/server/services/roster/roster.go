@@ -0,0 +1,99 @@
+package roster
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+const (
+	// DefaultLimit is the most players a team can carry when its league doesn't set a limit
+	DefaultLimit = 20
+	// MaxJerseyNumber is the highest number a jersey can have
+	MaxJerseyNumber = 99
+)
+
+var (
+	ErrTeamArchived    = errors.New("the team is archived and its roster can't change")
+	ErrUnknownPlayer   = errors.New("the player doesn't exist")
+	ErrAlreadyOnRoster = errors.New("the player is already on the roster")
+	ErrNotOnRoster     = errors.New("the player isn't on the roster")
+	ErrRosterFull      = errors.New("the roster is full")
+	ErrSuspended       = errors.New("the player is serving a suspension")
+	ErrJerseyTaken     = errors.New("the jersey number is taken")
+	ErrInvalidJersey   = fmt.Errorf("jersey numbers must be between 0 and %d", MaxJerseyNumber)
+	ErrInvalidPosition = fmt.Errorf("the position must be %v or %v", models.Skater, models.Goalie)
+)
+
+// Limit is the most players a team in the league can carry
+func Limit(league models.League) int {
+	if league.RosterLimit > 0 {
+		return league.RosterLimit
+	}
+	return DefaultLimit
+}
+
+// Normalize fills in the default position
+func Normalize(player *models.RosterPlayer) {
+	if player.Position == "" {
+		player.Position = models.Skater
+	}
+}
+
+// CheckAdd checks a player can join a roster that already has members. Suspended players are
+// blocked.
+func CheckAdd(members []models.RosterPlayer, player models.RosterPlayer, limit int, suspended bool) error {
+	for _, member := range members {
+		if member.UserID == player.UserID {
+			return ErrAlreadyOnRoster
+		}
+	}
+	if suspended {
+		return ErrSuspended
+	}
+	if len(members) >= limit {
+		return fmt.Errorf("%w, it has the maximum of %d players", ErrRosterFull, limit)
+	}
+	return check(members, player)
+}
+
+// CheckUpdate checks a member's new jersey number and position
+func CheckUpdate(members []models.RosterPlayer, player models.RosterPlayer) error {
+	for _, member := range members {
+		if member.UserID == player.UserID {
+			return check(members, player)
+		}
+	}
+	return ErrNotOnRoster
+}
+
+func check(members []models.RosterPlayer, player models.RosterPlayer) error {
+	if player.Position != models.Skater && player.Position != models.Goalie {
+		return ErrInvalidPosition
+	}
+	if player.JerseyNumber == nil {
+		return nil
+	}
+
+	number := *player.JerseyNumber
+	if number < 0 || number > MaxJerseyNumber {
+		return ErrInvalidJersey
+	}
+	for _, member := range members {
+		if member.UserID != player.UserID && member.JerseyNumber != nil && *member.JerseyNumber == number {
+			return fmt.Errorf("%w, #%d is already worn by another player", ErrJerseyTaken, number)
+		}
+	}
+	return nil
+}
+
+// IsRuleViolation reports whether err is one of the roster rules rather than a failure
+func IsRuleViolation(err error) bool {
+	for _, rule := range []error{ErrTeamArchived, ErrUnknownPlayer, ErrAlreadyOnRoster, ErrNotOnRoster, ErrRosterFull, ErrSuspended, ErrJerseyTaken, ErrInvalidJersey, ErrInvalidPosition} {
+		if errors.Is(err, rule) {
+			return true
+		}
+	}
+	return false
+}
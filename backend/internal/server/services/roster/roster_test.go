@@ -0,0 +1,68 @@
+package roster
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func jersey(n int) *int {
+	return &n
+}
+
+func testRoster() []models.RosterPlayer {
+	return []models.RosterPlayer{
+		{RosterID: 1, UserID: 10, JerseyNumber: jersey(9), Position: models.Skater},
+		{RosterID: 1, UserID: 11, JerseyNumber: jersey(30), Position: models.Goalie},
+		{RosterID: 1, UserID: 12, Position: models.Skater},
+	}
+}
+
+func TestLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, Limit(models.League{}))
+	assert.Equal(t, 15, Limit(models.League{RosterLimit: 15}))
+}
+
+func TestNormalize(t *testing.T) {
+	player := models.RosterPlayer{UserID: 1}
+	Normalize(&player)
+	assert.Equal(t, models.Skater, player.Position)
+
+	player.Position = models.Goalie
+	Normalize(&player)
+	assert.Equal(t, models.Goalie, player.Position)
+}
+
+func TestCheckAdd(t *testing.T) {
+	members := testRoster()
+
+	assert.NoError(t, CheckAdd(members, models.RosterPlayer{UserID: 13, JerseyNumber: jersey(0), Position: models.Skater}, 4, false))
+	assert.NoError(t, CheckAdd(members, models.RosterPlayer{UserID: 13, Position: models.Goalie}, 4, false), "jersey numbers are optional")
+
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 10, Position: models.Skater}, 4, false), ErrAlreadyOnRoster)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, Position: models.Skater}, 4, true), ErrSuspended)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 10, Position: models.Skater}, 4, true), ErrAlreadyOnRoster, "a suspended player already on the roster stays")
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, Position: models.Skater}, 3, false), ErrRosterFull)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, JerseyNumber: jersey(9), Position: models.Skater}, 4, false), ErrJerseyTaken)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, JerseyNumber: jersey(100), Position: models.Skater}, 4, false), ErrInvalidJersey)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, JerseyNumber: jersey(-1), Position: models.Skater}, 4, false), ErrInvalidJersey)
+	assert.ErrorIs(t, CheckAdd(members, models.RosterPlayer{UserID: 13, Position: "defense"}, 4, false), ErrInvalidPosition)
+}
+
+func TestCheckUpdate(t *testing.T) {
+	members := testRoster()
+
+	assert.NoError(t, CheckUpdate(members, models.RosterPlayer{UserID: 10, JerseyNumber: jersey(9), Position: models.Goalie}), "a player keeps their own number")
+	assert.NoError(t, CheckUpdate(members, models.RosterPlayer{UserID: 12, JerseyNumber: jersey(12), Position: models.Skater}))
+
+	assert.ErrorIs(t, CheckUpdate(members, models.RosterPlayer{UserID: 12, JerseyNumber: jersey(30), Position: models.Skater}), ErrJerseyTaken)
+	assert.ErrorIs(t, CheckUpdate(members, models.RosterPlayer{UserID: 99, Position: models.Skater}), ErrNotOnRoster)
+}
+
+func TestIsRuleViolation(t *testing.T) {
+	err := CheckAdd(testRoster(), models.RosterPlayer{UserID: 13, Position: models.Skater}, 3, false)
+	assert.True(t, IsRuleViolation(err), "wrapped rule errors are violations")
+	assert.False(t, IsRuleViolation(nil))
+	assert.False(t, IsRuleViolation(assert.AnError))
+}
@@ -0,0 +1,14 @@
+package scoring
+
+// Sorts maps the orders a player leaderboard accepts to the stat line columns they sort by. Names
+// sort without regard to case.
+var Sorts = map[string]string{
+	"goals":           "goals",
+	"assists":         "assists",
+	"points":          "points",
+	"penalty_minutes": "penalty_mins",
+	"games_played":    "games_played",
+	"plus_minus":      "plus_minus",
+	"points_per_game": "points_per_game",
+	"last_name":       "lower(last_name)",
+}
@@ -0,0 +1,238 @@
+package standings
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+// PointSystem is the number of points a team earns for each kind of result
+type PointSystem struct {
+	Win          int `json:"win"`
+	OvertimeWin  int `json:"overtime_win"`
+	Tie          int `json:"tie"`
+	OvertimeLoss int `json:"overtime_loss"`
+	Loss         int `json:"loss"`
+}
+
+const DefaultPointSystem = "2-1-0"
+
+var PointSystems = map[string]PointSystem{
+	// Two points for any win, one for a tie or an overtime loss
+	"2-1-0": {Win: 2, OvertimeWin: 2, Tie: 1, OvertimeLoss: 1, Loss: 0},
+	// Three points for a regulation win, two for an overtime win, one for a tie or an overtime loss
+	"3-2-1-0": {Win: 3, OvertimeWin: 2, Tie: 1, OvertimeLoss: 1, Loss: 0},
+}
+
+type Tiebreaker string
+
+const (
+	Points           Tiebreaker = "points"
+	Wins             Tiebreaker = "wins"
+	HeadToHead       Tiebreaker = "head_to_head"
+	GoalDifferential Tiebreaker = "goal_differential"
+	GoalsFor         Tiebreaker = "goals_for"
+)
+
+var DefaultTiebreakers = []Tiebreaker{Points, Wins, HeadToHead, GoalDifferential, GoalsFor}
+
+// Row is a single team's line in a league table
+type Row struct {
+	Rank             int    `json:"rank"`
+	TeamID           uint   `json:"team_id"`
+	TeamName         string `json:"team_name"`
+	GamesPlayed      int    `json:"games_played"`
+	Wins             int    `json:"wins"`
+	OvertimeWins     int    `json:"overtime_wins"`
+	Losses           int    `json:"losses"`
+	OvertimeLosses   int    `json:"overtime_losses"`
+	Ties             int    `json:"ties"`
+	GoalsFor         int    `json:"goals_for"`
+	GoalsAgainst     int    `json:"goals_against"`
+	GoalDifferential int    `json:"goal_differential"`
+	Points           int    `json:"points"`
+}
+
+// Config is the point system and tiebreaker order used to build a league's table
+type Config struct {
+	PointSystem PointSystem
+	Tiebreakers []Tiebreaker
+}
+
+// ConfigFor returns the standings configuration of a league, falling back to the
+// defaults when the league hasn't chosen a point system or tiebreakers. Teams are always
+// ranked by points first, so a league's tiebreakers only decide between teams level on points.
+func ConfigFor(league *models.League) (Config, error) {
+	name := league.PointSystem
+	if name == "" {
+		name = DefaultPointSystem
+	}
+
+	points, ok := PointSystems[name]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown point system %q", name)
+	}
+
+	tiebreakers := DefaultTiebreakers
+	if len(league.Tiebreakers) > 0 {
+		tiebreakers = make([]Tiebreaker, 0, len(league.Tiebreakers)+1)
+		tiebreakers = append(tiebreakers, Points)
+		for _, t := range league.Tiebreakers {
+			tb := Tiebreaker(t)
+			if !tb.valid() {
+				return Config{}, fmt.Errorf("unknown tiebreaker %q", t)
+			}
+			if tb == Points {
+				continue
+			}
+			tiebreakers = append(tiebreakers, tb)
+		}
+	}
+
+	return Config{PointSystem: points, Tiebreakers: tiebreakers}, nil
+}
+
+func (t Tiebreaker) valid() bool {
+	switch t {
+	case Points, Wins, HeadToHead, GoalDifferential, GoalsFor:
+		return true
+	}
+	return false
+}
+
+// Compute builds a ranked table for the given teams from their finished games.
+// Games involving a team that isn't in the list are ignored.
+func Compute(teams []models.Team, results []models.GameResult, config Config) []Row {
+	rows := make([]*Row, 0, len(teams))
+	byTeam := make(map[uint]*Row, len(teams))
+	for _, team := range teams {
+		row := &Row{TeamID: team.ID, TeamName: team.Name}
+		rows = append(rows, row)
+		byTeam[team.ID] = row
+	}
+
+	counted := make([]models.GameResult, 0, len(results))
+	for _, result := range results {
+		home, away := byTeam[result.HomeTeamID], byTeam[result.AwayTeamID]
+		if home == nil || away == nil {
+			continue
+		}
+		counted = append(counted, result)
+
+		home.record(result.HomeScore, result.AwayScore, result.Overtime, config.PointSystem)
+		away.record(result.AwayScore, result.HomeScore, result.Overtime, config.PointSystem)
+	}
+
+	// Names give a stable order for teams that are still level after every tiebreaker
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].TeamName < rows[j].TeamName
+	})
+	rank(rows, config.Tiebreakers, counted, config.PointSystem)
+
+	table := make([]Row, 0, len(rows))
+	for i, row := range rows {
+		row.Rank = i + 1
+		table = append(table, *row)
+	}
+	return table
+}
+
+// Records converts a table into the win/loss record stored on each team. Only the ID and record
+// fields of the teams are set.
+func Records(table []Row) []models.Team {
+	teams := make([]models.Team, 0, len(table))
+	for _, row := range table {
+		team := models.Team{
+			Wins:           row.Wins,
+			Losses:         row.Losses,
+			Ties:           row.Ties,
+			OvertimeLosses: row.OvertimeLosses,
+			GoalsFor:       row.GoalsFor,
+			GoalsAgainst:   row.GoalsAgainst,
+			Points:         row.Points,
+		}
+		team.ID = row.TeamID
+		teams = append(teams, team)
+	}
+	return teams
+}
+
+func (r *Row) record(goalsFor, goalsAgainst int, overtime bool, points PointSystem) {
+	r.GamesPlayed++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	r.GoalDifferential = r.GoalsFor - r.GoalsAgainst
+
+	switch {
+	case goalsFor > goalsAgainst && overtime:
+		r.Wins++
+		r.OvertimeWins++
+		r.Points += points.OvertimeWin
+	case goalsFor > goalsAgainst:
+		r.Wins++
+		r.Points += points.Win
+	case goalsFor < goalsAgainst && overtime:
+		r.OvertimeLosses++
+		r.Points += points.OvertimeLoss
+	case goalsFor < goalsAgainst:
+		r.Losses++
+		r.Points += points.Loss
+	default:
+		r.Ties++
+		r.Points += points.Tie
+	}
+}
+
+// rank orders rows by the first tiebreaker, then recursively breaks each group of
+// still-level teams with the remaining tiebreakers. Head-to-head is evaluated only
+// among the teams in the tied group.
+func rank(rows []*Row, tiebreakers []Tiebreaker, results []models.GameResult, points PointSystem) {
+	if len(rows) < 2 || len(tiebreakers) == 0 {
+		return
+	}
+
+	value := valueFunc(tiebreakers[0], rows, results, points)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return value(rows[i]) > value(rows[j])
+	})
+
+	start := 0
+	for i := 1; i <= len(rows); i++ {
+		if i == len(rows) || value(rows[i]) != value(rows[start]) {
+			rank(rows[start:i], tiebreakers[1:], results, points)
+			start = i
+		}
+	}
+}
+
+func valueFunc(tiebreaker Tiebreaker, group []*Row, results []models.GameResult, points PointSystem) func(*Row) int {
+	switch tiebreaker {
+	case Points:
+		return func(r *Row) int { return r.Points }
+	case Wins:
+		return func(r *Row) int { return r.Wins }
+	case GoalDifferential:
+		return func(r *Row) int { return r.GoalDifferential }
+	case GoalsFor:
+		return func(r *Row) int { return r.GoalsFor }
+	case HeadToHead:
+		inGroup := make(map[uint]bool, len(group))
+		for _, r := range group {
+			inGroup[r.TeamID] = true
+		}
+
+		headToHead := make(map[uint]*Row, len(group))
+		for _, r := range group {
+			headToHead[r.TeamID] = &Row{}
+		}
+		for _, result := range results {
+			if inGroup[result.HomeTeamID] && inGroup[result.AwayTeamID] {
+				headToHead[result.HomeTeamID].record(result.HomeScore, result.AwayScore, result.Overtime, points)
+				headToHead[result.AwayTeamID].record(result.AwayScore, result.HomeScore, result.Overtime, points)
+			}
+		}
+		return func(r *Row) int { return headToHead[r.TeamID].Points }
+	}
+	return func(r *Row) int { return 0 }
+}
@@ -0,0 +1,113 @@
+package standings
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func team(id uint, name string) models.Team {
+	t := models.Team{Name: name}
+	t.ID = id
+	return t
+}
+
+func TestComputeRecordsResults(t *testing.T) {
+	teams := []models.Team{team(1, "Ducks"), team(2, "Geese")}
+	results := []models.GameResult{
+		{HomeTeamID: 1, AwayTeamID: 2, HomeScore: 3, AwayScore: 1},
+		{HomeTeamID: 2, AwayTeamID: 1, HomeScore: 2, AwayScore: 2},
+		{HomeTeamID: 2, AwayTeamID: 1, HomeScore: 4, AwayScore: 3, Overtime: true},
+	}
+
+	table := Compute(teams, results, Config{PointSystem: PointSystems["3-2-1-0"], Tiebreakers: DefaultTiebreakers})
+	require.Len(t, table, 2)
+
+	ducks := table[0]
+	assert.Equal(t, "Ducks", ducks.TeamName)
+	assert.Equal(t, 1, ducks.Rank)
+	assert.Equal(t, 3, ducks.GamesPlayed)
+	assert.Equal(t, 1, ducks.Wins)
+	assert.Equal(t, 1, ducks.Ties)
+	assert.Equal(t, 1, ducks.OvertimeLosses)
+	assert.Equal(t, 8, ducks.GoalsFor)
+	assert.Equal(t, 7, ducks.GoalsAgainst)
+	assert.Equal(t, 5, ducks.Points)
+
+	geese := table[1]
+	assert.Equal(t, 1, geese.Wins)
+	assert.Equal(t, 1, geese.OvertimeWins)
+	assert.Equal(t, 1, geese.Losses)
+	assert.Equal(t, 3, geese.Points)
+}
+
+func TestComputeHeadToHeadOnlyCountsTiedTeams(t *testing.T) {
+	teams := []models.Team{team(1, "A"), team(2, "B"), team(3, "C")}
+	results := []models.GameResult{
+		// B beats A, so B should finish ahead even though A has the better goal differential
+		{HomeTeamID: 2, AwayTeamID: 1, HomeScore: 1, AwayScore: 0},
+		{HomeTeamID: 1, AwayTeamID: 3, HomeScore: 9, AwayScore: 0},
+		{HomeTeamID: 3, AwayTeamID: 2, HomeScore: 1, AwayScore: 0},
+	}
+
+	config := Config{PointSystem: PointSystems["2-1-0"], Tiebreakers: []Tiebreaker{Points, HeadToHead, GoalDifferential}}
+	table := Compute(teams, results, config)
+
+	// Everyone has 2 points, and in a three-way tie each team has 2 head-to-head points,
+	// so goal differential decides it
+	assert.Equal(t, []uint{1, 2, 3}, []uint{table[0].TeamID, table[1].TeamID, table[2].TeamID})
+
+	// Drop C's win over B so only A and B are level on points
+	table = Compute(teams, results[:2], config)
+	assert.Equal(t, []uint{2, 1, 3}, []uint{table[0].TeamID, table[1].TeamID, table[2].TeamID})
+}
+
+func TestComputeIgnoresGamesOutsideLeague(t *testing.T) {
+	teams := []models.Team{team(1, "A")}
+	results := []models.GameResult{{HomeTeamID: 1, AwayTeamID: 99, HomeScore: 5, AwayScore: 0}}
+
+	table := Compute(teams, results, Config{PointSystem: PointSystems["2-1-0"], Tiebreakers: DefaultTiebreakers})
+	assert.Equal(t, 0, table[0].GamesPlayed)
+}
+
+func TestConfigFor(t *testing.T) {
+	config, err := ConfigFor(&models.League{})
+	require.Nil(t, err)
+	assert.Equal(t, PointSystems[DefaultPointSystem], config.PointSystem)
+	assert.Equal(t, DefaultTiebreakers, config.Tiebreakers)
+
+	config, err = ConfigFor(&models.League{PointSystem: "3-2-1-0", Tiebreakers: []string{"wins", "goals_for"}})
+	require.Nil(t, err)
+	assert.Equal(t, 3, config.PointSystem.Win)
+	assert.Equal(t, []Tiebreaker{Points, Wins, GoalsFor}, config.Tiebreakers)
+
+	// Points always come first, wherever a league lists them
+	config, err = ConfigFor(&models.League{Tiebreakers: []string{"wins", "points", "goals_for"}})
+	require.Nil(t, err)
+	assert.Equal(t, []Tiebreaker{Points, Wins, GoalsFor}, config.Tiebreakers)
+
+	_, err = ConfigFor(&models.League{PointSystem: "10-0"})
+	assert.NotNil(t, err)
+
+	_, err = ConfigFor(&models.League{Tiebreakers: []string{"coin_flip"}})
+	assert.NotNil(t, err)
+}
+
+func TestComputeRanksByPointsBeforeLeagueTiebreakers(t *testing.T) {
+	teams := []models.Team{team(1, "A"), team(2, "B"), team(3, "C")}
+	results := []models.GameResult{
+		// A has the only win, but the three ties between B and C are worth more points
+		{HomeTeamID: 1, AwayTeamID: 3, HomeScore: 2, AwayScore: 0},
+		{HomeTeamID: 2, AwayTeamID: 3, HomeScore: 1, AwayScore: 1},
+		{HomeTeamID: 2, AwayTeamID: 3, HomeScore: 2, AwayScore: 2},
+		{HomeTeamID: 2, AwayTeamID: 3, HomeScore: 0, AwayScore: 0},
+	}
+
+	config, err := ConfigFor(&models.League{Tiebreakers: []string{"wins"}})
+	require.Nil(t, err)
+
+	table := Compute(teams, results, config)
+	assert.Equal(t, []uint{2, 3, 1}, []uint{table[0].TeamID, table[1].TeamID, table[2].TeamID})
+}
@@ -0,0 +1,171 @@
+package timeline
+
+import (
+	"sort"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/jak103/powerplay/internal/server/services/powerplay"
+)
+
+type EventType string
+
+const (
+	Shot         EventType = "shot"
+	Goal         EventType = "goal"
+	PenaltyEnd   EventType = "penalty_end"
+	PenaltyStart EventType = "penalty_start"
+)
+
+// order breaks ties between events at the same second: a goal comes after the shot it was
+// scored on, and a penalty it ends expires after it
+var order = map[EventType]int{Shot: 0, Goal: 1, PenaltyEnd: 2, PenaltyStart: 3}
+
+// Event is one entry in a game's play-by-play. ID is the goal, penalty or shot it came from.
+type Event struct {
+	Type      EventType `json:"type"`
+	ID        uint      `json:"id"`
+	TeamID    uint      `json:"team_id"`
+	GameTime  uint      `json:"game_time"` // Seconds since puck drop
+	Period    uint      `json:"period"`
+	Elapsed   uint      `json:"elapsed"` // Seconds elapsed in the period
+	Clock     string    `json:"clock"`   // Time left in the period as shown on the scoreboard
+	HomeScore int       `json:"home_score"`
+	AwayScore int       `json:"away_score"`
+
+	PlayerID  uint   `json:"player_id,omitempty"`
+	Assist1ID uint   `json:"assist1_id,omitempty"`
+	Assist2ID uint   `json:"assist2_id,omitempty"`
+	Strength  string `json:"strength,omitempty"`
+
+	PenaltyType string `json:"penalty_type,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	Length      uint   `json:"length,omitempty"`      // Seconds the penalty is served for
+	EndedEarly  bool   `json:"ended_early,omitempty"` // A minor released by a power play goal
+}
+
+// Timeline is the play-by-play of a game
+type Timeline struct {
+	GameID     uint    `json:"game_id"`
+	HomeTeamID uint    `json:"home_team_id"`
+	AwayTeamID uint    `json:"away_team_id"`
+	Events     []Event `json:"events"`
+}
+
+// Build merges a game's goals, penalties and shots into one chronological stream. Penalties must
+// have their type loaded. Each penalty that is served gets a start and an end marker; the end
+// accounts for minors released early by power play goals.
+func Build(game *models.Game, goals []models.Goal, penalties []models.Penalty, shots []models.ShotOnGoal) Timeline {
+	analysis := powerplay.Analyze(powerplay.Game{
+		GameID:     game.ID,
+		HomeTeamID: game.HomeTeamID,
+		AwayTeamID: game.AwayTeamID,
+		Penalties:  penalties,
+		Goals:      goals,
+	})
+	servedUntil := make(map[uint]uint, len(analysis.Penalties))
+	for _, served := range analysis.Penalties {
+		servedUntil[served.PenaltyID] = served.End
+	}
+	strengths := make(map[uint]powerplay.Strength, len(analysis.Goals))
+	for _, goal := range analysis.Goals {
+		strengths[goal.GoalID] = goal.Strength
+	}
+
+	events := make([]Event, 0, len(goals)+len(shots)+2*len(penalties))
+	for _, shot := range shots {
+		events = append(events, Event{
+			Type:     Shot,
+			ID:       shot.ID,
+			TeamID:   shot.TeamId,
+			GameTime: models.GameSeconds(shot.Period, shot.ShotTime),
+			Period:   shot.Period,
+			Elapsed:  shot.ShotTime,
+		})
+	}
+
+	for _, goal := range goals {
+		events = append(events, Event{
+			Type:      Goal,
+			ID:        goal.ID,
+			TeamID:    goal.TeamId,
+			GameTime:  models.GameSeconds(goal.Period, goal.Duration),
+			Period:    goal.Period,
+			Elapsed:   goal.Duration,
+			PlayerID:  goal.UserId,
+			Assist1ID: goal.Assist1Id,
+			Assist2ID: goal.Assist2Id,
+			Strength:  string(strengths[goal.ID]),
+		})
+	}
+
+	for _, penalty := range penalties {
+		start := models.GameSeconds(penalty.Period, penalty.GameTime)
+		length := penalty.Duration
+		if length == 0 {
+			length = penalty.PenaltyType.Duration * 60
+		}
+
+		marker := Event{
+			Type:        PenaltyStart,
+			ID:          penalty.ID,
+			TeamID:      penalty.TeamID,
+			GameTime:    start,
+			Period:      penalty.Period,
+			Elapsed:     penalty.GameTime,
+			PlayerID:    penalty.PlayerID,
+			PenaltyType: penalty.PenaltyType.Name,
+			Severity:    penalty.PenaltyType.Severity,
+			Length:      length,
+		}
+		events = append(events, marker)
+		if length == 0 {
+			continue // Game misconducts and match penalties are served for the rest of the game
+		}
+
+		marker.Type = PenaltyEnd
+		marker.Period, marker.Elapsed = 0, 0
+		marker.GameTime = start + length
+		if end, ok := servedUntil[penalty.ID]; ok && end < marker.GameTime {
+			marker.GameTime = end
+			marker.EndedEarly = true
+		}
+		events = append(events, marker)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.GameTime != b.GameTime {
+			return a.GameTime < b.GameTime
+		}
+		if order[a.Type] != order[b.Type] {
+			return order[a.Type] < order[b.Type]
+		}
+		return a.ID < b.ID
+	})
+
+	home, away := 0, 0
+	for i := range events {
+		event := &events[i]
+		if event.Type == Goal {
+			switch event.TeamID {
+			case game.HomeTeamID:
+				home++
+			case game.AwayTeamID:
+				away++
+			}
+		}
+		event.HomeScore, event.AwayScore = home, away
+		if event.Period == 0 {
+			// Shots and penalty ends are only known as seconds since puck drop
+			event.Period, event.Elapsed = models.PeriodClock(event.GameTime)
+		}
+		event.Clock = models.ScoreboardClock(event.Elapsed)
+	}
+
+	return Timeline{
+		GameID:     game.ID,
+		HomeTeamID: game.HomeTeamID,
+		AwayTeamID: game.AwayTeamID,
+		Events:     events,
+	}
+}
@@ -0,0 +1,77 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	home uint = 1
+	away uint = 2
+)
+
+func TestBuild(t *testing.T) {
+	game := &models.Game{HomeTeamID: home, AwayTeamID: away}
+	game.ID = 9
+
+	minor := models.PenaltyType{Name: "Tripping", Duration: 2, Severity: "minor"}
+	misconduct := models.PenaltyType{Name: "Game misconduct", Severity: "game_misconduct"}
+
+	goals := []models.Goal{
+		{TeamId: home, UserId: 10, Assist1Id: 11, Period: 1, Duration: 130},
+		{TeamId: away, UserId: 20, Period: 2, Duration: 5},
+	}
+	goals[0].ID, goals[1].ID = 1, 2
+
+	penalties := []models.Penalty{
+		{TeamID: away, PlayerID: 21, Period: 1, GameTime: 100, PenaltyType: minor},
+		{TeamID: home, PlayerID: 12, Period: 1, GameTime: 1150, PenaltyType: minor},
+		{TeamID: home, PlayerID: 13, Period: 3, GameTime: 0, PenaltyType: misconduct},
+	}
+	penalties[0].ID, penalties[1].ID, penalties[2].ID = 1, 2, 3
+
+	shots := []models.ShotOnGoal{
+		{TeamId: home, Period: 1, ShotTime: 130},
+		{TeamId: away, Period: 2, ShotTime: 5},
+	}
+	shots[0].ID, shots[1].ID = 1, 2
+
+	timeline := Build(game, goals, penalties, shots)
+	assert.Equal(t, uint(9), timeline.GameID)
+
+	type entry struct {
+		Type     EventType
+		ID       uint
+		Period   uint
+		Clock    string
+		Home     int
+		Away     int
+		Strength string
+		Early    bool
+	}
+	got := make([]entry, 0, len(timeline.Events))
+	for _, e := range timeline.Events {
+		got = append(got, entry{e.Type, e.ID, e.Period, e.Clock, e.HomeScore, e.AwayScore, e.Strength, e.EndedEarly})
+	}
+
+	require.Equal(t, []entry{
+		{PenaltyStart, 1, 1, "18:20", 0, 0, "", false},
+		{Shot, 1, 1, "17:50", 0, 0, "", false},
+		{Goal, 1, 1, "17:50", 1, 0, "PP", false},
+		{PenaltyEnd, 1, 1, "17:50", 1, 0, "", true},
+		{PenaltyStart, 2, 1, "00:50", 1, 0, "", false},
+		{Shot, 2, 2, "19:55", 1, 0, "", false},
+		{Goal, 2, 2, "19:55", 1, 1, "PP", false},
+		{PenaltyEnd, 2, 2, "19:55", 1, 1, "", true},
+		{PenaltyStart, 3, 3, "20:00", 1, 1, "", false},
+	}, got)
+}
+
+func TestClock(t *testing.T) {
+	assert.Equal(t, "20:00", models.ScoreboardClock(0))
+	assert.Equal(t, "00:07", models.ScoreboardClock(models.PeriodLength-7))
+	assert.Equal(t, "00:00", models.ScoreboardClock(models.PeriodLength+30), "overtime past the period length stays at zero")
+}
@@ -0,0 +1,48 @@
+package totals
+
+import "github.com/jak103/powerplay/internal/models"
+
+// Derive counts a game's score and shots on goal from its recorded goals and shots. Deleted
+// events and events for teams not playing in the game are left out.
+func Derive(game models.Game, goals []models.Goal, shots []models.ShotOnGoal) models.GameTotals {
+	totals := models.GameTotals{}
+	for _, goal := range goals {
+		if goal.DeletedAt != nil {
+			continue
+		}
+		switch goal.TeamId {
+		case game.HomeTeamID:
+			totals.HomeTeamScore++
+		case game.AwayTeamID:
+			totals.AwayTeamScore++
+		}
+	}
+	for _, shot := range shots {
+		if shot.DeletedAt != nil {
+			continue
+		}
+		switch shot.TeamId {
+		case game.HomeTeamID:
+			totals.HomeTeamShotsOnGoal++
+		case game.AwayTeamID:
+			totals.AwayTeamShotsOnGoal++
+		}
+	}
+	return totals
+}
+
+// Stored returns the totals saved on a game
+func Stored(game models.Game) models.GameTotals {
+	return models.GameTotals{
+		HomeTeamScore:       game.HomeTeamScore,
+		AwayTeamScore:       game.AwayTeamScore,
+		HomeTeamShotsOnGoal: game.HomeTeamShotsOnGoal,
+		AwayTeamShotsOnGoal: game.AwayTeamShotsOnGoal,
+	}
+}
+
+// Compare reports whether a game's stored totals disagree with the derived ones, and how
+func Compare(game models.Game, derived models.GameTotals) (models.GameTotalsDiscrepancy, bool) {
+	stored := Stored(game)
+	return models.GameTotalsDiscrepancy{GameID: game.ID, Stored: stored, Derived: derived}, stored != derived
+}
@@ -0,0 +1,46 @@
+package totals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerive(t *testing.T) {
+	deleted := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	game := models.Game{HomeTeamID: 1, AwayTeamID: 2}
+	goals := []models.Goal{
+		{TeamId: 1},
+		{TeamId: 1},
+		{TeamId: 2},
+		{TeamId: 1, DbModel: models.DbModel{DeletedAt: &deleted}},
+		{TeamId: 9},
+	}
+	shots := []models.ShotOnGoal{
+		{TeamId: 1},
+		{TeamId: 2},
+		{TeamId: 2},
+		{TeamId: 2, DbModel: models.DbModel{DeletedAt: &deleted}},
+	}
+
+	assert.Equal(t, models.GameTotals{HomeTeamScore: 2, AwayTeamScore: 1, HomeTeamShotsOnGoal: 1, AwayTeamShotsOnGoal: 2}, Derive(game, goals, shots))
+	assert.Equal(t, models.GameTotals{}, Derive(game, nil, nil))
+}
+
+func TestCompare(t *testing.T) {
+	game := models.Game{DbModel: models.DbModel{ID: 5}, HomeTeamScore: 3, AwayTeamScore: 1, HomeTeamShotsOnGoal: 20}
+	derived := models.GameTotals{HomeTeamScore: 3, AwayTeamScore: 1, HomeTeamShotsOnGoal: 20}
+
+	_, differs := Compare(game, derived)
+	assert.False(t, differs)
+
+	derived.AwayTeamScore = 2
+	discrepancy, differs := Compare(game, derived)
+	assert.True(t, differs)
+	assert.Equal(t, uint(5), discrepancy.GameID)
+	assert.Equal(t, 1, discrepancy.Stored.AwayTeamScore)
+	assert.Equal(t, 2, discrepancy.Derived.AwayTeamScore)
+	assert.False(t, discrepancy.Repaired)
+}
@@ -0,0 +1,63 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+)
+
+var (
+	ErrSameTeam         = errors.New("a player can't transfer to the team they're already on")
+	ErrUnknownTeam      = errors.New("the team doesn't exist")
+	ErrTeamArchived     = errors.New("players can't transfer to an archived team")
+	ErrDifferentSeasons = errors.New("players can only transfer between teams of the same season")
+	ErrNotOnTeam        = errors.New("the player isn't on the roster of the team they're leaving")
+	ErrAlreadyRequested = errors.New("the player already has a transfer waiting to take effect")
+	ErrNotAllowed       = errors.New("only the player, a captain of either team or a manager can request a transfer")
+	ErrNotPending       = errors.New("the transfer has already been reviewed")
+	ErrBeforeSeason     = errors.New("a transfer can't take effect before its season starts")
+	ErrPastDeadline     = errors.New("the trade deadline has passed")
+)
+
+// CheckDates checks a transfer can take effect on the given date in a season: not before it starts
+// and not after its trade deadline
+func CheckDates(season models.Season, effective time.Time) error {
+	if !season.Start.IsZero() && effective.Before(season.Start) {
+		return ErrBeforeSeason
+	}
+	if season.TradeDeadline != nil && effective.After(*season.TradeDeadline) {
+		return fmt.Errorf("%w, transfers in %v had to take effect by %v", ErrPastDeadline, season.Name, season.TradeDeadline.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// CheckTeams checks a player can move between two teams. from and to need their leagues loaded.
+func CheckTeams(from, to models.Team) error {
+	switch {
+	case from.ID == to.ID:
+		return ErrSameTeam
+	case to.ArchivedAt != nil:
+		return ErrTeamArchived
+	case from.League.SeasonID != to.League.SeasonID:
+		return ErrDifferentSeasons
+	}
+	return nil
+}
+
+// Due reports whether an approved transfer should take effect
+func Due(transfer models.Transfer, now time.Time) bool {
+	return transfer.Status == models.TransferApproved && !transfer.EffectiveAt.After(now)
+}
+
+// IsRuleViolation reports whether err is one of the transfer rules rather than a failure.
+// Permission errors aren't rule violations.
+func IsRuleViolation(err error) bool {
+	for _, rule := range []error{ErrSameTeam, ErrUnknownTeam, ErrTeamArchived, ErrDifferentSeasons, ErrNotOnTeam, ErrAlreadyRequested, ErrNotPending, ErrBeforeSeason, ErrPastDeadline} {
+		if errors.Is(err, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jak103/powerplay/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	start    = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	deadline = time.Date(2024, 11, 15, 23, 59, 0, 0, time.UTC)
+)
+
+func TestCheckDates(t *testing.T) {
+	season := models.Season{Name: "Fall 2024", Start: start, TradeDeadline: &deadline}
+
+	assert.NoError(t, CheckDates(season, start))
+	assert.NoError(t, CheckDates(season, deadline), "a transfer can take effect right at the deadline")
+	assert.ErrorIs(t, CheckDates(season, start.Add(-time.Hour)), ErrBeforeSeason)
+
+	err := CheckDates(season, deadline.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrPastDeadline)
+	assert.Contains(t, err.Error(), "2024-11-15")
+
+	season.TradeDeadline = nil
+	assert.NoError(t, CheckDates(season, deadline.AddDate(1, 0, 0)), "seasons without a deadline allow transfers any time")
+}
+
+func TestCheckTeams(t *testing.T) {
+	archived := time.Now()
+	otters := models.Team{DbModel: models.DbModel{ID: 1}, League: models.League{SeasonID: 1}}
+	ravens := models.Team{DbModel: models.DbModel{ID: 2}, League: models.League{SeasonID: 1}}
+
+	assert.NoError(t, CheckTeams(otters, ravens))
+	assert.ErrorIs(t, CheckTeams(otters, otters), ErrSameTeam)
+	assert.ErrorIs(t, CheckTeams(otters, models.Team{DbModel: models.DbModel{ID: 3}, League: models.League{SeasonID: 2}}), ErrDifferentSeasons)
+	assert.ErrorIs(t, CheckTeams(otters, models.Team{DbModel: models.DbModel{ID: 4}, League: models.League{SeasonID: 1}, ArchivedAt: &archived}), ErrTeamArchived)
+}
+
+func TestDue(t *testing.T) {
+	now := start.AddDate(0, 1, 0)
+	transfer := models.Transfer{Status: models.TransferApproved, EffectiveAt: now}
+
+	assert.True(t, Due(transfer, now))
+	assert.False(t, Due(transfer, now.Add(-time.Minute)))
+
+	transfer.Status = models.TransferPending
+	assert.False(t, Due(transfer, now), "transfers need approval first")
+}
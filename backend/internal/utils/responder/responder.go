@@ -1,8 +1,10 @@
 package responder
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
@@ -33,11 +35,35 @@ func Ok(c *fiber.Ctx, message ...any) error {
 	return respond(c, fiber.StatusOK, nil, message...)
 }
 
+// OkWithFile sends a rendered file, such as a PDF, instead of the JSON envelope. The file is
+// rendered before anything is sent so a failure can still be reported as an error.
+func OkWithFile(c *fiber.Ctx, contentType, filename string, render func(io.Writer) error) error {
+	body := &bytes.Buffer{}
+	if err := render(body); err != nil {
+		return InternalServerError(c)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(body.Bytes())
+}
+
 // 400
 func BadRequest(c *fiber.Ctx, message ...any) error {
 	return respond(c, fiber.StatusBadRequest, nil, message...)
 }
 
+func BadRequestWithData(c *fiber.Ctx, data any, message ...any) error {
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return InternalServerError(c)
+	}
+
+	raw := json.RawMessage(jsonBytes)
+
+	return respond(c, fiber.StatusBadRequest, &raw, message...)
+}
+
 // 401
 func Unauthorized(c *fiber.Ctx, message ...any) error {
 	return respond(c, fiber.StatusUnauthorized, nil, message...)
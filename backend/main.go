@@ -2,11 +2,14 @@ package main
 
 import (
 	"flag"
+	"os"
+	"time"
 
 	"github.com/jak103/powerplay/internal/config"
 	"github.com/jak103/powerplay/internal/db"
 	ppseeders "github.com/jak103/powerplay/internal/db/seeders"
 	"github.com/jak103/powerplay/internal/server"
+	"github.com/jak103/powerplay/internal/server/services/importer"
 	"github.com/jak103/powerplay/internal/utils/log"
 )
 
@@ -19,9 +22,70 @@ func runMigrations() {
 	log.Info("Migrations completed successfully")
 }
 
+func runReconcile() {
+	discrepancies, err := db.GetSession(nil).ReconcileGameTotals(true)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to reconcile game totals")
+		return
+	}
+
+	for _, d := range discrepancies {
+		log.Warn("Game %v totals were %+v, repaired to %+v", d.GameID, d.Stored, d.Derived)
+	}
+	log.Info("Reconciled game totals, %v games repaired", len(discrepancies))
+}
+
+func runImport(kind, path string, dryRun bool) {
+	k, err := importer.ParseKind(kind)
+	if err != nil {
+		log.WithErr(err).Alert("Invalid import")
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to open %v", path)
+		return
+	}
+	defer file.Close()
+
+	result, err := db.GetSession(nil).Import(k, file, dryRun, time.Local)
+	if err != nil {
+		log.WithErr(err).Alert("Failed to import %v", path)
+		return
+	}
+
+	for _, e := range result.Errors {
+		log.Warn("%v: %v", path, e)
+	}
+	switch {
+	case result.Saved:
+		log.Info("Imported %v %v: %v created, %v matched", result.Rows, kind, result.Created, result.Matched)
+	case dryRun && len(result.Errors) == 0:
+		log.Info("Dry run of %v %v passed: %v would be created, %v matched", result.Rows, kind, result.Created, result.Matched)
+	default:
+		log.Warn("%v has %v problems, nothing was imported", path, len(result.Errors))
+	}
+}
+
+// applyTransfers moves the players of approved transfers once their effective dates come,
+// checking every minute while the server runs
+func applyTransfers() {
+	for range time.Tick(time.Minute) {
+		completed, err := db.GetSession(nil).ApplyDueTransfers()
+		if err != nil {
+			log.WithErr(err).Alert("Failed to apply due transfers")
+		}
+		for _, t := range completed {
+			log.Info("Transfer %v moved player %v from team %v to team %v", t.ID, t.UserID, t.FromTeamID, t.ToTeamID)
+		}
+	}
+}
+
 func runSeeds() {
 	seeders := []ppseeders.Seeder{
 		ppseeders.PenaltyTypeSeeder{},
+		ppseeders.DisciplineRuleSeeder{},
 		// Add more seeders here
 	}
 
@@ -34,6 +98,10 @@ func runSeeds() {
 
 func main() {
 	migrateFlag := flag.Bool("migrate", false, "Run database migrations and exit")
+	reconcileFlag := flag.Bool("reconcile", false, "Repair game scores and shots that disagree with recorded goals and shots, then exit")
+	importFlag := flag.String("import", "", "Import users, rosters or games from the CSV file given by -file, then exit")
+	fileFlag := flag.String("file", "", "The CSV file to import")
+	dryRunFlag := flag.Bool("dry-run", false, "With -import, check the file and report problems without saving anything")
 	flag.Parse()
 
 	err := log.Init("DEBUG", false)
@@ -68,8 +136,19 @@ func main() {
 		return
 	}
 
+	if *reconcileFlag {
+		runReconcile()
+		return
+	}
+
+	if *importFlag != "" {
+		runImport(*importFlag, *fileFlag, *dryRunFlag)
+		return
+	}
+
 	runMigrations()
 	runSeeds()
+	go applyTransfers()
 
 	// run
 	server.Run()